@@ -0,0 +1,102 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+type LeapDayRule string
+
+const (
+	LeapDayMarch1     LeapDayRule = "MAR1"
+	LeapDayFebruary28 LeapDayRule = "FEB28"
+)
+
+// Calendar does date arithmetic for a leap day rule. The zero Calendar uses
+// the MAR1 rule.
+type Calendar struct {
+	leapDayRule LeapDayRule
+}
+
+type SentencePart struct {
+	Length   int
+	TimeCode string
+}
+
+func NewCalendar(leapDayRule LeapDayRule) (Calendar, error) {
+	switch leapDayRule {
+	case LeapDayMarch1, LeapDayFebruary28:
+		return Calendar{leapDayRule: leapDayRule}, nil
+	}
+	return Calendar{}, fmt.Errorf("invalid leap day rule %q: should be %s or %s", leapDayRule, LeapDayMarch1, LeapDayFebruary28)
+}
+
+func (c Calendar) LeapDayRule() LeapDayRule {
+	return c.leapDayRule
+}
+
+// An event on February 29 is observed on February 28 or March 1 in common years, depending on the leap day rule.
+func (c Calendar) Anniversary(event time.Time, years int) time.Time {
+	year := event.Year() + years
+	month, day := event.Month(), event.Day()
+	if month == time.February && day == 29 && !isLeapYear(year) {
+		if c.leapDayRule == LeapDayFebruary28 {
+			day = 28
+		} else {
+			month, day = time.March, 1
+		}
+	}
+	return time.Date(year, month, day, event.Hour(), event.Minute(), event.Second(), event.Nanosecond(), event.Location())
+}
+
+func (c Calendar) AddMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if lastDay := daysInMonth(firstOfMonth.Year(), firstOfMonth.Month()); day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+func (c Calendar) AddSentencePart(start time.Time, part SentencePart) time.Time {
+	switch part.TimeCode {
+	case "D":
+		return start.AddDate(0, 0, part.Length)
+	case "M":
+		return c.AddMonths(start, part.Length)
+	case "Y":
+		return c.Anniversary(start, part.Length)
+	}
+	return start
+}
+
+func (c Calendar) WholeYearsBetween(start time.Time, end time.Time) int {
+	years := end.Year() - start.Year()
+	if c.Anniversary(start, years).After(end) {
+		years--
+	}
+	return years
+}
+
+func (c Calendar) YearsBetween(start time.Time, end time.Time) float64 {
+	wholeYears := c.WholeYearsBetween(start, end)
+	lastAnniversary := c.Anniversary(start, wholeYears)
+	nextAnniversary := c.Anniversary(start, wholeYears+1)
+	return float64(wholeYears) + float64(end.Sub(lastAnniversary))/float64(nextAnniversary.Sub(lastAnniversary))
+}
+
+func (c Calendar) AgeAt(dob time.Time, t time.Time) int {
+	return c.WholeYearsBetween(dob, t)
+}
+
+func (c Calendar) HasReachedAnniversary(event time.Time, years int, t time.Time) bool {
+	return !c.Anniversary(event, years).After(t)
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
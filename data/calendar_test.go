@@ -0,0 +1,88 @@
+package data_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen/data"
+)
+
+var _ = Describe("Calendar", func() {
+	leapDayBirth := time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+	Describe("NewCalendar", func() {
+		It("rejects unknown leap day rules", func() {
+			_, err := NewCalendar("FEB30")
+			Expect(err).To(MatchError(`invalid leap day rule "FEB30": should be MAR1 or FEB28`))
+		})
+	})
+
+	Describe("Anniversary", func() {
+		It("observes February 29 on March 1 in common years with the MAR1 rule", func() {
+			calendar, _ := NewCalendar(LeapDayMarch1)
+			Expect(calendar.Anniversary(leapDayBirth, 21)).To(Equal(time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("observes February 29 on February 28 in common years with the FEB28 rule", func() {
+			calendar, _ := NewCalendar(LeapDayFebruary28)
+			Expect(calendar.Anniversary(leapDayBirth, 21)).To(Equal(time.Date(2021, time.February, 28, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("keeps February 29 in leap years", func() {
+			calendar, _ := NewCalendar(LeapDayFebruary28)
+			Expect(calendar.Anniversary(leapDayBirth, 20)).To(Equal(time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("WholeYearsBetween", func() {
+		calendar, _ := NewCalendar(LeapDayMarch1)
+		dob := time.Date(1998, time.November, 11, 0, 0, 0, 0, time.UTC)
+
+		It("counts a birthday on the day it occurs", func() {
+			Expect(calendar.AgeAt(dob, time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC))).To(Equal(21))
+			Expect(calendar.AgeAt(dob, time.Date(2019, time.November, 10, 0, 0, 0, 0, time.UTC))).To(Equal(20))
+		})
+
+		It("applies the leap day rule to ages", func() {
+			march1, _ := NewCalendar(LeapDayMarch1)
+			february28, _ := NewCalendar(LeapDayFebruary28)
+			onFebruary28 := time.Date(2021, time.February, 28, 0, 0, 0, 0, time.UTC)
+
+			Expect(march1.AgeAt(leapDayBirth, onFebruary28)).To(Equal(20))
+			Expect(february28.AgeAt(leapDayBirth, onFebruary28)).To(Equal(21))
+		})
+	})
+
+	Describe("YearsBetween", func() {
+		calendar, _ := NewCalendar(LeapDayMarch1)
+
+		It("returns exact whole years on anniversaries", func() {
+			start := time.Date(2009, time.November, 11, 0, 0, 0, 0, time.UTC)
+			Expect(calendar.YearsBetween(start, time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC))).To(Equal(10.0))
+		})
+
+		It("adds the elapsed fraction of the current year", func() {
+			start := time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
+			Expect(calendar.YearsBetween(start, time.Date(2011, time.July, 2, 12, 0, 0, 0, time.UTC))).To(BeNumerically("~", 1.5, 0.001))
+		})
+	})
+
+	Describe("AddSentencePart", func() {
+		calendar, _ := NewCalendar(LeapDayMarch1)
+
+		It("adds days, calendar months and calendar years", func() {
+			start := time.Date(2011, time.January, 31, 0, 0, 0, 0, time.UTC)
+			Expect(calendar.AddSentencePart(start, SentencePart{Length: 30, TimeCode: "D"})).To(Equal(time.Date(2011, time.March, 2, 0, 0, 0, 0, time.UTC)))
+			Expect(calendar.AddSentencePart(start, SentencePart{Length: 1, TimeCode: "M"})).To(Equal(time.Date(2011, time.February, 28, 0, 0, 0, 0, time.UTC)))
+			Expect(calendar.AddSentencePart(start, SentencePart{Length: 13, TimeCode: "M"})).To(Equal(time.Date(2012, time.February, 29, 0, 0, 0, 0, time.UTC)))
+			Expect(calendar.AddSentencePart(start, SentencePart{Length: 2, TimeCode: "Y"})).To(Equal(time.Date(2013, time.January, 31, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("ignores unknown time codes", func() {
+			start := time.Date(2011, time.January, 31, 0, 0, 0, 0, time.UTC)
+			Expect(calendar.AddSentencePart(start, SentencePart{Length: 3, TimeCode: ""})).To(Equal(start))
+		})
+	})
+})
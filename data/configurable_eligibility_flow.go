@@ -4,19 +4,26 @@ import (
 	"errors"
 	"fmt"
 	"gogen/matchers"
+	"strings"
 	"time"
 )
 
 type ConfigurableEligibilityFlow struct {
-	county                               string
-	DismissSections                      []string
-	ReduceSections                       []string
-	dismissConvictionsUnderAgeOf21       bool
-	dismissIfSubjectHasOnlyProp64Charges bool
-	dismissIfSubjectIsDeceased           bool
-	subjectAgeThreshold                  int
-	yearsSinceConvictionThreshold        int
-	yearsCrimeFreeThreshold              int
+	county                                string
+	DismissSections                       []string
+	ReduceSections                        []string
+	dismissConvictionsUnderAgeOf21        bool
+	dismissIfSubjectIsCurrentlyUnder21    bool
+	dismissIfSubjectIsOlderThanFifty      bool
+	dismissIfSubjectHasOnlyProp64Charges  bool
+	dismissIfSubjectIsDeceased            bool
+	dismissIfNoConvictionsPastTenYears    bool
+	subjectAgeThreshold                   int
+	yearsSinceConvictionThreshold         int
+	yearsCrimeFreeThreshold               int
+	handReviewTwoPriorsOfSameSection      bool
+	requireAllSentencesCompletedForRelief bool
+	handReviewTriggers                    HandReviewTriggers
 }
 
 func NewConfigurableEligibilityFlow(options EligibilityOptions, county string) (ConfigurableEligibilityFlow, error) {
@@ -39,18 +46,41 @@ func NewConfigurableEligibilityFlow(options EligibilityOptions, county string) (
 		}
 	}
 
+	dismissSections, err := normalizeCodeSectionKeys(options.BaselineEligibility.Dismiss)
+	if err != nil {
+		return ConfigurableEligibilityFlow{}, err
+	}
+
+	reduceSections, err := normalizeCodeSectionKeys(options.BaselineEligibility.Reduce)
+	if err != nil {
+		return ConfigurableEligibilityFlow{}, err
+	}
+
+	if options.AdditionalRelief.SubjectOlderThanFifty && options.AdditionalRelief.SubjectAgeThreshold != 0 {
+		return ConfigurableEligibilityFlow{}, errors.New("SubjectOlderThanFifty and SubjectAgeThreshold should not both be set")
+	}
+
+	if options.AdditionalRelief.SubjectHasNoConvictionsPastTenYears && options.AdditionalRelief.YearsCrimeFreeThreshold != 0 {
+		return ConfigurableEligibilityFlow{}, errors.New("SubjectHasNoConvictionsPastTenYears and YearsCrimeFreeThreshold should not both be set")
+	}
+
 	return ConfigurableEligibilityFlow{
-		county:                               county,
-		DismissSections:                      options.BaselineEligibility.Dismiss,
-		ReduceSections:                       options.BaselineEligibility.Reduce,
-		dismissConvictionsUnderAgeOf21:       options.AdditionalRelief.SubjectUnder21AtConviction,
-		dismissIfSubjectIsDeceased:           options.AdditionalRelief.SubjectIsDeceased,
-		dismissIfSubjectHasOnlyProp64Charges: options.AdditionalRelief.SubjectHasOnlyProp64Charges,
-		subjectAgeThreshold:                  options.AdditionalRelief.SubjectAgeThreshold,
-		yearsSinceConvictionThreshold:        options.AdditionalRelief.YearsSinceConvictionThreshold,
-		yearsCrimeFreeThreshold:              options.AdditionalRelief.YearsCrimeFreeThreshold,
-	},
-	nil
+		county:                                county,
+		DismissSections:                       dismissSections,
+		ReduceSections:                        reduceSections,
+		dismissConvictionsUnderAgeOf21:        options.AdditionalRelief.SubjectUnder21AtConviction,
+		dismissIfSubjectIsCurrentlyUnder21:    options.AdditionalRelief.SubjectCurrentlyUnder21,
+		dismissIfSubjectIsOlderThanFifty:      options.AdditionalRelief.SubjectOlderThanFifty,
+		dismissIfSubjectIsDeceased:            options.AdditionalRelief.SubjectIsDeceased,
+		dismissIfSubjectHasOnlyProp64Charges:  options.AdditionalRelief.SubjectHasOnlyProp64Charges,
+		dismissIfNoConvictionsPastTenYears:    options.AdditionalRelief.SubjectHasNoConvictionsPastTenYears,
+		subjectAgeThreshold:                   options.AdditionalRelief.SubjectAgeThreshold,
+		yearsSinceConvictionThreshold:         options.AdditionalRelief.YearsSinceConvictionThreshold,
+		yearsCrimeFreeThreshold:               options.AdditionalRelief.YearsCrimeFreeThreshold,
+		handReviewTwoPriorsOfSameSection:      options.AdditionalRelief.HandReviewTwoPriorsOfSameSection,
+		requireAllSentencesCompletedForRelief: options.AdditionalRelief.RequireAllSentencesCompleted,
+		handReviewTriggers:                    options.HandReview,
+	}, nil
 }
 
 func (ef ConfigurableEligibilityFlow) ProcessSubject(subject *Subject, comparisonTime time.Time, flowCounty string) map[int]*EligibilityInfo {
@@ -74,6 +104,42 @@ func (ef ConfigurableEligibilityFlow) checkRelevancy(codeSection string, county
 }
 
 func (ef ConfigurableEligibilityFlow) EvaluateEligibility(info *EligibilityInfo, row *DOJRow, subject *Subject) {
+	if reason := ef.handReviewReason(row, subject); reason != "" {
+		info.SetHandReview(reason)
+		return
+	}
+
+	ef.evaluateDetermination(info, row, subject)
+
+	if reason := ef.maybeEligibleReason(row); reason != "" && reducedOrDismissedFilter(info) {
+		info.SetMaybeEligible(reason)
+	}
+}
+
+func (ef ConfigurableEligibilityFlow) handReviewReason(row *DOJRow, subject *Subject) string {
+	if ef.handReviewTriggers.MissingDateOfBirth && subject.DOB.IsZero() {
+		return "Missing date of birth"
+	}
+	if ef.handReviewTriggers.MissingDispositionDate && row.DispositionDate.IsZero() {
+		return "Missing disposition date"
+	}
+	if ef.handReviewTriggers.UnknownFelonyStatus && row.FelonyStatusUnknown {
+		return "Unknown felony status"
+	}
+	return ""
+}
+
+func (ef ConfigurableEligibilityFlow) maybeEligibleReason(row *DOJRow) string {
+	if ef.handReviewTriggers.CodeSectionOnlyInComment && row.CodeSectionInComment {
+		return "Code section only in comment"
+	}
+	if ef.handReviewTriggers.PossibleProp64ChargeInComment && row.PossibleP64ChargeInComment != "" {
+		return "Possible Prop 64 charge in comment"
+	}
+	return ""
+}
+
+func (ef ConfigurableEligibilityFlow) evaluateDetermination(info *EligibilityInfo, row *DOJRow, subject *Subject) {
 	if !row.IsFelony {
 		info.SetEligibleForDismissal("Misdemeanor or Infraction")
 		return
@@ -82,54 +148,119 @@ func (ef ConfigurableEligibilityFlow) EvaluateEligibility(info *EligibilityInfo,
 		info.SetEligibleForDismissal(composeEligibilityReason(canonicalCodeSection, true))
 		return
 	}
+	// Additional relief the county chose comes before the two priors hand
+	// review, which only catches convictions nothing else would dismiss.
+	reliefWithheld := false
+	if !ef.requireAllSentencesCompletedForRelief || info.allSentencesCompleted(row, subject) {
+		if ef.evaluateAdditionalRelief(info, row, subject) {
+			return
+		}
+	} else {
+		withheld := *info
+		reliefWithheld = ef.evaluateAdditionalRelief(&withheld, row, subject)
+	}
+	if ef.handReviewTwoPriorsOfSameSection && info.hasTwoPriors(row, subject) {
+		info.SetHandReview("Two or more priors of the same code section")
+		return
+	}
+
+	if matched, canonicalCodeSection := ef.isReducedCodeSection(row.CodeSection); matched {
+		reason := composeEligibilityReason(canonicalCodeSection, false)
+		if reliefWithheld {
+			reason = SentenceNotCompletedReason(reason)
+		}
+		info.SetEligibleForReduction(reason)
+		return
+	}
+	if reliefWithheld {
+		info.SetNotEligible(sentenceNotCompleted)
+	}
+}
+
+func (ef ConfigurableEligibilityFlow) evaluateAdditionalRelief(info *EligibilityInfo, row *DOJRow, subject *Subject) bool {
 	if ef.dismissConvictionsUnderAgeOf21 && row.wasConvictionUnderAgeOf21(subject) {
 		info.SetEligibleForDismissal("21 years or younger")
-		return
+		return true
+	}
+	if ef.dismissIfSubjectIsCurrentlyUnder21 && info.youngerThanTwentyOne(row, subject) {
+		info.SetEligibleForDismissal("Currently 21 years or younger")
+		return true
 	}
 	if ef.subjectAgeThreshold != 0 && subject.olderThan(ef.subjectAgeThreshold, info.comparisonTime) {
 		info.SetEligibleForDismissal(fmt.Sprintf("%d years or older", ef.subjectAgeThreshold))
-		return
+		return true
+	}
+	if ef.dismissIfSubjectIsOlderThanFifty && info.olderThanFifty(row, subject) {
+		info.SetEligibleForDismissal("50 years or older")
+		return true
 	}
-	if ef.yearsSinceConvictionThreshold != 0 && row.convictionBefore(ef.yearsSinceConvictionThreshold, info.comparisonTime) {
+	if ef.yearsSinceConvictionThreshold != 0 && row.convictionBefore(info.calendar, ef.yearsSinceConvictionThreshold, info.comparisonTime) {
 		info.SetEligibleForDismissal(fmt.Sprintf("Conviction occurred %d or more years ago", ef.yearsSinceConvictionThreshold))
-		return
+		return true
 	}
-	if ef.yearsCrimeFreeThreshold != 0 && subject.MostRecentConvictionDate().Before(info.comparisonTime.AddDate(-ef.yearsCrimeFreeThreshold, 0, 0)) {
+	if ef.yearsCrimeFreeThreshold != 0 && info.calendar.Anniversary(subject.MostRecentConvictionDate(), ef.yearsCrimeFreeThreshold).Before(info.comparisonTime) {
 		info.SetEligibleForDismissal(fmt.Sprintf("No convictions in the past %d years", ef.yearsCrimeFreeThreshold))
-		return
+		return true
+	}
+	if ef.dismissIfNoConvictionsPastTenYears && info.noConvictionsPastTenYears(row, subject) {
+		info.SetEligibleForDismissal("No convictions in the past 10 years")
+		return true
 	}
 	if ef.dismissIfSubjectHasOnlyProp64Charges && info.onlyProp64Convictions(row, subject) {
 		info.SetEligibleForDismissal("Only has 11357-60 charges")
-		return
+		return true
 	}
-
 	if ef.dismissIfSubjectIsDeceased && subject.IsDeceased {
 		info.SetEligibleForDismissal("Individual is deceased")
-		return
-	}
-
-	if matched, canonicalCodeSection := ef.isReducedCodeSection(row.CodeSection); matched {
-		info.SetEligibleForReduction(composeEligibilityReason(canonicalCodeSection, false))
-		return
+		return true
 	}
+	return false
 }
 
 func (ef ConfigurableEligibilityFlow) isDismissedCodeSection(candidateCodeSection string) (bool, string) {
-	for _, codeSection := range ef.DismissSections {
-		if matchers.Prop64MatchersByCodeSection[codeSection].MatchString(candidateCodeSection) {
-			return true, codeSection
+	codeSection, isDismiss := ef.baselineCodeSection(candidateCodeSection)
+	return codeSection != "" && isDismiss, codeSection
+}
+
+func (ef ConfigurableEligibilityFlow) isReducedCodeSection(candidateCodeSection string) (bool, string) {
+	codeSection, isDismiss := ef.baselineCodeSection(candidateCodeSection)
+	return codeSection != "" && !isDismiss, codeSection
+}
+
+func (ef ConfigurableEligibilityFlow) baselineCodeSection(candidateCodeSection string) (string, bool) {
+	for _, subSectionsOnly := range []bool{true, false} {
+		for _, codeSection := range ef.DismissSections {
+			if matchers.IsProp64SubSectionKey(codeSection) == subSectionsOnly && matchers.MatchesProp64CodeSectionKey(codeSection, candidateCodeSection) {
+				return codeSection, true
+			}
+		}
+		for _, codeSection := range ef.ReduceSections {
+			if matchers.IsProp64SubSectionKey(codeSection) == subSectionsOnly && matchers.MatchesProp64CodeSectionKey(codeSection, candidateCodeSection) {
+				return codeSection, false
+			}
 		}
 	}
-	return false, ""
+	return "", false
 }
 
-func (ef ConfigurableEligibilityFlow) isReducedCodeSection(candidateCodeSection string) (bool, string) {
-	for _, codeSection := range ef.ReduceSections {
-		if matchers.Prop64MatchersByCodeSection[codeSection].MatchString(candidateCodeSection) {
-			return true, codeSection
+func normalizeCodeSectionKeys(codeSections []string) ([]string, error) {
+	var result []string
+	for _, codeSection := range codeSections {
+		key := strings.ToUpper(strings.Join(strings.Fields(codeSection), ""))
+		if !matchers.IsProp64CodeSectionKey(key) {
+			return nil, fmt.Errorf("unknown code section %q in baselineEligibility: expected 11357, 11358, 11359 or 11360, optionally with a subsection such as 11357(A)", codeSection)
 		}
+		result = append(result, key)
 	}
-	return false, ""
+	return result, nil
+}
+
+const sentenceNotCompleted = "Sentence not completed"
+
+// SentenceNotCompletedReason marks a baseline reason on a conviction whose
+// additional relief was withheld because the subject has an open sentence.
+func SentenceNotCompletedReason(reason string) string {
+	return fmt.Sprintf("%s; %s", reason, sentenceNotCompleted)
 }
 
 func composeEligibilityReason(canonicalCodeSection string, isDismiss bool) string {
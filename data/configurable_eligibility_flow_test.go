@@ -495,6 +495,40 @@ var _ = Describe("ConfigurableEligibilityFlow", func() {
 				Expect(infos[2].EligibilityReason).To(Equal("Reduce all HS 11360 convictions"))
 			})
 
+			It("applies the leap day rule to subjects born on February 29", func() {
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					BaselineEligibility: BaselineEligibility{
+						Reduce: []string{"11359"},
+					},
+					AdditionalRelief: AdditionalRelief{
+						SubjectUnder21AtConviction: true,
+					},
+				}, COUNTY)
+
+				leapDaySubject := func(leapDayRule LeapDayRule) *Subject {
+					calendar, err := NewCalendar(leapDayRule)
+					Expect(err).ToNot(HaveOccurred())
+					subject := &Subject{calendar: calendar}
+					subject.PushRow(DOJRow{
+						DOB:             time.Date(1988, time.February, 29, 0, 0, 0, 0, time.UTC),
+						WasConvicted:    true,
+						CodeSection:     "11359 HS",
+						DispositionDate: time.Date(2009, time.February, 28, 0, 0, 0, 0, time.UTC),
+						County:          COUNTY,
+						CountOrder:      "101001001000",
+						Index:           0,
+						IsFelony:        true,
+					}, flow)
+					return subject
+				}
+
+				infos := flow.ProcessSubject(leapDaySubject(LeapDayMarch1), comparisonTime, COUNTY)
+				Expect(infos[0].EligibilityReason).To(Equal("21 years or younger"))
+
+				infos = flow.ProcessSubject(leapDaySubject(LeapDayFebruary28), comparisonTime, COUNTY)
+				Expect(infos[0].EligibilityReason).To(Equal("Reduce all HS 11359 convictions"))
+			})
+
 		})
 
 		Context("When additionalRelief -> yearsSinceConvictionThreshold is set", func() {
@@ -1087,5 +1121,316 @@ var _ = Describe("ConfigurableEligibilityFlow", func() {
 
 		})
 
+		Context("When additionalRelief -> handReviewTwoPriorsOfSameSection", func() {
+			var subject Subject
+
+			BeforeEach(func() {
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					BaselineEligibility: BaselineEligibility{
+						Dismiss: []string{"11357"},
+						Reduce:  []string{"11359"},
+					},
+					AdditionalRelief: AdditionalRelief{
+						HandReviewTwoPriorsOfSameSection: true,
+					},
+				}, COUNTY)
+
+				rows := []DOJRow{
+					{DOB: birthDate, WasConvicted: true, CodeSection: "11359 HS", DispositionDate: time.Date(2001, time.May, 4, 0, 0, 0, 0, time.UTC), County: COUNTY, CountOrder: "101001001000", Index: 0, IsFelony: true},
+					{DOB: birthDate, WasConvicted: true, CodeSection: "11359(A) HS", DispositionDate: time.Date(2003, time.May, 4, 0, 0, 0, 0, time.UTC), County: COUNTY, CountOrder: "102001001000", Index: 1, IsFelony: true},
+					{DOB: birthDate, WasConvicted: true, CodeSection: "11359 HS", DispositionDate: time.Date(2005, time.May, 4, 0, 0, 0, 0, time.UTC), County: COUNTY, CountOrder: "103001001000", Index: 2, IsFelony: true},
+					{DOB: birthDate, WasConvicted: true, CodeSection: "11357 HS", DispositionDate: time.Date(2007, time.May, 4, 0, 0, 0, 0, time.UTC), County: COUNTY, CountOrder: "104001001000", Index: 3, IsFelony: true},
+				}
+				subject = Subject{}
+				for _, row := range rows {
+					subject.PushRow(row, flow)
+				}
+			})
+
+			It("flags convictions with two or more priors of the same section for hand review", func() {
+				infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY)
+				Expect(infos[0].EligibilityDetermination).To(Equal("Eligible for Reduction"))
+				Expect(infos[1].EligibilityDetermination).To(Equal("Eligible for Reduction"))
+				Expect(infos[2].EligibilityDetermination).To(Equal("Hand Review"))
+				Expect(infos[2].EligibilityReason).To(Equal("Two or more priors of the same code section"))
+				Expect(infos[3].EligibilityReason).To(Equal("Dismiss all HS 11357 convictions"))
+			})
+
+			It("dismisses convictions with two priors when additional relief applies", func() {
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					BaselineEligibility: BaselineEligibility{
+						Dismiss: []string{"11357"},
+						Reduce:  []string{"11359"},
+					},
+					AdditionalRelief: AdditionalRelief{
+						HandReviewTwoPriorsOfSameSection: true,
+						SubjectHasOnlyProp64Charges:      true,
+					},
+				}, COUNTY)
+				infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY)
+				Expect(infos[2].EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(infos[2].EligibilityReason).To(Equal("Only has 11357-60 charges"))
+			})
+		})
+
+		Context("When additionalRelief -> subjectCurrentlyUnder21, subjectOlderThanFifty or subjectHasNoConvictionsPastTenYears", func() {
+			var conviction DOJRow
+
+			BeforeEach(func() {
+				conviction = DOJRow{
+					WasConvicted: true,
+					CodeSection:  "11359 HS",
+					County:       COUNTY,
+					CountOrder:   "101001001000",
+					Index:        0,
+					IsFelony:     true,
+				}
+			})
+
+			processWith := func(relief AdditionalRelief, row DOJRow) *EligibilityInfo {
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					BaselineEligibility: BaselineEligibility{Reduce: []string{"11359"}},
+					AdditionalRelief:    relief,
+				}, COUNTY)
+				subject := Subject{}
+				subject.PushRow(row, flow)
+				return flow.ProcessSubject(&subject, comparisonTime, COUNTY)[0]
+			}
+
+			It("dismisses convictions for subjects who are currently 21 or younger", func() {
+				conviction.DOB = time.Date(1999, time.July, 1, 0, 0, 0, 0, time.UTC)
+				conviction.DispositionDate = time.Date(2018, time.May, 4, 0, 0, 0, 0, time.UTC)
+
+				info := processWith(AdditionalRelief{SubjectCurrentlyUnder21: true}, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("Currently 21 years or younger"))
+
+				conviction.DOB = time.Date(1999, time.June, 30, 0, 0, 0, 0, time.UTC)
+				info = processWith(AdditionalRelief{SubjectCurrentlyUnder21: true}, conviction)
+				Expect(info.EligibilityReason).To(Equal("Reduce all HS 11359 convictions"))
+			})
+
+			It("dismisses convictions for subjects who are 50 or older", func() {
+				conviction.DOB = time.Date(1970, time.July, 1, 0, 0, 0, 0, time.UTC)
+				conviction.DispositionDate = time.Date(2018, time.May, 4, 0, 0, 0, 0, time.UTC)
+
+				info := processWith(AdditionalRelief{SubjectOlderThanFifty: true}, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("50 years or older"))
+
+				conviction.DOB = time.Date(1970, time.July, 2, 0, 0, 0, 0, time.UTC)
+				info = processWith(AdditionalRelief{SubjectOlderThanFifty: true}, conviction)
+				Expect(info.EligibilityReason).To(Equal("Reduce all HS 11359 convictions"))
+			})
+
+			It("dismisses convictions for subjects with no convictions in the past ten years", func() {
+				conviction.DOB = time.Date(1980, time.July, 1, 0, 0, 0, 0, time.UTC)
+				conviction.DispositionDate = time.Date(2010, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+				info := processWith(AdditionalRelief{SubjectHasNoConvictionsPastTenYears: true}, conviction)
+				Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+				Expect(info.EligibilityReason).To(Equal("No convictions in the past 10 years"))
+
+				conviction.DispositionDate = time.Date(2010, time.July, 2, 0, 0, 0, 0, time.UTC)
+				info = processWith(AdditionalRelief{SubjectHasNoConvictionsPastTenYears: true}, conviction)
+				Expect(info.EligibilityReason).To(Equal("Reduce all HS 11359 convictions"))
+			})
+		})
+
+		Context("When additionalRelief -> requireAllSentencesCompleted", func() {
+			var subject Subject
+
+			BeforeEach(func() {
+				rows := []DOJRow{
+					{DOB: birthDate, WasConvicted: true, CodeSection: "11359 HS", DispositionDate: time.Date(2001, time.May, 4, 0, 0, 0, 0, time.UTC), SentenceEndDate: time.Date(2002, time.May, 4, 0, 0, 0, 0, time.UTC), County: COUNTY, CountOrder: "101001001000", Index: 0, IsFelony: true},
+					{DOB: birthDate, WasConvicted: true, CodeSection: "187 PC", DispositionDate: time.Date(2005, time.May, 4, 0, 0, 0, 0, time.UTC), SentenceEndDate: time.Date(2025, time.May, 4, 0, 0, 0, 0, time.UTC), County: COUNTY, CountOrder: "102001001000", Index: 1, IsFelony: true},
+				}
+				subject = Subject{}
+				for _, row := range rows {
+					subject.PushRow(row, flow)
+				}
+			})
+
+			It("only grants additional relief to subjects who have completed all sentences", func() {
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					BaselineEligibility: BaselineEligibility{Reduce: []string{"11359"}},
+					AdditionalRelief: AdditionalRelief{
+						YearsSinceConvictionThreshold: 10,
+						RequireAllSentencesCompleted:  true,
+					},
+				}, COUNTY)
+				infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY)
+				Expect(infos[0].EligibilityDetermination).To(Equal("Eligible for Reduction"))
+				Expect(infos[0].EligibilityReason).To(Equal("Reduce all HS 11359 convictions; Sentence not completed"))
+
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					BaselineEligibility: BaselineEligibility{Reduce: []string{"11359"}},
+					AdditionalRelief: AdditionalRelief{
+						YearsSinceConvictionThreshold: 10,
+					},
+				}, COUNTY)
+				infos = flow.ProcessSubject(&subject, comparisonTime, COUNTY)
+				Expect(infos[0].EligibilityReason).To(Equal("Conviction occurred 10 or more years ago"))
+			})
+
+			It("records that relief was withheld for convictions with no baseline relief", func() {
+				flow, _ = NewConfigurableEligibilityFlow(EligibilityOptions{
+					AdditionalRelief: AdditionalRelief{
+						YearsSinceConvictionThreshold: 10,
+						RequireAllSentencesCompleted:  true,
+					},
+				}, COUNTY)
+				infos := flow.ProcessSubject(&subject, comparisonTime, COUNTY)
+				Expect(infos[0].EligibilityDetermination).To(Equal("Not eligible"))
+				Expect(infos[0].EligibilityReason).To(Equal("Sentence not completed"))
+			})
+		})
+	})
+
+	Describe("Subsection baseline eligibility", func() {
+		birthDate := time.Date(1978, time.April, 10, 0, 0, 0, 0, time.UTC)
+		comparisonTime := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		processCodeSection := func(baseline BaselineEligibility, codeSection string) *EligibilityInfo {
+			flow, err := NewConfigurableEligibilityFlow(EligibilityOptions{BaselineEligibility: baseline}, COUNTY)
+			Expect(err).ToNot(HaveOccurred())
+			subject := Subject{}
+			subject.PushRow(DOJRow{
+				DOB:             birthDate,
+				WasConvicted:    true,
+				CodeSection:     codeSection,
+				DispositionDate: time.Date(2010, time.May, 4, 0, 0, 0, 0, time.UTC),
+				County:          COUNTY,
+				CountOrder:      "101001001000",
+				Index:           0,
+				IsFelony:        true,
+			}, flow)
+			return flow.ProcessSubject(&subject, comparisonTime, COUNTY)[0]
+		}
+
+		It("applies subsection keys only to that subsection", func() {
+			baseline := BaselineEligibility{Dismiss: []string{"11357(A)"}, Reduce: []string{"11357"}}
+
+			info := processCodeSection(baseline, "11357(A) HS")
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+			Expect(info.EligibilityReason).To(Equal("Dismiss all HS 11357(A) convictions"))
+
+			info = processCodeSection(baseline, "11357(C) HS")
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Reduction"))
+			Expect(info.EligibilityReason).To(Equal("Reduce all HS 11357 convictions"))
+		})
+
+		It("prefers a subsection key over a whole section key in the other list", func() {
+			info := processCodeSection(BaselineEligibility{Dismiss: []string{"11359"}, Reduce: []string{"11359(B)"}}, "11359(B) HS")
+			Expect(info.EligibilityReason).To(Equal("Reduce all HS 11359(B) convictions"))
+		})
+
+		It("normalizes case and spacing in keys", func() {
+			info := processCodeSection(BaselineEligibility{Dismiss: []string{"11357 (a)"}}, "11357(A) HS")
+			Expect(info.EligibilityReason).To(Equal("Dismiss all HS 11357(A) convictions"))
+		})
+
+		It("rejects unknown keys", func() {
+			_, err := NewConfigurableEligibilityFlow(EligibilityOptions{
+				BaselineEligibility: BaselineEligibility{Dismiss: []string{"11357(E)"}},
+			}, COUNTY)
+			Expect(err).To(MatchError(ContainSubstring(`unknown code section "11357(E)"`)))
+
+			_, err = NewConfigurableEligibilityFlow(EligibilityOptions{
+				BaselineEligibility: BaselineEligibility{Reduce: []string{"11361"}},
+			}, COUNTY)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Hand review triggers", func() {
+		var conviction DOJRow
+		birthDate := time.Date(1978, time.April, 10, 0, 0, 0, 0, time.UTC)
+		comparisonTime := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			conviction = DOJRow{
+				DOB:             birthDate,
+				WasConvicted:    true,
+				CodeSection:     "11359 HS",
+				DispositionDate: time.Date(2010, time.May, 4, 0, 0, 0, 0, time.UTC),
+				County:          COUNTY,
+				CountOrder:      "101001001000",
+				Index:           0,
+				IsFelony:        true,
+			}
+		})
+
+		processWith := func(triggers HandReviewTriggers, row DOJRow) *EligibilityInfo {
+			flow, _ := NewConfigurableEligibilityFlow(EligibilityOptions{
+				BaselineEligibility: BaselineEligibility{Reduce: []string{"11359"}},
+				HandReview:          triggers,
+			}, COUNTY)
+			subject := Subject{}
+			subject.PushRow(row, flow)
+			return flow.ProcessSubject(&subject, comparisonTime, COUNTY)[0]
+		}
+
+		It("flags convictions with a missing date of birth for hand review", func() {
+			conviction.DOB = time.Time{}
+			info := processWith(HandReviewTriggers{MissingDateOfBirth: true}, conviction)
+			Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+			Expect(info.EligibilityReason).To(Equal("Missing date of birth"))
+		})
+
+		It("flags convictions with a missing disposition date for hand review", func() {
+			conviction.DispositionDate = time.Time{}
+			info := processWith(HandReviewTriggers{MissingDispositionDate: true}, conviction)
+			Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+			Expect(info.EligibilityReason).To(Equal("Missing disposition date"))
+		})
+
+		It("flags convictions with an unknown felony status for hand review instead of treating them as misdemeanors", func() {
+			conviction.IsFelony = false
+			conviction.FelonyStatusUnknown = true
+			info := processWith(HandReviewTriggers{UnknownFelonyStatus: true}, conviction)
+			Expect(info.EligibilityDetermination).To(Equal("Hand Review"))
+			Expect(info.EligibilityReason).To(Equal("Unknown felony status"))
+
+			info = processWith(HandReviewTriggers{}, conviction)
+			Expect(info.EligibilityReason).To(Equal("Misdemeanor or Infraction"))
+		})
+
+		It("marks eligible convictions whose code section came from the comment as maybe eligible", func() {
+			conviction.CodeSectionInComment = true
+			info := processWith(HandReviewTriggers{CodeSectionOnlyInComment: true}, conviction)
+			Expect(info.EligibilityDetermination).To(Equal("Maybe Eligible - Flag for Review"))
+			Expect(info.EligibilityReason).To(Equal("Code section only in comment"))
+		})
+
+		It("marks eligible convictions with a possible Prop 64 charge in the comment as maybe eligible", func() {
+			conviction.PossibleP64ChargeInComment = "11357(C)"
+			info := processWith(HandReviewTriggers{PossibleProp64ChargeInComment: true}, conviction)
+			Expect(info.EligibilityDetermination).To(Equal("Maybe Eligible - Flag for Review"))
+			Expect(info.EligibilityReason).To(Equal("Possible Prop 64 charge in comment"))
+		})
+
+		It("does not change determinations when the triggers are off", func() {
+			conviction.DOB = time.Time{}
+			conviction.CodeSectionInComment = true
+			info := processWith(HandReviewTriggers{}, conviction)
+			Expect(info.EligibilityDetermination).To(Equal("Eligible for Reduction"))
+		})
+	})
+
+	Describe("NewConfigurableEligibilityFlow", func() {
+		It("rejects an age threshold together with the 50 or older option", func() {
+			_, err := NewConfigurableEligibilityFlow(EligibilityOptions{
+				AdditionalRelief: AdditionalRelief{SubjectAgeThreshold: 50, SubjectOlderThanFifty: true},
+			}, COUNTY)
+			Expect(err).To(MatchError("SubjectOlderThanFifty and SubjectAgeThreshold should not both be set"))
+		})
+
+		It("rejects a crime free threshold together with the ten year option", func() {
+			_, err := NewConfigurableEligibilityFlow(EligibilityOptions{
+				AdditionalRelief: AdditionalRelief{YearsCrimeFreeThreshold: 5, SubjectHasNoConvictionsPastTenYears: true},
+			}, COUNTY)
+			Expect(err).To(MatchError("SubjectHasNoConvictionsPastTenYears and YearsCrimeFreeThreshold should not both be set"))
+		})
 	})
 })
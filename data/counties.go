@@ -0,0 +1,122 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type County struct {
+	Name     string
+	Code     string
+	Variants []string
+}
+
+var CaliforniaCounties = []County{
+	{Name: "ALAMEDA", Code: "01"},
+	{Name: "ALPINE", Code: "02"},
+	{Name: "AMADOR", Code: "03"},
+	{Name: "BUTTE", Code: "04"},
+	{Name: "CALAVERAS", Code: "05"},
+	{Name: "COLUSA", Code: "06"},
+	{Name: "CONTRA COSTA", Code: "07", Variants: []string{"CONTRACOSTA"}},
+	{Name: "DEL NORTE", Code: "08", Variants: []string{"DELNORTE"}},
+	{Name: "EL DORADO", Code: "09", Variants: []string{"ELDORADO"}},
+	{Name: "FRESNO", Code: "10"},
+	{Name: "GLENN", Code: "11"},
+	{Name: "HUMBOLDT", Code: "12"},
+	{Name: "IMPERIAL", Code: "13"},
+	{Name: "INYO", Code: "14"},
+	{Name: "KERN", Code: "15"},
+	{Name: "KINGS", Code: "16"},
+	{Name: "LAKE", Code: "17"},
+	{Name: "LASSEN", Code: "18"},
+	{Name: "LOS ANGELES", Code: "19", Variants: []string{"LA", "L A", "LOSANGELES"}},
+	{Name: "MADERA", Code: "20"},
+	{Name: "MARIN", Code: "21"},
+	{Name: "MARIPOSA", Code: "22"},
+	{Name: "MENDOCINO", Code: "23"},
+	{Name: "MERCED", Code: "24"},
+	{Name: "MODOC", Code: "25"},
+	{Name: "MONO", Code: "26"},
+	{Name: "MONTEREY", Code: "27"},
+	{Name: "NAPA", Code: "28"},
+	{Name: "NEVADA", Code: "29"},
+	{Name: "ORANGE", Code: "30"},
+	{Name: "PLACER", Code: "31"},
+	{Name: "PLUMAS", Code: "32"},
+	{Name: "RIVERSIDE", Code: "33"},
+	{Name: "SACRAMENTO", Code: "34"},
+	{Name: "SAN BENITO", Code: "35", Variants: []string{"SANBENITO"}},
+	{Name: "SAN BERNARDINO", Code: "36", Variants: []string{"SAN BERNADINO", "SANBERNARDINO"}},
+	{Name: "SAN DIEGO", Code: "37", Variants: []string{"SANDIEGO"}},
+	{Name: "SAN FRANCISCO", Code: "38", Variants: []string{"SF", "S F", "SANFRANCISCO"}},
+	{Name: "SAN JOAQUIN", Code: "39", Variants: []string{"SANJOAQUIN"}},
+	{Name: "SAN LUIS OBISPO", Code: "40", Variants: []string{"SLO", "SANLUISOBISPO"}},
+	{Name: "SAN MATEO", Code: "41", Variants: []string{"SANMATEO"}},
+	{Name: "SANTA BARBARA", Code: "42", Variants: []string{"SANTABARBARA"}},
+	{Name: "SANTA CLARA", Code: "43", Variants: []string{"SANTACLARA"}},
+	{Name: "SANTA CRUZ", Code: "44", Variants: []string{"SANTACRUZ"}},
+	{Name: "SHASTA", Code: "45"},
+	{Name: "SIERRA", Code: "46"},
+	{Name: "SISKIYOU", Code: "47"},
+	{Name: "SOLANO", Code: "48"},
+	{Name: "SONOMA", Code: "49"},
+	{Name: "STANISLAUS", Code: "50"},
+	{Name: "SUTTER", Code: "51"},
+	{Name: "TEHAMA", Code: "52"},
+	{Name: "TRINITY", Code: "53"},
+	{Name: "TULARE", Code: "54"},
+	{Name: "TUOLUMNE", Code: "55"},
+	{Name: "VENTURA", Code: "56"},
+	{Name: "YOLO", Code: "57"},
+	{Name: "YUBA", Code: "58"},
+}
+
+var countiesByKey = indexCounties()
+var countyNameSeparators = regexp.MustCompile(`[^A-Z0-9]+`)
+
+func indexCounties() map[string]County {
+	index := make(map[string]County)
+	for _, county := range CaliforniaCounties {
+		index[county.Code] = county
+		index[county.Name] = county
+		for _, variant := range county.Variants {
+			index[variant] = county
+		}
+	}
+	return index
+}
+
+func normalizeCountyKey(nameOrCode string) string {
+	key := strings.TrimSpace(countyNameSeparators.ReplaceAllString(strings.ToUpper(nameOrCode), " "))
+	key = strings.TrimPrefix(key, "COUNTY OF ")
+	key = strings.TrimSuffix(key, " COUNTY")
+	if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+		key = "0" + key
+	}
+	return key
+}
+
+func FindCounty(nameOrCode string) (County, bool) {
+	county, ok := countiesByKey[normalizeCountyKey(nameOrCode)]
+	return county, ok
+}
+
+func NormalizeCounty(nameOrCode string) (string, error) {
+	county, ok := FindCounty(nameOrCode)
+	if !ok {
+		return "", fmt.Errorf("unknown county %q: expected a California county name or STP_ORI_CNTY_CODE", nameOrCode)
+	}
+	return county.Name, nil
+}
+
+func countyForRow(rawRow []string) string {
+	if county, ok := FindCounty(rawRow[STP_ORI_CNTY_CODE]); ok {
+		return county.Name
+	}
+	if county, ok := FindCounty(rawRow[STP_ORI_CNTY_NAME]); ok {
+		return county.Name
+	}
+	return normalizeCountyKey(rawRow[STP_ORI_CNTY_NAME])
+}
@@ -0,0 +1,39 @@
+package data_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen/data"
+)
+
+var _ = Describe("Counties", func() {
+	It("has all 58 California counties", func() {
+		Expect(CaliforniaCounties).To(HaveLen(58))
+	})
+
+	It("normalizes case, spacing and punctuation in county names", func() {
+		for _, name := range []string{"SAN JOAQUIN", "San Joaquin", " SAN JOAQUIN ", "san_joaquin", "San Joaquin County", "County of San Joaquin"} {
+			Expect(NormalizeCounty(name)).To(Equal("SAN JOAQUIN"), name)
+		}
+	})
+
+	It("recognizes STP_ORI_CNTY_CODE values", func() {
+		Expect(NormalizeCounty("39")).To(Equal("SAN JOAQUIN"))
+		Expect(NormalizeCounty("1")).To(Equal("ALAMEDA"))
+		Expect(NormalizeCounty("57")).To(Equal("YOLO"))
+	})
+
+	It("recognizes known name variants", func() {
+		Expect(NormalizeCounty("S.F.")).To(Equal("SAN FRANCISCO"))
+		Expect(NormalizeCounty("LA")).To(Equal("LOS ANGELES"))
+		Expect(NormalizeCounty("San Bernadino")).To(Equal("SAN BERNARDINO"))
+	})
+
+	It("rejects names that are not California counties", func() {
+		_, err := NormalizeCounty("SAN JOAQUN")
+		Expect(err).To(MatchError(`unknown county "SAN JOAQUN": expected a California county name or STP_ORI_CNTY_CODE`))
+		_, err = NormalizeCounty("59")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,193 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"gogen/matchers"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const CourtMatchThreshold = 0.75
+
+type CourtColumnMapping struct {
+	DocketNumber    string `json:"docketNumber"`
+	Name            string `json:"name"`
+	LastName        string `json:"lastName"`
+	FirstName       string `json:"firstName"`
+	DateOfBirth     string `json:"dateOfBirth"`
+	DispositionDate string `json:"dispositionDate"`
+	Charge          string `json:"charge"`
+	DateFormat      string `json:"dateFormat"`
+}
+
+var DefaultCourtColumnMapping = CourtColumnMapping{
+	DocketNumber:    "DOCKET_NUMBER",
+	Name:            "NAME",
+	DateOfBirth:     "DOB",
+	DispositionDate: "DISPOSITION_DATE",
+	Charge:          "CHARGE",
+	DateFormat:      "01/02/2006",
+}
+
+type CourtRecord struct {
+	DocketNumber    string
+	Name            string
+	DOB             time.Time
+	DispositionDate time.Time
+	Charge          string
+}
+
+type CourtMatcher struct {
+	recordsByDOB map[string][]CourtRecord
+}
+
+func ReadCourtRecords(courtFilePath string, mapping CourtColumnMapping) ([]CourtRecord, error) {
+	courtFile, err := os.Open(courtFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer courtFile.Close()
+
+	rows, err := csv.NewReader(courtFile).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("court CMS file %s is empty", courtFilePath)
+	}
+
+	columns := make(map[string]int)
+	for index, header := range rows[0] {
+		columns[strings.TrimSpace(header)] = index
+	}
+	column := func(name string) (int, error) {
+		index, ok := columns[name]
+		if !ok {
+			return 0, fmt.Errorf("court CMS file is missing column %q", name)
+		}
+		return index, nil
+	}
+
+	docketColumn, err := column(mapping.DocketNumber)
+	if err != nil {
+		return nil, err
+	}
+	dobColumn, err := column(mapping.DateOfBirth)
+	if err != nil {
+		return nil, err
+	}
+	dispositionDateColumn, err := column(mapping.DispositionDate)
+	if err != nil {
+		return nil, err
+	}
+	chargeColumn, err := column(mapping.Charge)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameColumns []int
+	if mapping.LastName != "" {
+		lastNameColumn, err := column(mapping.LastName)
+		if err != nil {
+			return nil, err
+		}
+		firstNameColumn, err := column(mapping.FirstName)
+		if err != nil {
+			return nil, err
+		}
+		nameColumns = []int{lastNameColumn, firstNameColumn}
+	} else {
+		nameColumn, err := column(mapping.Name)
+		if err != nil {
+			return nil, err
+		}
+		nameColumns = []int{nameColumn}
+	}
+
+	var records []CourtRecord
+	for _, row := range rows[1:] {
+		var nameParts []string
+		for _, index := range nameColumns {
+			nameParts = append(nameParts, strings.TrimSpace(row[index]))
+		}
+		records = append(records, CourtRecord{
+			DocketNumber:    strings.TrimSpace(row[docketColumn]),
+			Name:            strings.Join(nameParts, ","),
+			DOB:             parseDate(mapping.DateFormat, strings.TrimSpace(row[dobColumn])),
+			DispositionDate: parseDate(mapping.DateFormat, strings.TrimSpace(row[dispositionDateColumn])),
+			Charge:          strings.TrimSpace(row[chargeColumn]),
+		})
+	}
+	return records, nil
+}
+
+func NewCourtMatcher(records []CourtRecord) CourtMatcher {
+	recordsByDOB := make(map[string][]CourtRecord)
+	for _, record := range records {
+		if !record.DOB.IsZero() {
+			key := record.DOB.Format(dateFormat)
+			recordsByDOB[key] = append(recordsByDOB[key], record)
+		}
+	}
+	return CourtMatcher{recordsByDOB: recordsByDOB}
+}
+
+func (m CourtMatcher) Match(row *DOJRow, subject *Subject) (CourtRecord, float64, bool) {
+	var bestRecord CourtRecord
+	bestConfidence := 0.0
+	if subject.DOB.IsZero() {
+		return bestRecord, bestConfidence, false
+	}
+	for _, record := range m.recordsByDOB[subject.DOB.Format(dateFormat)] {
+		confidence := 0.3 + 0.4*nameSimilarity(subject.Name, record.Name)
+		if !row.DispositionDate.IsZero() && row.DispositionDate.Equal(record.DispositionDate) {
+			confidence += 0.15
+		}
+		if sameCharge(row.CodeSection, record.Charge) {
+			confidence += 0.15
+		}
+		if confidence > bestConfidence {
+			bestRecord, bestConfidence = record, confidence
+		}
+	}
+	return bestRecord, bestConfidence, bestConfidence >= CourtMatchThreshold
+}
+
+func (i *DOJInformation) MatchCourtRecords(eligibilities map[int]*EligibilityInfo, matcher CourtMatcher) []int {
+	var unmatched []int
+	for _, subject := range i.Subjects {
+		for _, conviction := range subject.Convictions {
+			info := eligibilities[conviction.Index]
+			if info == nil || !eligibleForReliefFilter(info) {
+				continue
+			}
+			record, confidence, ok := matcher.Match(conviction, subject)
+			if ok {
+				info.CourtDocketNumber = record.DocketNumber
+				info.CourtMatchConfidence = confidence
+			} else {
+				unmatched = append(unmatched, conviction.Index)
+			}
+		}
+	}
+	sort.Ints(unmatched)
+	return unmatched
+}
+
+func sameCharge(codeSection string, charge string) bool {
+	if ok, chargeSubSection := matchers.ExtractProp64SubSection(charge); ok {
+		if _, rowSubSection := matchers.ExtractProp64SubSection(codeSection); rowSubSection != "" {
+			return rowSubSection == chargeSubSection
+		}
+	}
+	ok, chargeSection := matchers.ExtractProp64Section(charge)
+	_, rowSection := matchers.ExtractProp64Section(codeSection)
+	return ok && chargeSection == rowSection
+}
+
+func eligibleForReliefFilter(eligibility *EligibilityInfo) bool {
+	return reducedOrDismissedFilter(eligibility) || eligibility.EligibilityDetermination == "Maybe Eligible - Flag for Review"
+}
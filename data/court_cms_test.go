@@ -0,0 +1,87 @@
+package data_test
+
+import (
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen/data"
+)
+
+var _ = Describe("ReadCourtRecords", func() {
+	pathToCourtCMS := path.Join("..", "test_fixtures", "court_cms.csv")
+	mapping := CourtColumnMapping{
+		DocketNumber:    "Case Number",
+		LastName:        "Last Name",
+		FirstName:       "First Name",
+		DateOfBirth:     "Birth Date",
+		DispositionDate: "Disposition Date",
+		Charge:          "Charge Code",
+		DateFormat:      "2006-01-02",
+	}
+
+	It("reads records using the column mapping", func() {
+		records, err := ReadCourtRecords(pathToCourtCMS, mapping)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(HaveLen(3))
+		Expect(records[0]).To(Equal(CourtRecord{
+			DocketNumber:    "CR-2014-0001",
+			Name:            "SKYWALKER,LUKE",
+			DOB:             time.Date(1960, time.March, 14, 0, 0, 0, 0, time.UTC),
+			DispositionDate: time.Date(2014, time.February, 11, 0, 0, 0, 0, time.UTC),
+			Charge:          "HS 11358",
+		}))
+	})
+
+	It("returns an error when a mapped column is missing", func() {
+		_, err := ReadCourtRecords(pathToCourtCMS, DefaultCourtColumnMapping)
+		Expect(err).To(MatchError(`court CMS file is missing column "DOCKET_NUMBER"`))
+	})
+})
+
+var _ = Describe("MatchCourtRecords", func() {
+	var (
+		dojInformation *DOJInformation
+		eligibilities  map[int]*EligibilityInfo
+		matcher        CourtMatcher
+	)
+	birthDate := time.Date(1960, time.March, 14, 0, 0, 0, 0, time.UTC)
+	dispositionDate := time.Date(2014, time.February, 11, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		subject := new(Subject)
+		subject.PushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE S", DOB: birthDate, WasConvicted: true, CodeSection: "11358 HS", DispositionDate: dispositionDate, CountOrder: "101001001000", Index: 0}, nil)
+		subject.PushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE S", DOB: birthDate, WasConvicted: true, CodeSection: "11357 HS", DispositionDate: dispositionDate.AddDate(1, 0, 0), CountOrder: "102001001000", Index: 1}, nil)
+		subject.PushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE S", DOB: birthDate, WasConvicted: true, CodeSection: "11360 HS", DispositionDate: dispositionDate, CountOrder: "103001001000", Index: 2}, nil)
+		dojInformation = &DOJInformation{Subjects: map[string]*Subject{"100": subject}}
+
+		eligibilities = map[int]*EligibilityInfo{0: {}, 1: {}, 2: {}}
+		eligibilities[0].SetEligibleForDismissal("Dismiss all 11358 convictions")
+		eligibilities[1].SetEligibleForDismissal("Dismiss all 11357 convictions")
+		eligibilities[2].SetNotEligible("Occurred after 11/09/2016")
+
+		matcher = NewCourtMatcher([]CourtRecord{
+			{DocketNumber: "CR-2014-0001", Name: "SKYWALKER,LUKE", DOB: birthDate, DispositionDate: dispositionDate, Charge: "HS 11358"},
+			{DocketNumber: "CR-2014-0002", Name: "ORGANA,LEIA", DOB: birthDate, DispositionDate: dispositionDate.AddDate(1, 0, 0), Charge: "HS 11357"},
+		})
+	})
+
+	It("adds the docket number and match confidence to eligible convictions", func() {
+		dojInformation.MatchCourtRecords(eligibilities, matcher)
+		Expect(eligibilities[0].CourtDocketNumber).To(Equal("CR-2014-0001"))
+		Expect(eligibilities[0].CourtMatchConfidence).To(BeNumerically("~", 1.0, 0.001))
+	})
+
+	It("returns eligible convictions without a confident match", func() {
+		unmatched := dojInformation.MatchCourtRecords(eligibilities, matcher)
+		Expect(unmatched).To(Equal([]int{1}))
+		Expect(eligibilities[1].CourtDocketNumber).To(BeEmpty())
+	})
+
+	It("does not match convictions that are not eligible", func() {
+		dojInformation.MatchCourtRecords(eligibilities, matcher)
+		Expect(eligibilities[2].CourtDocketNumber).To(BeEmpty())
+	})
+})
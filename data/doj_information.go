@@ -8,6 +8,7 @@ import (
 	"gogen/utilities"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,7 +17,9 @@ type DOJInformation struct {
 	Rows                 [][]string
 	Subjects             map[string]*Subject
 	comparisonTime       time.Time
+	calendar             Calendar
 	checksRelatedCharges bool
+	convictionsByIndex   map[int]*DOJRow
 }
 
 func (i *DOJInformation) aggregateSubjects(eligibilityFlow EligibilityFlow) {
@@ -28,9 +31,9 @@ func (i *DOJInformation) aggregateSubjects(eligibilityFlow EligibilityFlow) {
 
 	for index, row := range i.Rows {
 		startTime := time.Now()
-		dojRow := NewDOJRow(row, index)
+		dojRow := NewDOJRow(row, index, i.calendar)
 		if i.Subjects[dojRow.SubjectID] == nil {
-			i.Subjects[dojRow.SubjectID] = new(Subject)
+			i.Subjects[dojRow.SubjectID] = &Subject{calendar: i.calendar}
 		}
 		i.Subjects[dojRow.SubjectID].PushRow(dojRow, eligibilityFlow)
 
@@ -52,6 +55,49 @@ func (i *DOJInformation) DetermineEligibility(county string, eligibilityFlow Eli
 	return eligibilities
 }
 
+func (i *DOJInformation) ComparisonTime() time.Time {
+	return i.comparisonTime
+}
+
+func (i *DOJInformation) Calendar() Calendar {
+	return i.calendar
+}
+
+func (i *DOJInformation) ConvictionCounties() []string {
+	seen := make(map[string]bool)
+	var counties []string
+	for _, subject := range i.Subjects {
+		for _, conviction := range subject.Convictions {
+			if conviction.County != "" && !seen[conviction.County] {
+				seen[conviction.County] = true
+				counties = append(counties, conviction.County)
+			}
+		}
+	}
+	sort.Strings(counties)
+	return counties
+}
+
+func (i *DOJInformation) HasRowsInCounty(county string) bool {
+	for _, row := range i.Rows {
+		if countyForRow(row) == county {
+			return true
+		}
+	}
+	return false
+}
+
+// RowIndexesInCounty lists the rows reported by county, in input order.
+func (i *DOJInformation) RowIndexesInCounty(county string) []int {
+	indexes := []int{}
+	for index, row := range i.Rows {
+		if countyForRow(row) == county {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
 func (i *DOJInformation) TotalRows() int {
 	return len(i.Rows)
 }
@@ -64,6 +110,24 @@ func (i *DOJInformation) TotalConvictions() int {
 	return totalConvictions
 }
 
+// ParsedRow is the row at index as eligibility saw it, so a conviction has the
+// sentence end date built up from the sentence rows that follow it.
+func (i *DOJInformation) ParsedRow(index int) *DOJRow {
+	if i.convictionsByIndex == nil {
+		i.convictionsByIndex = make(map[int]*DOJRow)
+		for _, subject := range i.Subjects {
+			for _, conviction := range subject.Convictions {
+				i.convictionsByIndex[conviction.Index] = conviction
+			}
+		}
+	}
+	if conviction, ok := i.convictionsByIndex[index]; ok {
+		return conviction
+	}
+	row := NewDOJRow(i.Rows[index], index, i.calendar)
+	return &row
+}
+
 func (i *DOJInformation) Prop64ConvictionsInThisCountyByCodeSection(county string) map[string]int {
 	return i.countByCodeSectionFilteredMatchedConvictions(county, countyFilter, matchers.ExtractProp64Section)
 }
@@ -72,6 +136,14 @@ func (i *DOJInformation) Prop64ConvictionsInThisCountyByEligibilityByReason(coun
 	return i.countByCodeSectionAndEligibilityFilteredMatchedConvictions(county, eligibilities, countyFilter, matchers.ExtractProp64Section, countByEligibilityDeterminationAndReason)
 }
 
+func (i *DOJInformation) Prop64ConvictionsInThisCountyByYearByFelonyStatus(county string) map[string]map[string]int {
+	return i.countByCodeSectionAndEligibilityFilteredMatchedConvictions(county, nil, countyFilter, matchers.ExtractProp64Section, countByDispositionYearAndFelonyStatus)
+}
+
+func (i *DOJInformation) Prop64ConvictionsInThisCountyByYearByEligibility(county string, eligibilities map[int]*EligibilityInfo) map[string]map[string]int {
+	return i.countByCodeSectionAndEligibilityFilteredMatchedConvictions(county, eligibilities, countyFilter, matchers.ExtractProp64Section, countByDispositionYearAndEligibilityDetermination)
+}
+
 func (i *DOJInformation) EarliestProp64ConvictionDateInThisCounty(county string) time.Time {
 	var convictionDates = TimeSlice{}
 	for _, subject := range i.Subjects {
@@ -88,7 +160,7 @@ func (i *DOJInformation) EarliestProp64ConvictionDateInThisCounty(county string)
 	if len(convictionDates) > 0 {
 		return convictionDates[0]
 	} else {
-		return time.Now()
+		return i.comparisonTime
 	}
 }
 
@@ -124,10 +196,52 @@ func (i *DOJInformation) CountIndividualsNoLongerHaveConviction(eligibilities ma
 }
 
 func (i *DOJInformation) CountIndividualsNoLongerHaveConvictionInLast7Years(eligibilities map[int]*EligibilityInfo) int {
+	occurredInLast7YearsFilter := func(conviction *DOJRow) bool {
+		return conviction.OccurredInLast7Years(i.calendar, i.comparisonTime)
+	}
 	return i.countIndividualsFilteredByFullRelief(eligibilities, occurredInLast7YearsFilter, dismissedFilter)
 }
 
-func NewDOJInformation(dojFileName string, comparisonTime time.Time, eligibilityFlow EligibilityFlow) (*DOJInformation, utilities.GogenError) {
+func (i *DOJInformation) Prop64ReliefByGroup(county string, eligibilities map[int]*EligibilityInfo, group func(subject *Subject) string) map[string]map[string]int {
+	groupCounts := make(map[string]map[string]int)
+	for _, subject := range i.Subjects {
+		prop64Convictions := 0
+		eligibleConvictions := 0
+		for _, conviction := range subject.Convictions {
+			if countyFilter(county, conviction) && matchers.IsProp64Charge(conviction.CodeSection) {
+				prop64Convictions++
+				if eligibilities[conviction.Index] != nil && reducedOrDismissedFilter(eligibilities[conviction.Index]) {
+					eligibleConvictions++
+				}
+			}
+		}
+		if prop64Convictions == 0 {
+			continue
+		}
+
+		counts := groupCounts[group(subject)]
+		if counts == nil {
+			counts = map[string]int{
+				"Prop64Convictions":         0,
+				"EligibleConvictions":       0,
+				"CountSubjectsNoFelony":     0,
+				"CountSubjectsNoConviction": 0,
+			}
+			groupCounts[group(subject)] = counts
+		}
+		counts["Prop64Convictions"] += prop64Convictions
+		counts["EligibleConvictions"] += eligibleConvictions
+		if subject.hasFullRelief(eligibilities, IsFelonyFilter, reducedOrDismissedFilter) {
+			counts["CountSubjectsNoFelony"]++
+		}
+		if subject.hasFullRelief(eligibilities, hasConvictionFilter, dismissedFilter) {
+			counts["CountSubjectsNoConviction"]++
+		}
+	}
+	return groupCounts
+}
+
+func NewDOJInformation(dojFileName string, comparisonTime time.Time, calendar Calendar, eligibilityFlow EligibilityFlow) (*DOJInformation, utilities.GogenError) {
 	dojFile, err := os.Open(dojFileName)
 	if err != nil {
 		return nil, utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
@@ -153,6 +267,7 @@ func NewDOJInformation(dojFileName string, comparisonTime time.Time, eligibility
 		Rows:                 rows,
 		Subjects:             make(map[string]*Subject),
 		comparisonTime:       comparisonTime,
+		calendar:             calendar,
 		checksRelatedCharges: eligibilityFlow.ChecksRelatedCharges(),
 	}
 
@@ -221,19 +336,7 @@ func (i *DOJInformation) countIndividualsFilteredByFullRelief(
 	reliefFilter func(eligibility *EligibilityInfo) bool) int {
 	countIndividuals := 0
 	for _, subject := range i.Subjects {
-		countConvictions := 0
-		countRelief := 0
-		for _, conviction := range subject.Convictions {
-			if convictionFilter(conviction) {
-				countConvictions++
-				if eligibilities[conviction.Index] != nil {
-					if reliefFilter(eligibilities[conviction.Index]) {
-						countRelief++
-					}
-				}
-			}
-		}
-		if countConvictions != 0 && (countConvictions == countRelief) {
+		if subject.hasFullRelief(eligibilities, convictionFilter, reliefFilter) {
 			countIndividuals++
 		}
 	}
@@ -267,6 +370,46 @@ func countByEligibilityDeterminationAndReason(
 	return convictionMap
 }
 
+func countByDispositionYearAndFelonyStatus(
+	conviction *DOJRow,
+	_ string,
+	_ map[int]*EligibilityInfo,
+	convictionMap map[string]map[string]int) map[string]map[string]int {
+	year := dispositionYear(conviction)
+	if convictionMap[year] == nil {
+		convictionMap[year] = make(map[string]int)
+	}
+	if conviction.IsFelony {
+		convictionMap[year]["Felony"]++
+	} else {
+		convictionMap[year]["NonFelony"]++
+	}
+	return convictionMap
+}
+
+func countByDispositionYearAndEligibilityDetermination(
+	conviction *DOJRow,
+	_ string,
+	eligibilities map[int]*EligibilityInfo,
+	convictionMap map[string]map[string]int) map[string]map[string]int {
+	if eligibilities[conviction.Index] == nil {
+		return convictionMap
+	}
+	year := dispositionYear(conviction)
+	if convictionMap[year] == nil {
+		convictionMap[year] = make(map[string]int)
+	}
+	convictionMap[year][eligibilities[conviction.Index].EligibilityDetermination]++
+	return convictionMap
+}
+
+func dispositionYear(conviction *DOJRow) string {
+	if conviction.DispositionDate.IsZero() {
+		return "UNKNOWN"
+	}
+	return strconv.Itoa(conviction.DispositionDate.Year())
+}
+
 func (i *DOJInformation) TotalConvictionsInCountyFiltered(county string, convictionFilter func(conviction *DOJRow) bool, matcher func(codeSection string) bool) int {
 	result := 0
 	for _, subject := range i.Subjects {
@@ -299,10 +442,6 @@ func IsNotFelonyFilter(conviction *DOJRow) bool {
 	return !conviction.IsFelony
 }
 
-func occurredInLast7YearsFilter(conviction *DOJRow) bool {
-	return conviction.OccurredInLast7Years()
-}
-
 func reducedOrDismissedFilter(eligibility *EligibilityInfo) bool {
 	determination := eligibility.EligibilityDetermination
 	return determination == "Eligible for Dismissal" || determination == "Eligible for Reduction"
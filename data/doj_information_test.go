@@ -77,7 +77,7 @@ var _ = Describe("DojInformation", func() {
 				SubjectIsDeceased:             true,
 			},
 		}, county)
-		dojInformation, _ = NewDOJInformation(pathToDOJ, comparisonTime, configurableFlow)
+		dojInformation, _ = NewDOJInformation(pathToDOJ, comparisonTime, Calendar{}, configurableFlow)
 
 		testEligibilities = dojInformation.DetermineEligibility(county, testFlow)
 		dojEligibilities = dojInformation.DetermineEligibility(county, configurableFlow)
@@ -89,8 +89,8 @@ var _ = Describe("DojInformation", func() {
 			county = "LAKE"
 		})
 
-		It("Exits succesfully with dummy datetime", func() {
-			Expect(dojInformation.EarliestProp64ConvictionDateInThisCounty(county)).To(BeTemporally("~", time.Now(), time.Second))
+		It("Exits succesfully with the comparison time as a dummy datetime", func() {
+			Expect(dojInformation.EarliestProp64ConvictionDateInThisCounty(county)).To(Equal(comparisonTime))
 		})
 	})
 
@@ -118,6 +118,25 @@ var _ = Describe("DojInformation", func() {
 				Expect(dojInformation.TotalConvictions()).To(Equal(30))
 			})
 
+			It("Gives the accumulated conviction for a conviction row and parses other rows", func() {
+				convictionIndexes := make(map[int]bool)
+				for _, subject := range dojInformation.Subjects {
+					for _, conviction := range subject.Convictions {
+						convictionIndexes[conviction.Index] = true
+						Expect(dojInformation.ParsedRow(conviction.Index)).To(BeIdenticalTo(conviction))
+					}
+				}
+				for index, row := range dojInformation.Rows {
+					if !convictionIndexes[index] {
+						Expect(*dojInformation.ParsedRow(index)).To(Equal(NewDOJRow(row, index, Calendar{})))
+					}
+				}
+			})
+
+			It("Lists the counties with convictions in the file", func() {
+				Expect(dojInformation.ConvictionCounties()).To(Equal([]string{"SACRAMENTO", "YOLO"}))
+			})
+
 			It("Counts Prop64 convictions in this county sorted by code section", func() {
 				Expect(dojInformation.Prop64ConvictionsInThisCountyByCodeSection(county)).To(Equal(map[string]int{"11357": 3, "11358": 7, "11359": 8}))
 			})
@@ -144,6 +163,17 @@ var _ = Describe("DojInformation", func() {
 					}))
 			})
 
+			It("Counts Prop64 convictions in this county by disposition year", func() {
+				byFelonyStatus := dojInformation.Prop64ConvictionsInThisCountyByYearByFelonyStatus(county)
+				Expect(byFelonyStatus).To(HaveLen(14))
+				Expect(byFelonyStatus["1981"]).To(Equal(map[string]int{"Felony": 2}))
+				Expect(byFelonyStatus["2015"]).To(Equal(map[string]int{"Felony": 2, "NonFelony": 1}))
+
+				byEligibility := dojInformation.Prop64ConvictionsInThisCountyByYearByEligibility(county, dojEligibilities)
+				Expect(byEligibility).To(HaveLen(14))
+				Expect(byEligibility["2015"]).To(Equal(map[string]int{"Eligible for Dismissal": 2, "Eligible for Reduction": 1}))
+			})
+
 			Context("Computing aggregate statistics for individuals", func() {
 				Context("After eligibility is run", func() {
 					It("Calculates individuals who will no longer have a felony ", func() {
@@ -161,6 +191,20 @@ var _ = Describe("DojInformation", func() {
 					It("Calculates individuals who will have some relief", func() {
 						Expect(dojInformation.CountIndividualsWithSomeRelief(dojEligibilities)).To(Equal(12))
 					})
+
+					It("Counts Prop64 convictions and relief by a grouping of individuals", func() {
+						byGender := dojInformation.Prop64ReliefByGroup(county, dojEligibilities, func(subject *Subject) string {
+							return subject.Gender
+						})
+						Expect(byGender).To(Equal(map[string]map[string]int{
+							"": {
+								"Prop64Convictions":         18,
+								"EligibleConvictions":       18,
+								"CountSubjectsNoFelony":     5,
+								"CountSubjectsNoConviction": 4,
+							},
+						}))
+					})
 				})
 
 			})
@@ -1,52 +1,67 @@
 package data
 
 import (
+	"gogen/matchers"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type DOJRow struct {
-	SubjectID              string
-	DOB                    time.Time
-	Name                   string
-	WasConvicted           bool
-	CodeSection            string
-	DispositionDate        time.Time
-	OFN                    string
-	Type                   string
-	IsPC290Registration    bool
-	County                 string
-	IsFelony               bool
-	NumCrtCase             string
-	CourtNoParts           []string
-	CountOrder             string
-	Index                  int
-	SentenceEndDate        time.Time
-	SentencePartDuration   time.Duration
-	HasProp64ChargeInCycle bool
+	SubjectID                  string
+	DOB                        time.Time
+	Name                       string
+	CII                        string
+	FBINumber                  string
+	WasConvicted               bool
+	CodeSection                string
+	DispositionDate            time.Time
+	OFN                        string
+	Type                       string
+	IsPC290Registration        bool
+	County                     string
+	IsFelony                   bool
+	NumCrtCase                 string
+	CourtNoParts               []string
+	CountOrder                 string
+	Index                      int
+	SentenceEndDate            time.Time
+	SentencePart               SentencePart
+	HasProp64ChargeInCycle     bool
+	CodeSectionInComment       bool
+	PossibleP64ChargeInComment string
+	FelonyStatusUnknown        bool
+	Race                       string
+	Gender                     string
 }
 
 const dateFormat = "20060102"
 
-func NewDOJRow(rawRow []string, index int) DOJRow {
+func NewDOJRow(rawRow []string, index int, calendar Calendar) DOJRow {
 
 	return DOJRow{
-		Name:                 rawRow[PRI_NAME],
-		SubjectID:            rawRow[SUBJECT_ID],
-		DOB:                  parseDate(dateFormat, rawRow[PRI_DOB]),
-		WasConvicted:         strings.HasPrefix(rawRow[DISP_DESCR], "CONVICTED"),
-		CodeSection:          findCodeSection(rawRow),
-		DispositionDate:      parseDate(dateFormat, rawRow[STP_EVENT_DATE]),
-		OFN:                  rawRow[OFN],
-		Type:                 rawRow[STP_TYPE_DESCR],
-		IsPC290Registration:  rawRow[STP_TYPE_DESCR] == "REGISTRATION" && strings.HasPrefix(rawRow[OFFENSE_DESCR], "290"),
-		County:               rawRow[STP_ORI_CNTY_NAME],
-		IsFelony:             isFelony(rawRow),
-		CountOrder:           rawRow[CNT_ORDER],
-		Index:                index,
-		SentenceEndDate:      getSentenceEndDate(rawRow),
-		SentencePartDuration: getSentencePartDuration(rawRow),
+		Name:                       rawRow[PRI_NAME],
+		SubjectID:                  rawRow[SUBJECT_ID],
+		CII:                        strings.TrimSpace(rawRow[CII_NUMBER]),
+		FBINumber:                  strings.TrimSpace(rawRow[FBI_NUMBER]),
+		DOB:                        parseDate(dateFormat, rawRow[PRI_DOB]),
+		WasConvicted:               strings.HasPrefix(rawRow[DISP_DESCR], "CONVICTED"),
+		CodeSection:                findCodeSection(rawRow),
+		DispositionDate:            parseDate(dateFormat, rawRow[STP_EVENT_DATE]),
+		OFN:                        rawRow[OFN],
+		Type:                       rawRow[STP_TYPE_DESCR],
+		IsPC290Registration:        rawRow[STP_TYPE_DESCR] == "REGISTRATION" && strings.HasPrefix(rawRow[OFFENSE_DESCR], "290"),
+		County:                     countyForRow(rawRow),
+		IsFelony:                   isFelony(rawRow),
+		CountOrder:                 rawRow[CNT_ORDER],
+		Index:                      index,
+		SentenceEndDate:            getSentenceEndDate(rawRow, calendar),
+		SentencePart:               getSentencePart(rawRow),
+		CodeSectionInComment:       IsCodeSectionInComment(rawRow[OFFENSE_DESCR]),
+		PossibleP64ChargeInComment: PossibleP64ChargeOnlyInComment(rawRow[OFFENSE_DESCR], rawRow[COMMENT_TEXT]),
+		FelonyStatusUnknown:        isFelonyStatusUnknown(rawRow),
+		Race:                       strings.TrimSpace(rawRow[RACE_DESCR]),
+		Gender:                     strings.TrimSpace(rawRow[GENDER]),
 	}
 }
 
@@ -54,27 +69,22 @@ func isFelony(rawRow []string) bool {
 	return rawRow[CONV_STAT_DESCR] == "FELONY" || (rawRow[CONV_STAT_DESCR] == "" && rawRow[OFFENSE_TOC] == "F")
 }
 
-func getSentenceEndDate(rawRow []string) time.Time {
-	dispDate := parseDate(dateFormat, rawRow[STP_EVENT_DATE])
-	return dispDate.Add(getSentencePartDuration(rawRow))
+func isFelonyStatusUnknown(rawRow []string) bool {
+	if rawRow[CONV_STAT_DESCR] != "" {
+		return false
+	}
+	toc := strings.TrimSpace(rawRow[OFFENSE_TOC])
+	return toc != "F" && toc != "M" && toc != "I"
 }
 
-func getSentencePartDuration(rawRow []string) time.Duration {
-	sentenceLength, _ := strconv.Atoi(rawRow[SENT_LENGTH])
-
-	days := time.Duration(24) * (time.Hour)
-	years := time.Date(2012, 03, 04, 0, 0, 0, 0, time.UTC).Sub(time.Date(2011, 03, 04, 0, 0, 0, 0, time.UTC))
-	months := years / 12
+func getSentenceEndDate(rawRow []string, calendar Calendar) time.Time {
+	dispDate := parseDate(dateFormat, rawRow[STP_EVENT_DATE])
+	return calendar.AddSentencePart(dispDate, getSentencePart(rawRow))
+}
 
-	switch rawRow[SENT_TIME_CODE] {
-	case "D":
-		return time.Duration(sentenceLength) * days
-	case "M":
-		return time.Duration(sentenceLength) * months
-	case "Y":
-		return time.Duration(sentenceLength) * years
-	}
-	return time.Duration(0)
+func getSentencePart(rawRow []string) SentencePart {
+	sentenceLength, _ := strconv.Atoi(strings.TrimSpace(rawRow[SENT_LENGTH]))
+	return SentencePart{Length: sentenceLength, TimeCode: strings.TrimSpace(rawRow[SENT_TIME_CODE])}
 }
 
 func findCodeSection(rawRow []string) string {
@@ -90,14 +100,39 @@ func IsCodeSectionInComment(offenseDescription string) bool {
 	return trimmedOffenseDescription == "" || trimmedOffenseDescription == "SEE COMMENT FOR CHARGE"
 }
 
-func (row *DOJRow) OccurredInLast7Years() bool {
-	sevenYearsAgo := time.Now().AddDate(-7, 0, 0)
-
-	if row.DispositionDate.After(sevenYearsAgo) {
-		return true
-	} else {
-		return false
+func PossibleP64ChargeOnlyInComment(offenseDescription, commentText string) string {
+	if !IsCodeSectionInComment(offenseDescription) {
+		prop64InComment, commentCodeSection := matchers.ExtractProp64Section(commentText)
+		if prop64InComment {
+			prop64InOffenseDescription, offenseDescriptionCodeSection := matchers.ExtractProp64Section(offenseDescription)
+			if !prop64InOffenseDescription {
+				return commentText
+			} else if offenseDescriptionCodeSection != commentCodeSection {
+				return commentText
+			} else if offenseDescriptionCodeSection == "11357" {
+				offenseDescriptionHasSubSection, offenseDescriptionSubSection := matchers.Extract11357SubSection(offenseDescription)
+				commentTextHasSubSection, commentTextSubSection := matchers.Extract11357SubSection(commentText)
+				if offenseDescriptionHasSubSection && commentTextHasSubSection {
+					if (offenseDescriptionSubSection == "A" || offenseDescriptionSubSection == "B") &&
+						(commentTextSubSection == "C" || commentTextSubSection == "D") {
+						return commentText
+					} else if (offenseDescriptionSubSection == "C" || offenseDescriptionSubSection == "D") &&
+						(commentTextSubSection == "A" || commentTextSubSection == "B") {
+						return commentText
+					} else {
+						return ""
+					}
+				} else if commentTextHasSubSection {
+					return commentText
+				}
+			}
+		}
 	}
+	return ""
+}
+
+func (row *DOJRow) OccurredInLast7Years(calendar Calendar, comparisonTime time.Time) bool {
+	return calendar.Anniversary(row.DispositionDate, 7).After(comparisonTime)
 }
 
 const (
@@ -199,9 +234,9 @@ const (
 )
 
 func (row *DOJRow) wasConvictionUnderAgeOf21(subject *Subject) bool {
-	return subject.DOB.AddDate(21, 0, 0).After(row.DispositionDate)
+	return !subject.calendar.HasReachedAnniversary(subject.DOB, 21, row.DispositionDate)
 }
 
-func (row *DOJRow) convictionBefore(years int, comparisonTime time.Time) bool {
-	return !row.DispositionDate.After(comparisonTime.AddDate(-years, 0, 0))
+func (row *DOJRow) convictionBefore(calendar Calendar, years int, comparisonTime time.Time) bool {
+	return calendar.HasReachedAnniversary(row.DispositionDate, years, comparisonTime)
 }
@@ -21,7 +21,7 @@ var _ = Describe("DojRow", func() {
 	It("Sets values on initialization", func() {
 		expectedDob := time.Date(1960, time.March, 14, 0, 0, 0, 0, time.UTC)
 
-		row := NewDOJRow(rawRow, 1)
+		row := NewDOJRow(rawRow, 1, Calendar{})
 		Expect(row.Name).To(Equal("SKYWALKER,LUKE S"))
 		Expect(row.SubjectID).To(Equal("18675309"))
 		Expect(row.DOB).To(Equal(expectedDob))
@@ -37,13 +37,15 @@ var _ = Describe("DojRow", func() {
 		})
 
 		It("recognizes the registration", func() {
-			row := NewDOJRow(rawRow, 1)
+			row := NewDOJRow(rawRow, 1, Calendar{})
 
 			Expect(row.IsPC290Registration).To(BeTrue())
 		})
 	})
 
 	Describe("OccurredInLast7Years", func() {
+		comparisonTime := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+
 		Context("when the disposition date occurred in the last 7 years", func() {
 			BeforeEach(func() {
 				rawRow = []string{
@@ -51,62 +53,140 @@ var _ = Describe("DojRow", func() {
 				}
 			})
 			It("returns true", func() {
-				row := NewDOJRow(rawRow, 1)
+				row := NewDOJRow(rawRow, 1, Calendar{})
 
 				Expect(row.DispositionDate).To(Equal(time.Date(2016, time.May, 25, 0, 0, 0, 0, time.UTC)))
-				Expect(row.OccurredInLast7Years()).To(BeTrue())
+				Expect(row.OccurredInLast7Years(Calendar{}, comparisonTime)).To(BeTrue())
 			})
 		})
 
-		Context("when the disposition date occurred in the last 7 years", func() {
+		Context("when the disposition date occurred more than 7 years before the comparison time", func() {
 			BeforeEach(func() {
 				rawRow = []string{
 					"x", "x", "18675309", "#", "1008675309", "x", "x", "x", "x", "x", "#", "1008675309", "SKYWALKER,LUKE S", "x", "19600314", "123456789", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "x", "19790525", "x", "ARREST/DETAINED/CITED", "x", "x", "x", "CAPDSAN FRANCISCO", "x", "SAN FRANCISCO", "x", "x", "19790525", "12 140189-B", "x", "503 VC-TAKE CAR W/OUT OWNERS CONSENT", "F", "              ", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "REL/TOT OTHER JURIS/AUTH", "", "FELONY", "#", "", "", "", "", "", "                  ", "23", "", "", "", "#", "",
 				}
 			})
 			It("returns true", func() {
-				row := NewDOJRow(rawRow, 1)
+				row := NewDOJRow(rawRow, 1, Calendar{})
 
 				Expect(row.DispositionDate).To(Equal(time.Date(1979, time.May, 25, 0, 0, 0, 0, time.UTC)))
-				Expect(row.OccurredInLast7Years()).To(BeFalse())
+				Expect(row.OccurredInLast7Years(Calendar{}, comparisonTime)).To(BeFalse())
 			})
 		})
+
+		It("does not depend on the wall clock", func() {
+			row := NewDOJRow(rawRow, 1, Calendar{})
+			row.DispositionDate = time.Date(2016, time.May, 25, 0, 0, 0, 0, time.UTC)
+
+			Expect(row.OccurredInLast7Years(Calendar{}, time.Date(2023, time.May, 24, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+			Expect(row.OccurredInLast7Years(Calendar{}, time.Date(2023, time.May, 26, 0, 0, 0, 0, time.UTC))).To(BeFalse())
+		})
 	})
 
 	Describe("Determines the code section", func() {
 
 		It("detects the code section when it is explicitly specified in OFFENSE_DESCR", func() {
 			rawRow[OFFENSE_DESCR] = "503 VC-TAKE CAR W/OUT OWNERS CONSENT"
-			row := NewDOJRow(rawRow, 1)
+			row := NewDOJRow(rawRow, 1, Calendar{})
 			Expect(row.CodeSection).To(Equal("503 VC"))
 		})
 
 		It("detects the code section from COMMENT_TEXT when OFFENSE_DESCR reads 'SEE COMMENT FOR CHANGE'", func() {
 			rawRow[OFFENSE_DESCR] = "SEE COMMENT FOR CHARGE"
 			rawRow[COMMENT_TEXT] = "503 VC-TAKE CAR W/OUT OWNERS CONSENT"
-			row := NewDOJRow(rawRow, 1)
+			row := NewDOJRow(rawRow, 1, Calendar{})
 			Expect(row.CodeSection).To(Equal("503 VC"))
 		})
 
 		It("detects the code section from COMMENT_TEXT when OFFENSE_DESCR is empty", func() {
 			rawRow[OFFENSE_DESCR] = ""
 			rawRow[COMMENT_TEXT] = "503 VC-TAKE CAR W/OUT OWNERS CONSENT"
-			row := NewDOJRow(rawRow, 1)
+			row := NewDOJRow(rawRow, 1, Calendar{})
 			Expect(row.CodeSection).To(Equal("503 VC"))
 		})
 
 		It("detects the code section from COMMENT_TEXT when OFFENSE_DESCR is all blank", func() {
 			rawRow[OFFENSE_DESCR] = "   "
 			rawRow[COMMENT_TEXT] = "503 VC-TAKE CAR W/OUT OWNERS CONSENT"
-			row := NewDOJRow(rawRow, 1)
+			row := NewDOJRow(rawRow, 1, Calendar{})
 			Expect(row.CodeSection).To(Equal("503 VC"))
 		})
 
 		It("doesn't detect code section when COMMENT_TEXT and OFFENSE_DESCR are empty", func() {
 			rawRow[OFFENSE_DESCR] = ""
 			rawRow[COMMENT_TEXT] = ""
-			row := NewDOJRow(rawRow, 1)
+			row := NewDOJRow(rawRow, 1, Calendar{})
 			Expect(row.CodeSection).To(Equal(""))
 		})
 	})
+
+	Describe("Determines the county", func() {
+		It("uses the county for STP_ORI_CNTY_CODE when it is present", func() {
+			rawRow[STP_ORI_CNTY_CODE] = "34"
+			rawRow[STP_ORI_CNTY_NAME] = ""
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).County).To(Equal("SACRAMENTO"))
+		})
+
+		It("normalizes STP_ORI_CNTY_NAME when there is no code", func() {
+			rawRow[STP_ORI_CNTY_CODE] = ""
+			rawRow[STP_ORI_CNTY_NAME] = "San Francisco "
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).County).To(Equal("SAN FRANCISCO"))
+		})
+	})
+
+	Describe("Data quality flags", func() {
+		It("records when the code section was taken from the comment", func() {
+			rawRow[OFFENSE_DESCR] = "SEE COMMENT FOR CHARGE"
+			rawRow[COMMENT_TEXT] = "11357 HS-POSSESS MARIJUANA"
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).CodeSectionInComment).To(BeTrue())
+
+			rawRow[OFFENSE_DESCR] = "11357 HS-POSSESS MARIJUANA"
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).CodeSectionInComment).To(BeFalse())
+		})
+
+		It("records a possible Prop 64 charge that only appears in the comment", func() {
+			rawRow[OFFENSE_DESCR] = "11350 HS-POSSESS NARCOTIC CONTROLLED SUBSTANCE"
+			rawRow[COMMENT_TEXT] = "11357(A)"
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).PossibleP64ChargeInComment).To(Equal("11357(A)"))
+		})
+
+		It("records an unknown felony status when neither CONV_STAT_DESCR nor OFFENSE_TOC say what the conviction was", func() {
+			rawRow[CONV_STAT_DESCR] = ""
+			rawRow[OFFENSE_TOC] = ""
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).FelonyStatusUnknown).To(BeTrue())
+
+			rawRow[OFFENSE_TOC] = "M"
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).FelonyStatusUnknown).To(BeFalse())
+
+			rawRow[CONV_STAT_DESCR] = "FELONY"
+			rawRow[OFFENSE_TOC] = ""
+			Expect(NewDOJRow(rawRow, 1, Calendar{}).FelonyStatusUnknown).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("PossibleP64ChargeOnlyInComment", func() {
+	It("returns the comment text if the comment text has a Prop64 charge and the offense description doesn't", func() {
+		Expect(PossibleP64ChargeOnlyInComment("912", "11357(A)")).To(Equal("11357(A)"))
+		Expect(PossibleP64ChargeOnlyInComment("11350", "11357(A)")).To(Equal("11357(A)"))
+	})
+
+	It("returns empty string if the comment text is expected to override the offense description", func() {
+		Expect(PossibleP64ChargeOnlyInComment("", "11357(A)")).To(Equal(""))
+		Expect(PossibleP64ChargeOnlyInComment("SEE COMMENT FOR CHARGE", "11357(A)")).To(Equal(""))
+	})
+	It("returns the comment text if offense description and comment text have different Prop64 charges that are not both 11357 subsections", func() {
+		Expect(PossibleP64ChargeOnlyInComment("11358", "11359")).To(Equal("11359"))
+		Expect(PossibleP64ChargeOnlyInComment("11357(A)", "11358")).To(Equal("11358"))
+		Expect(PossibleP64ChargeOnlyInComment("11358", "11357(C)")).To(Equal("11357(C)"))
+	})
+	It("returns the comment text if offense description and comment text have different 11357 subsections, one from the (A,B) group and the other from the (C,D) group", func() {
+		Expect(PossibleP64ChargeOnlyInComment("11357(A)", "11357(C)")).To(Equal("11357(C)"))
+		Expect(PossibleP64ChargeOnlyInComment("11357(D)", "11357(B)")).To(Equal("11357(B)"))
+	})
+	It("returns the empty string if offense description and comment text have different 11357 subsections, but from the same group (A,B) or (C,D)", func() {
+		Expect(PossibleP64ChargeOnlyInComment("11357(A)", "11357(B)")).To(Equal(""))
+		Expect(PossibleP64ChargeOnlyInComment("11357(D)", "11357(C)")).To(Equal(""))
+	})
+
 })
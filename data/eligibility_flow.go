@@ -17,6 +17,7 @@ var EligibilityFlows = map[string]EligibilityFlow{
 type EligibilityOptions struct {
 	BaselineEligibility BaselineEligibility `json:"baselineEligibility"`
 	AdditionalRelief    AdditionalRelief    `json:"additionalRelief"`
+	HandReview          HandReviewTriggers  `json:"handReview"`
 }
 
 type BaselineEligibility struct {
@@ -25,10 +26,23 @@ type BaselineEligibility struct {
 }
 
 type AdditionalRelief struct {
-	SubjectUnder21AtConviction    bool `json:"subjectUnder21AtConviction"`
-	SubjectHasOnlyProp64Charges   bool `json:"subjectHasOnlyProp64Charges"`
-	SubjectIsDeceased             bool `json:"subjectIsDeceased"`
-	SubjectAgeThreshold           int  `json:"subjectAgeThreshold"`
-	YearsSinceConvictionThreshold int  `json:"yearsSinceConvictionThreshold"`
-	YearsCrimeFreeThreshold       int  `json:"yearsCrimeFreeThreshold"`
+	SubjectUnder21AtConviction          bool `json:"subjectUnder21AtConviction"`
+	SubjectCurrentlyUnder21             bool `json:"subjectCurrentlyUnder21"`
+	SubjectOlderThanFifty               bool `json:"subjectOlderThanFifty"`
+	SubjectHasOnlyProp64Charges         bool `json:"subjectHasOnlyProp64Charges"`
+	SubjectIsDeceased                   bool `json:"subjectIsDeceased"`
+	SubjectHasNoConvictionsPastTenYears bool `json:"subjectHasNoConvictionsPastTenYears"`
+	SubjectAgeThreshold                 int  `json:"subjectAgeThreshold"`
+	YearsSinceConvictionThreshold       int  `json:"yearsSinceConvictionThreshold"`
+	YearsCrimeFreeThreshold             int  `json:"yearsCrimeFreeThreshold"`
+	HandReviewTwoPriorsOfSameSection    bool `json:"handReviewTwoPriorsOfSameSection"`
+	RequireAllSentencesCompleted        bool `json:"requireAllSentencesCompleted"`
+}
+
+type HandReviewTriggers struct {
+	CodeSectionOnlyInComment      bool `json:"codeSectionOnlyInComment"`
+	PossibleProp64ChargeInComment bool `json:"possibleProp64ChargeInComment"`
+	MissingDateOfBirth            bool `json:"missingDateOfBirth"`
+	MissingDispositionDate        bool `json:"missingDispositionDate"`
+	UnknownFelonyStatus           bool `json:"unknownFelonyStatus"`
 }
@@ -17,6 +17,7 @@ type EligibilityInfo struct {
 	NumberOf11359Convictions       int
 	NumberOf11360Convictions       int
 	comparisonTime                 time.Time
+	calendar                       Calendar
 	OccurredAfterEffectiveDate     string
 	Superstrikes                   string
 	PC290CodeSections              string
@@ -25,11 +26,14 @@ type EligibilityInfo struct {
 	EligibilityReason              string
 	CaseNumber                     string
 	Deceased                       string
+	CourtDocketNumber              string
+	CourtMatchConfidence           float64
 }
 
 func NewEligibilityInfo(row *DOJRow, subject *Subject, comparisonTime time.Time, county string) *EligibilityInfo {
 	info := new(EligibilityInfo)
 	info.comparisonTime = comparisonTime
+	info.calendar = subject.calendar
 
 	if (row.DispositionDate == time.Time{}) {
 		info.YearsSinceThisConviction = -1.0
@@ -77,15 +81,13 @@ func NewEligibilityInfo(row *DOJRow, subject *Subject, comparisonTime time.Time,
 	info.NumberOfConvictionsOnRecord = len(subject.Convictions)
 	info.NumberOfProp64Convictions, info.NumberOf11357Convictions, info.NumberOf11358Convictions, info.NumberOf11359Convictions, info.NumberOf11360Convictions = subject.Prop64ConvictionsBySection()
 	info.DateOfConviction = row.DispositionDate
-	info.CaseNumber = strings.Join(subject.CaseNumbers[row.CountOrder[0:6]], "; ")
+	info.CaseNumber = strings.Join(subject.CaseNumbersFor(row), "; ")
 
 	return info
 }
 
 func (info *EligibilityInfo) yearsSinceEvent(date time.Time) float64 {
-	hours := info.comparisonTime.Sub(date).Hours()
-	years := hours / (24 * 365.25)
-	return years
+	return info.calendar.YearsBetween(date, info.comparisonTime)
 }
 
 func (info *EligibilityInfo) hasSuperstrikes() bool {
@@ -93,36 +95,28 @@ func (info *EligibilityInfo) hasSuperstrikes() bool {
 }
 
 func (info *EligibilityInfo) hasTwoPriors(row *DOJRow, subject *Subject) bool {
-	priorConvictionsOfSameCodeSectionPrefix := 0
-	codeSectionRune := []rune(row.CodeSection)
-	codeSectionPrefix := string(codeSectionRune[0:5])
+	priorConvictionsOfSameCodeSection := 0
+	matched, codeSection := matchers.ExtractProp64Section(row.CodeSection)
+	if !matched {
+		return false
+	}
 	for _, conviction := range subject.Convictions {
-		if matchers.IsProp64Charge(conviction.CodeSection) {
+		if _, convictionCodeSection := matchers.ExtractProp64Section(conviction.CodeSection); convictionCodeSection == codeSection {
 			if conviction.DispositionDate.Before(row.DispositionDate) {
-				if strings.HasPrefix(conviction.CodeSection, codeSectionPrefix) {
-					priorConvictionsOfSameCodeSectionPrefix++
-				}
+				priorConvictionsOfSameCodeSection++
 			}
 		}
 	}
 
-	return priorConvictionsOfSameCodeSectionPrefix >= 2
+	return priorConvictionsOfSameCodeSection >= 2
 }
 
 func (info *EligibilityInfo) olderThanFifty(row *DOJRow, subject *Subject) bool {
-	age := info.yearsSinceEvent(subject.DOB)
-	if age >= 50 {
-		return true
-	}
-	return false
+	return info.calendar.HasReachedAnniversary(subject.DOB, 50, info.comparisonTime)
 }
 
 func (info *EligibilityInfo) youngerThanTwentyOne(row *DOJRow, subject *Subject) bool {
-	age := info.yearsSinceEvent(subject.DOB)
-	if age <= 21 {
-		return true
-	}
-	return false
+	return !info.calendar.Anniversary(subject.DOB, 21).Before(info.comparisonTime)
 }
 
 func (info *EligibilityInfo) onlyProp64Convictions(row *DOJRow, subject *Subject) bool {
@@ -140,7 +134,7 @@ func (info *EligibilityInfo) allSentencesCompleted(row *DOJRow, subject *Subject
 
 func (info *EligibilityInfo) noConvictionsPastTenYears(row *DOJRow, subject *Subject) bool {
 	for _, conviction := range subject.Convictions {
-		if conviction.DispositionDate.After(info.comparisonTime.AddDate(-10, 0, 0)) {
+		if info.calendar.Anniversary(conviction.DispositionDate, 10).After(info.comparisonTime) {
 			return false
 		}
 	}
@@ -171,3 +165,8 @@ func (info *EligibilityInfo) SetHandReview(reason string) {
 	info.EligibilityDetermination = "Hand Review"
 	info.EligibilityReason = strings.TrimSpace(reason)
 }
+
+func (info *EligibilityInfo) SetPreviouslyProcessed(reason string) {
+	info.EligibilityDetermination = "Previously Processed"
+	info.EligibilityReason = strings.TrimSpace(reason)
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type ExcludeList struct {
+	reasonsBySubjectID  map[string]string
+	reasonsByCII        map[string]string
+	reasonsByCaseNumber map[string]string
+}
+
+func newExcludeList() ExcludeList {
+	return ExcludeList{
+		reasonsBySubjectID:  make(map[string]string),
+		reasonsByCII:        make(map[string]string),
+		reasonsByCaseNumber: make(map[string]string),
+	}
+}
+
+func ReadExcludeList(excludeListPath string) (ExcludeList, error) {
+	excludeList := newExcludeList()
+
+	excludeListFile, err := os.Open(excludeListPath)
+	if err != nil {
+		return excludeList, err
+	}
+	defer excludeListFile.Close()
+
+	reader := csv.NewReader(excludeListFile)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return excludeList, err
+	}
+	if len(rows) == 0 {
+		return excludeList, fmt.Errorf("exclude list %s is empty", excludeListPath)
+	}
+
+	columns := make(map[string]int)
+	for index, header := range rows[0] {
+		header = strings.ToUpper(strings.TrimSpace(header))
+		switch header {
+		case "CII":
+			header = "CII_NUMBER"
+		case "OFN":
+			header = "CASE_NUMBER"
+		}
+		switch header {
+		case "SUBJECT_ID", "CII_NUMBER", "CASE_NUMBER", "REASON":
+			columns[header] = index
+		default:
+			return excludeList, fmt.Errorf("unknown column %q in exclude list: expected SUBJECT_ID, CII_NUMBER, CASE_NUMBER or REASON", rows[0][index])
+		}
+	}
+	if !hasColumn(columns, "SUBJECT_ID") && !hasColumn(columns, "CII_NUMBER") && !hasColumn(columns, "CASE_NUMBER") {
+		return excludeList, fmt.Errorf("exclude list %s needs a SUBJECT_ID, CII_NUMBER or CASE_NUMBER column", excludeListPath)
+	}
+
+	for _, row := range rows[1:] {
+		value := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		reason := value("REASON")
+		if reason == "" {
+			reason = "Listed in exclude list"
+		}
+		if subjectID := value("SUBJECT_ID"); subjectID != "" {
+			excludeList.reasonsBySubjectID[subjectID] = reason
+		}
+		if cii := value("CII_NUMBER"); cii != "" {
+			excludeList.reasonsByCII[cii] = reason
+		}
+		if caseNumber := value("CASE_NUMBER"); caseNumber != "" {
+			excludeList.reasonsByCaseNumber[normalizeCaseNumber(caseNumber)] = reason
+		}
+	}
+	return excludeList, nil
+}
+
+func (e ExcludeList) Reason(subject *Subject, conviction *DOJRow) (string, bool) {
+	for _, subjectID := range []string{conviction.SubjectID, subject.ID} {
+		if reason, ok := e.reasonsBySubjectID[subjectID]; ok && subjectID != "" {
+			return reason, true
+		}
+	}
+	for _, cii := range []string{conviction.CII, subject.CII} {
+		if reason, ok := e.reasonsByCII[cii]; ok && cii != "" {
+			return reason, true
+		}
+	}
+	for _, caseNumber := range append([]string{conviction.OFN}, subject.CaseNumbersFor(conviction)...) {
+		if reason, ok := e.reasonsByCaseNumber[normalizeCaseNumber(caseNumber)]; ok && caseNumber != "" {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+func (i *DOJInformation) ApplyExcludeList(eligibilities map[int]*EligibilityInfo, excludeList ExcludeList) {
+	for _, subject := range i.Subjects {
+		for _, conviction := range subject.Convictions {
+			info := eligibilities[conviction.Index]
+			if info == nil {
+				continue
+			}
+			if reason, ok := excludeList.Reason(subject, conviction); ok {
+				info.SetPreviouslyProcessed(reason)
+			}
+		}
+	}
+}
+
+func normalizeCaseNumber(caseNumber string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(caseNumber), ""))
+}
+
+func hasColumn(columns map[string]int, column string) bool {
+	_, ok := columns[column]
+	return ok
+}
@@ -0,0 +1,88 @@
+package data_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen/data"
+)
+
+var _ = Describe("ExcludeList", func() {
+	var (
+		excludeListPath string
+		dojInformation  *DOJInformation
+		eligibilities   map[int]*EligibilityInfo
+	)
+	birthDate := time.Date(1960, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+	writeExcludeList := func(contents string) {
+		outputDir, err := ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		excludeListPath = path.Join(outputDir, "exclude_list.csv")
+		Expect(ioutil.WriteFile(excludeListPath, []byte(contents), os.ModePerm)).To(Succeed())
+	}
+
+	pushRow := func(row DOJRow) {
+		if dojInformation.Subjects[row.SubjectID] == nil {
+			dojInformation.Subjects[row.SubjectID] = new(Subject)
+		}
+		dojInformation.Subjects[row.SubjectID].PushRow(row, nil)
+	}
+
+	BeforeEach(func() {
+		dojInformation = &DOJInformation{Subjects: make(map[string]*Subject)}
+		pushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", WasConvicted: true, CodeSection: "11357 HS", CountOrder: "101001001000", Index: 0})
+		pushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", Type: "COURT ACTION", OFN: "CR 1234", CountOrder: "101001001000", Index: 1})
+		pushRow(DOJRow{SubjectID: "200", Name: "ORGANA,LEIA", DOB: birthDate, CII: "A222", WasConvicted: true, CodeSection: "11358 HS", CountOrder: "101001001000", Index: 2})
+		pushRow(DOJRow{SubjectID: "300", Name: "SOLO,HAN", DOB: birthDate, CII: "A333", WasConvicted: true, CodeSection: "11359 HS", CountOrder: "101001001000", Index: 3})
+
+		eligibilities = map[int]*EligibilityInfo{0: {}, 2: {}, 3: {}}
+		for _, info := range eligibilities {
+			info.SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+		}
+	})
+
+	It("marks convictions listed by SUBJECT_ID, CII_NUMBER or CASE_NUMBER as previously processed", func() {
+		writeExcludeList("CASE_NUMBER,CII_NUMBER,SUBJECT_ID,REASON\ncr1234,,,Petition filed\n,A222,,\n")
+
+		excludeList, err := ReadExcludeList(excludeListPath)
+		Expect(err).ToNot(HaveOccurred())
+		dojInformation.ApplyExcludeList(eligibilities, excludeList)
+
+		Expect(eligibilities[0].EligibilityDetermination).To(Equal("Previously Processed"))
+		Expect(eligibilities[0].EligibilityReason).To(Equal("Petition filed"))
+		Expect(eligibilities[2].EligibilityDetermination).To(Equal("Previously Processed"))
+		Expect(eligibilities[2].EligibilityReason).To(Equal("Listed in exclude list"))
+		Expect(eligibilities[3].EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+	})
+
+	It("accepts a list with only some of the columns", func() {
+		writeExcludeList("SUBJECT_ID\n300\n")
+
+		excludeList, err := ReadExcludeList(excludeListPath)
+		Expect(err).ToNot(HaveOccurred())
+		dojInformation.ApplyExcludeList(eligibilities, excludeList)
+
+		Expect(eligibilities[0].EligibilityDetermination).To(Equal("Eligible for Dismissal"))
+		Expect(eligibilities[3].EligibilityDetermination).To(Equal("Previously Processed"))
+	})
+
+	It("returns an error for unknown columns", func() {
+		writeExcludeList("SUBJECT_ID,DOCKET\n300,CR1\n")
+
+		_, err := ReadExcludeList(excludeListPath)
+		Expect(err).To(MatchError(`unknown column "DOCKET" in exclude list: expected SUBJECT_ID, CII_NUMBER, CASE_NUMBER or REASON`))
+	})
+
+	It("returns an error when there is no identifying column", func() {
+		writeExcludeList("REASON\nPetition filed\n")
+
+		_, err := ReadExcludeList(excludeListPath)
+		Expect(err).To(MatchError(HaveSuffix("needs a SUBJECT_ID, CII_NUMBER or CASE_NUMBER column")))
+	})
+})
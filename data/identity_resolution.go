@@ -0,0 +1,200 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type IdentityStrictness string
+
+const (
+	IdentityResolutionOff      IdentityStrictness = "OFF"
+	IdentityResolutionStrict   IdentityStrictness = "STRICT"
+	IdentityResolutionStandard IdentityStrictness = "STANDARD"
+	IdentityResolutionLoose    IdentityStrictness = "LOOSE"
+)
+
+const DefaultNameSimilarityThreshold = 0.85
+
+type IdentityResolutionOptions struct {
+	Strictness              IdentityStrictness
+	NameSimilarityThreshold float64
+}
+
+type IdentityLink struct {
+	MergedSubjectID string
+	Subject         *Subject
+	LinkedSubject   *Subject
+	MatchedOn       string
+	NameSimilarity  float64
+}
+
+func NewIdentityResolutionOptions(strictness string, nameSimilarityThreshold float64) (IdentityResolutionOptions, error) {
+	options := IdentityResolutionOptions{
+		Strictness:              IdentityStrictness(strings.ToUpper(strictness)),
+		NameSimilarityThreshold: nameSimilarityThreshold,
+	}
+	switch options.Strictness {
+	case IdentityResolutionOff, IdentityResolutionStrict, IdentityResolutionStandard, IdentityResolutionLoose:
+	default:
+		return IdentityResolutionOptions{}, fmt.Errorf("identity resolution should be one of OFF, STRICT, STANDARD or LOOSE, got %q", strictness)
+	}
+	if nameSimilarityThreshold <= 0 || nameSimilarityThreshold > 1 {
+		return IdentityResolutionOptions{}, fmt.Errorf("name similarity threshold should be greater than 0 and at most 1, got %v", nameSimilarityThreshold)
+	}
+	return options, nil
+}
+
+func (i *DOJInformation) ResolveIdentities(options IdentityResolutionOptions) []IdentityLink {
+	if options.Strictness == IdentityResolutionOff || options.Strictness == "" {
+		return nil
+	}
+
+	var subjectIDs []string
+	for id := range i.Subjects {
+		subjectIDs = append(subjectIDs, id)
+	}
+	sort.Strings(subjectIDs)
+
+	parents := make(map[string]string)
+	for _, id := range subjectIDs {
+		parents[id] = id
+	}
+	var find func(id string) string
+	find = func(id string) string {
+		if parents[id] != id {
+			parents[id] = find(parents[id])
+		}
+		return parents[id]
+	}
+
+	var links []IdentityLink
+	link := func(id string, linkedID string, matchedOn string, similarity float64) {
+		root, linkedRoot := find(id), find(linkedID)
+		if root == linkedRoot {
+			return
+		}
+		if linkedRoot < root {
+			root, linkedRoot = linkedRoot, root
+		}
+		parents[linkedRoot] = root
+		links = append(links, IdentityLink{
+			Subject:        i.Subjects[id],
+			LinkedSubject:  i.Subjects[linkedID],
+			MatchedOn:      matchedOn,
+			NameSimilarity: similarity,
+		})
+	}
+
+	linkByKey := func(matchedOn string, key func(subject *Subject) string) {
+		firstByKey := make(map[string]string)
+		for _, id := range subjectIDs {
+			value := key(i.Subjects[id])
+			if value == "" {
+				continue
+			}
+			if first, ok := firstByKey[value]; ok {
+				link(first, id, matchedOn, nameSimilarity(i.Subjects[first].Name, i.Subjects[id].Name))
+			} else {
+				firstByKey[value] = id
+			}
+		}
+	}
+
+	linkByKey("CII_NUMBER", func(subject *Subject) string { return subject.CII })
+	if options.Strictness != IdentityResolutionStrict {
+		linkByKey("FBI_NUMBER", func(subject *Subject) string { return subject.FBINumber })
+	}
+	if options.Strictness == IdentityResolutionLoose {
+		idsByDOB := make(map[string][]string)
+		for _, id := range subjectIDs {
+			if dob := i.Subjects[id].DOB; !dob.IsZero() {
+				idsByDOB[dob.Format(dateFormat)] = append(idsByDOB[dob.Format(dateFormat)], id)
+			}
+		}
+		for _, id := range subjectIDs {
+			if i.Subjects[id].DOB.IsZero() {
+				continue
+			}
+			for _, candidateID := range idsByDOB[i.Subjects[id].DOB.Format(dateFormat)] {
+				if candidateID <= id {
+					continue
+				}
+				similarity := nameSimilarity(i.Subjects[id].Name, i.Subjects[candidateID].Name)
+				if similarity >= options.NameSimilarityThreshold {
+					link(id, candidateID, "NAME_DOB", similarity)
+				}
+			}
+		}
+	}
+
+	for _, id := range subjectIDs {
+		root := find(id)
+		if root != id {
+			i.Subjects[root].Merge(i.Subjects[id])
+		}
+	}
+	for index := range links {
+		links[index].MergedSubjectID = find(links[index].Subject.ID)
+	}
+	for _, id := range subjectIDs {
+		if find(id) != id {
+			delete(i.Subjects, id)
+		}
+	}
+
+	return links
+}
+
+func normalizeName(name string) string {
+	parts := strings.SplitN(strings.ToUpper(name), ",", 2)
+	lastName := strings.Join(strings.Fields(parts[0]), " ")
+	if len(parts) < 2 {
+		return lastName
+	}
+	givenNames := strings.Fields(parts[1])
+	if len(givenNames) == 0 {
+		return lastName
+	}
+	return lastName + " " + givenNames[0]
+}
+
+func nameSimilarity(name1 string, name2 string) float64 {
+	a, b := []rune(normalizeName(name1)), []rune(normalizeName(name2))
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
+
+func editDistance(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,96 @@
+package data_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "gogen/data"
+)
+
+var _ = Describe("ResolveIdentities", func() {
+	var dojInformation *DOJInformation
+	birthDate := time.Date(1980, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	pushRow := func(row DOJRow) {
+		if dojInformation.Subjects[row.SubjectID] == nil {
+			dojInformation.Subjects[row.SubjectID] = new(Subject)
+		}
+		dojInformation.Subjects[row.SubjectID].PushRow(row, nil)
+	}
+
+	BeforeEach(func() {
+		dojInformation = &DOJInformation{Subjects: make(map[string]*Subject)}
+
+		pushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE S", DOB: birthDate, CII: "A111", WasConvicted: true, CodeSection: "11357 HS", CountOrder: "101001001000", Index: 0})
+		pushRow(DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE S", DOB: birthDate, CII: "A111", Type: "COURT ACTION", OFN: "CASE-1", CountOrder: "101001001000", Index: 1})
+		pushRow(DOJRow{SubjectID: "200", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", WasConvicted: true, CodeSection: "11359 HS", CountOrder: "101001001000", Index: 2})
+		pushRow(DOJRow{SubjectID: "200", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", Type: "COURT ACTION", OFN: "CASE-2", CountOrder: "101001001000", Index: 3})
+		pushRow(DOJRow{SubjectID: "300", Name: "SKYWALKR,LUKE", DOB: birthDate, FBINumber: "F999", WasConvicted: true, CodeSection: "187 PC", CountOrder: "101001001000", Index: 4})
+		pushRow(DOJRow{SubjectID: "400", Name: "SKYWALKER,ANAKIN", DOB: birthDate, FBINumber: "F999", WasConvicted: true, CodeSection: "11360 HS", CountOrder: "101001001000", Index: 5})
+		pushRow(DOJRow{SubjectID: "500", Name: "ORGANA,LEIA", DOB: birthDate, WasConvicted: true, CodeSection: "11358 HS", CountOrder: "101001001000", Index: 6})
+	})
+
+	It("does nothing when identity resolution is off", func() {
+		links := dojInformation.ResolveIdentities(IdentityResolutionOptions{Strictness: IdentityResolutionOff})
+		Expect(links).To(BeEmpty())
+		Expect(dojInformation.Subjects).To(HaveLen(5))
+	})
+
+	It("merges subjects that share a CII_NUMBER when strict", func() {
+		links := dojInformation.ResolveIdentities(IdentityResolutionOptions{Strictness: IdentityResolutionStrict, NameSimilarityThreshold: DefaultNameSimilarityThreshold})
+
+		Expect(dojInformation.Subjects).To(HaveLen(4))
+		merged := dojInformation.Subjects["100"]
+		Expect(merged.LinkedSubjectIDs).To(Equal([]string{"200"}))
+		Expect(merged.Convictions).To(HaveLen(2))
+		Expect(merged.CaseNumbersFor(merged.Convictions[0])).To(Equal([]string{"CASE-1"}))
+		Expect(merged.CaseNumbersFor(merged.Convictions[1])).To(Equal([]string{"CASE-2"}))
+
+		Expect(links).To(HaveLen(1))
+		Expect(links[0].MergedSubjectID).To(Equal("100"))
+		Expect(links[0].Subject.ID).To(Equal("100"))
+		Expect(links[0].LinkedSubject.ID).To(Equal("200"))
+		Expect(links[0].MatchedOn).To(Equal("CII_NUMBER"))
+	})
+
+	It("also merges subjects that share an FBI_NUMBER when standard", func() {
+		links := dojInformation.ResolveIdentities(IdentityResolutionOptions{Strictness: IdentityResolutionStandard, NameSimilarityThreshold: DefaultNameSimilarityThreshold})
+
+		Expect(dojInformation.Subjects).To(HaveLen(3))
+		Expect(dojInformation.Subjects["300"].LinkedSubjectIDs).To(Equal([]string{"400"}))
+		Expect(links).To(HaveLen(2))
+		Expect(links[1].MatchedOn).To(Equal("FBI_NUMBER"))
+	})
+
+	It("also merges subjects with the same DOB and a similar name when loose", func() {
+		links := dojInformation.ResolveIdentities(IdentityResolutionOptions{Strictness: IdentityResolutionLoose, NameSimilarityThreshold: DefaultNameSimilarityThreshold})
+
+		Expect(dojInformation.Subjects).To(HaveLen(2))
+		Expect(dojInformation.Subjects["100"].Convictions).To(HaveLen(4))
+		Expect(dojInformation.Subjects["500"]).ToNot(BeNil())
+
+		Expect(links).To(HaveLen(3))
+		Expect(links[2].MatchedOn).To(Equal("NAME_DOB"))
+		Expect(links[2].NameSimilarity).To(BeNumerically(">=", DefaultNameSimilarityThreshold))
+		for _, link := range links {
+			Expect(link.MergedSubjectID).To(Equal("100"))
+		}
+	})
+
+	It("does not merge names below the similarity threshold", func() {
+		dojInformation.ResolveIdentities(IdentityResolutionOptions{Strictness: IdentityResolutionLoose, NameSimilarityThreshold: 0.99})
+		Expect(dojInformation.Subjects).To(HaveLen(3))
+	})
+
+	It("validates options", func() {
+		_, err := NewIdentityResolutionOptions("sometimes", DefaultNameSimilarityThreshold)
+		Expect(err).To(HaveOccurred())
+		_, err = NewIdentityResolutionOptions("LOOSE", 1.5)
+		Expect(err).To(HaveOccurred())
+		options, err := NewIdentityResolutionOptions("loose", 0.9)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(options.Strictness).To(Equal(IdentityResolutionLoose))
+	})
+})
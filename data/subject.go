@@ -2,6 +2,7 @@ package data
 
 import (
 	"gogen/matchers"
+	"sort"
 	"time"
 )
 
@@ -9,12 +10,18 @@ type Subject struct {
 	ID                      string
 	Name                    string
 	DOB                     time.Time
+	Race                    string
+	Gender                  string
+	CII                     string
+	FBINumber               string
+	LinkedSubjectIDs        []string
 	Convictions             []*DOJRow
 	seenConvictions         map[string]bool
 	PC290Registration       bool
 	CyclesWithProp64Charges map[string]bool
 	CaseNumbers             map[string][]string
 	IsDeceased              bool
+	calendar                Calendar
 }
 
 func (subject *Subject) PushRow(row DOJRow, eligibilityFlow EligibilityFlow) {
@@ -28,16 +35,29 @@ func (subject *Subject) PushRow(row DOJRow, eligibilityFlow EligibilityFlow) {
 	}
 	if row.WasConvicted && subject.seenConvictions[row.CountOrder] {
 		lastConviction := subject.Convictions[len(subject.Convictions)-1]
-		newEndDate := lastConviction.SentenceEndDate.Add(row.SentencePartDuration)
+		newEndDate := subject.calendar.AddSentencePart(lastConviction.SentenceEndDate, row.SentencePart)
 		lastConviction.SentenceEndDate = newEndDate
 	}
 
+	if subject.CII == "" {
+		subject.CII = row.CII
+	}
+	if subject.Race == "" {
+		subject.Race = row.Race
+	}
+	if subject.Gender == "" {
+		subject.Gender = row.Gender
+	}
+	if subject.FBINumber == "" {
+		subject.FBINumber = row.FBINumber
+	}
+
 	if row.Type == "DECEASED" {
 		subject.IsDeceased = true
 	}
 
 	if row.Type == "COURT ACTION" && row.OFN != "" {
-		subject.CaseNumbers[row.CountOrder[0:6]] = setAppend(subject.CaseNumbers[row.CountOrder[0:6]], row.OFN)
+		subject.CaseNumbers[caseNumberKey(&row)] = setAppend(subject.CaseNumbers[caseNumberKey(&row)], row.OFN)
 	}
 	if row.IsPC290Registration {
 		subject.PC290Registration = true
@@ -129,11 +149,11 @@ func (subject *Subject) NumberOfFelonies() int {
 	return felonies
 }
 
-func (subject *Subject) NumberOfConvictionsInLast7Years() int {
+func (subject *Subject) NumberOfConvictionsInLast7Years(comparisonTime time.Time) int {
 	convictionsInRange := 0
 
 	for _, conviction := range subject.Convictions {
-		if conviction.OccurredInLast7Years() {
+		if conviction.OccurredInLast7Years(subject.calendar, comparisonTime) {
 			convictionsInRange++
 		}
 	}
@@ -141,6 +161,72 @@ func (subject *Subject) NumberOfConvictionsInLast7Years() int {
 	return convictionsInRange
 }
 
+func (subject *Subject) NoLongerHasFelony(eligibilities map[int]*EligibilityInfo) bool {
+	return subject.hasFullRelief(eligibilities, IsFelonyFilter, reducedOrDismissedFilter)
+}
+
+func (subject *Subject) NoLongerHasConviction(eligibilities map[int]*EligibilityInfo) bool {
+	return subject.hasFullRelief(eligibilities, hasConvictionFilter, dismissedFilter)
+}
+
+func (subject *Subject) hasFullRelief(
+	eligibilities map[int]*EligibilityInfo,
+	convictionFilter func(conviction *DOJRow) bool,
+	reliefFilter func(eligibility *EligibilityInfo) bool) bool {
+	countConvictions := 0
+	countRelief := 0
+	for _, conviction := range subject.Convictions {
+		if convictionFilter(conviction) {
+			countConvictions++
+			if eligibilities[conviction.Index] != nil {
+				if reliefFilter(eligibilities[conviction.Index]) {
+					countRelief++
+				}
+			}
+		}
+	}
+	return countConvictions != 0 && (countConvictions == countRelief)
+}
+
+func (subject *Subject) CaseNumbersFor(row *DOJRow) []string {
+	return subject.CaseNumbers[caseNumberKey(row)]
+}
+
+func caseNumberKey(row *DOJRow) string {
+	return row.SubjectID + ":" + row.CountOrder[0:6]
+}
+
+func (subject *Subject) Merge(other *Subject) {
+	subject.Convictions = append(subject.Convictions, other.Convictions...)
+	sort.Slice(subject.Convictions, func(a, b int) bool {
+		return subject.Convictions[a].Index < subject.Convictions[b].Index
+	})
+	for key, caseNumbers := range other.CaseNumbers {
+		for _, caseNumber := range caseNumbers {
+			subject.CaseNumbers[key] = setAppend(subject.CaseNumbers[key], caseNumber)
+		}
+	}
+	if subject.DOB.IsZero() {
+		subject.DOB = other.DOB
+	}
+	if subject.CII == "" {
+		subject.CII = other.CII
+	}
+	if subject.Race == "" {
+		subject.Race = other.Race
+	}
+	if subject.Gender == "" {
+		subject.Gender = other.Gender
+	}
+	if subject.FBINumber == "" {
+		subject.FBINumber = other.FBINumber
+	}
+	subject.PC290Registration = subject.PC290Registration || other.PC290Registration
+	subject.IsDeceased = subject.IsDeceased || other.IsDeceased
+	subject.LinkedSubjectIDs = append(subject.LinkedSubjectIDs, other.ID)
+	subject.LinkedSubjectIDs = append(subject.LinkedSubjectIDs, other.LinkedSubjectIDs...)
+}
+
 func setAppend(arr []string, item string) []string {
 	for _, el := range arr {
 		if el == item {
@@ -162,5 +248,5 @@ func (subject *Subject) computeEligibilities(infos map[int]*EligibilityInfo, com
 }
 
 func (subject *Subject) olderThan(years int, t time.Time) bool {
-	return !subject.DOB.AddDate(years, 0, 0).After(t)
+	return subject.calendar.HasReachedAnniversary(subject.DOB, years, t)
 }
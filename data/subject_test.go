@@ -23,7 +23,6 @@ var _ = Describe("Subject", func() {
 		birthDate         time.Time
 	)
 
-	days := time.Duration(24) * (time.Hour)
 	sacramentoEligibilityFlow := data.EligibilityFlows["SACRAMENTO"]
 
 	BeforeEach(func() {
@@ -34,7 +33,7 @@ var _ = Describe("Subject", func() {
 		conviction3 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1118888", DOB: birthDate, CodeSection: "187 PC", WasConvicted: true, CountOrder: "103001004000", DispositionDate: time.Date(2001, time.May, 4, 0, 0, 0, 0, time.UTC), County: "LOS ANGELES"}
 		conviction4 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 12345678-00", DOB: birthDate, CodeSection: "11360 HS", WasConvicted: true,CountOrder: "104001005000", DispositionDate: time.Date(2011, time.May, 12, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO"}
 		conviction5 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "266J PC", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", SentenceEndDate: time.Date(2012, 03, 04, 0, 0, 0, 0, time.UTC)}
-		conviction5Prison = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "11360 HS", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", SentencePartDuration: time.Duration(30 * days)}
+		conviction5Prison = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 334455-00", DOB: birthDate, CodeSection: "11360 HS", WasConvicted: true, CountOrder: "104001006000", DispositionDate: time.Date(2009, time.December, 5, 0, 0, 0, 0, time.UTC), County: "SAN FRANCISCO", SentencePart: data.SentencePart{Length: 30, TimeCode: "D"}}
 		registration := data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1236 12345678-00", DOB: birthDate, CodeSection: "290 PC", WasConvicted: false, CountOrder: "105001007000", DispositionDate: time.Date(2008, time.June, 19, 0, 0, 0, 0, time.UTC), IsPC290Registration: true}
 
 		rows := []data.DOJRow{conviction1, nonConviction, conviction2, registration, conviction3, conviction4, conviction5, conviction5Prison}
@@ -83,6 +82,8 @@ var _ = Describe("Subject", func() {
 	})
 
 	Describe("NumberOfConvictionsInLast7Years", func() {
+		comparisonTime := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+
 		Describe("when at least one conviction occurred within the last 7 years", func() {
 			BeforeEach(func() {
 				conviction6 = data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", OFN: "1119999", DOB: birthDate, CodeSection: "187 PC", WasConvicted: true, CountOrder: "102001003300", DispositionDate: time.Date(2016, time.May, 4, 0, 0, 0, 0, time.UTC), County: "LOS ANGELES"}
@@ -93,12 +94,12 @@ var _ = Describe("Subject", func() {
 			})
 
 			It("returns the number of convictions that occurred in the last 7 years", func() {
-				Expect(subject.NumberOfConvictionsInLast7Years()).To(Equal(2))
+				Expect(subject.NumberOfConvictionsInLast7Years(comparisonTime)).To(Equal(2))
 			})
 		})
 
 		It("returns 0 if no convictions occurred in the last 7 years", func() {
-			Expect(subject.NumberOfConvictionsInLast7Years()).To(Equal(0))
+			Expect(subject.NumberOfConvictionsInLast7Years(comparisonTime)).To(Equal(0))
 		})
 	})
 
@@ -114,3 +115,36 @@ var _ = Describe("Subject", func() {
 		})
 	})
 })
+
+var _ = Describe("Subject relief", func() {
+	var (
+		subject       data.Subject
+		eligibilities map[int]*data.EligibilityInfo
+	)
+	birthDate := time.Date(1994, time.April, 10, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		subject = data.Subject{}
+		subject.PushRow(data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", DOB: birthDate, CodeSection: "11359 HS", IsFelony: true, WasConvicted: true, CountOrder: "101001001000", Index: 0}, nil)
+		subject.PushRow(data.DOJRow{SubjectID: "subj_id", Name: "SOUP,ZAK E", DOB: birthDate, CodeSection: "11357 HS", WasConvicted: true, CountOrder: "102001001000", Index: 1}, nil)
+
+		eligibilities = map[int]*data.EligibilityInfo{0: {}, 1: {}}
+		eligibilities[0].SetEligibleForReduction("Reduce all HS 11359 convictions")
+		eligibilities[1].SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+	})
+
+	It("no longer has a felony when every felony is reduced or dismissed", func() {
+		Expect(subject.NoLongerHasFelony(eligibilities)).To(BeTrue())
+		Expect(subject.NoLongerHasConviction(eligibilities)).To(BeFalse())
+	})
+
+	It("no longer has a conviction when every conviction is dismissed", func() {
+		eligibilities[0].SetEligibleForDismissal("Dismiss all HS 11359 convictions")
+		Expect(subject.NoLongerHasConviction(eligibilities)).To(BeTrue())
+	})
+
+	It("keeps a felony that is not eligible", func() {
+		eligibilities[0].SetNotEligible("Occurred after 11/09/2016")
+		Expect(subject.NoLongerHasFelony(eligibilities)).To(BeFalse())
+	})
+})
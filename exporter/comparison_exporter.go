@@ -0,0 +1,172 @@
+package exporter
+
+import (
+	"gogen/data"
+	"gogen/utilities"
+)
+
+type ComparisonExporter struct {
+	dojInformation       *data.DOJInformation
+	configurationLabels  []string
+	configEligibilities  []map[int]*data.EligibilityInfo
+	outputComparisonFile DOJWriter
+}
+
+type ConfigurationSummary struct {
+	EligibilityOptions                  string         `json:"eligibilityOptions"`
+	ConvictionCountByDetermination      map[string]int `json:"convictionCountByDetermination"`
+	SubjectsWithSomeReliefCount         int            `json:"subjectsWithSomeReliefCount"`
+	CountSubjectsNoFelony               int            `json:"countSubjectsNoFelony"`
+	CountSubjectsNoConviction           int            `json:"countSubjectsNoConviction"`
+	CountSubjectsNoConvictionLast7Years int            `json:"countSubjectsNoConvictionLast7Years"`
+}
+
+type ComparisonSummary struct {
+	County                  string                 `json:"county"`
+	LineCount               int                    `json:"lineCount"`
+	ProcessingTimeInSeconds float64                `json:"processingTimeInSeconds"`
+	Configurations          []ConfigurationSummary `json:"configurations"`
+	DifferingRowsCount      int                    `json:"differingRowsCount"`
+}
+
+var ComparisonRowHeaders = []string{
+	"SUBJECT_ID",
+	"CII_NUMBER",
+	"PRI_NAME",
+	"CNT_ORDER",
+	"DISP_DATE",
+	"OFFENSE_DESCR",
+	"CONV_STAT_DESCR",
+}
+
+func NewComparisonExporter(
+	dojInformation *data.DOJInformation,
+	configurationLabels []string,
+	configEligibilities []map[int]*data.EligibilityInfo,
+	outputComparisonFile DOJWriter,
+) ComparisonExporter {
+	return ComparisonExporter{
+		dojInformation:       dojInformation,
+		configurationLabels:  configurationLabels,
+		configEligibilities:  configEligibilities,
+		outputComparisonFile: outputComparisonFile,
+	}
+}
+
+func NewComparisonWriter(files utilities.OutputFiles, outputFilePath string, configurationLabels []string) (DOJWriter, error) {
+	headers := append([]string{}, ComparisonRowHeaders...)
+	for _, label := range configurationLabels {
+		headers = append(headers, "Eligibility Determination ("+label+")", "Eligibility Reason ("+label+")")
+	}
+	return NewWriter(files, outputFilePath, headers)
+}
+
+func (c *ComparisonExporter) Export(county string) (ComparisonSummary, error) {
+	differingRows := 0
+	for i, row := range c.dojInformation.Rows {
+		if !c.determinationsDiffer(i) {
+			continue
+		}
+		differingRows++
+
+		line := []string{
+			row[data.SUBJECT_ID],
+			row[data.CII_NUMBER],
+			row[data.PRI_NAME],
+			row[data.CNT_ORDER],
+			row[data.DISP_DATE],
+			row[data.OFFENSE_DESCR],
+			row[data.CONV_STAT_DESCR],
+		}
+		for _, eligibilities := range c.configEligibilities {
+			if info := eligibilities[i]; info != nil {
+				line = append(line, info.EligibilityDetermination, info.EligibilityReason)
+			} else {
+				line = append(line, "", "")
+			}
+		}
+		c.outputComparisonFile.Write(line)
+		if c.outputComparisonFile.Error() != nil {
+			break
+		}
+	}
+	err := CollectWriteErrors(c.outputComparisonFile.Flush())
+
+	return c.NewComparisonSummary(county, differingRows), err
+}
+
+func (c *ComparisonExporter) NewComparisonSummary(county string, differingRows int) ComparisonSummary {
+	var configurations []ConfigurationSummary
+	for index, eligibilities := range c.configEligibilities {
+		configurations = append(configurations, ConfigurationSummary{
+			EligibilityOptions:                  c.configurationLabels[index],
+			ConvictionCountByDetermination:      countByDetermination(eligibilities),
+			SubjectsWithSomeReliefCount:         c.dojInformation.CountIndividualsWithSomeRelief(eligibilities),
+			CountSubjectsNoFelony:               c.dojInformation.CountIndividualsNoLongerHaveFelony(eligibilities),
+			CountSubjectsNoConviction:           c.dojInformation.CountIndividualsNoLongerHaveConviction(eligibilities),
+			CountSubjectsNoConvictionLast7Years: c.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(eligibilities),
+		})
+	}
+
+	return ComparisonSummary{
+		County:             county,
+		LineCount:          c.dojInformation.TotalRows(),
+		Configurations:     configurations,
+		DifferingRowsCount: differingRows,
+	}
+}
+
+func (c *ComparisonExporter) AccumulateComparisonData(runSummary ComparisonSummary, fileSummary ComparisonSummary) ComparisonSummary {
+	configurations := make([]ConfigurationSummary, len(fileSummary.Configurations))
+	for index, fileConfiguration := range fileSummary.Configurations {
+		var runConfiguration ConfigurationSummary
+		if index < len(runSummary.Configurations) {
+			runConfiguration = runSummary.Configurations[index]
+		}
+		configurations[index] = ConfigurationSummary{
+			EligibilityOptions:                  fileConfiguration.EligibilityOptions,
+			ConvictionCountByDetermination:      utilities.AddMaps(runConfiguration.ConvictionCountByDetermination, fileConfiguration.ConvictionCountByDetermination),
+			SubjectsWithSomeReliefCount:         runConfiguration.SubjectsWithSomeReliefCount + fileConfiguration.SubjectsWithSomeReliefCount,
+			CountSubjectsNoFelony:               runConfiguration.CountSubjectsNoFelony + fileConfiguration.CountSubjectsNoFelony,
+			CountSubjectsNoConviction:           runConfiguration.CountSubjectsNoConviction + fileConfiguration.CountSubjectsNoConviction,
+			CountSubjectsNoConvictionLast7Years: runConfiguration.CountSubjectsNoConvictionLast7Years + fileConfiguration.CountSubjectsNoConvictionLast7Years,
+		}
+	}
+
+	return ComparisonSummary{
+		County:             fileSummary.County,
+		LineCount:          runSummary.LineCount + fileSummary.LineCount,
+		Configurations:     configurations,
+		DifferingRowsCount: runSummary.DifferingRowsCount + fileSummary.DifferingRowsCount,
+	}
+}
+
+func (c *ComparisonExporter) determinationsDiffer(index int) bool {
+	if len(c.configEligibilities) == 0 {
+		return false
+	}
+	first := determinationAt(c.configEligibilities[0], index)
+	for _, eligibilities := range c.configEligibilities[1:] {
+		if determinationAt(eligibilities, index) != first {
+			return true
+		}
+	}
+	return false
+}
+
+func determinationAt(eligibilities map[int]*data.EligibilityInfo, index int) string {
+	if info := eligibilities[index]; info != nil {
+		return info.EligibilityDetermination
+	}
+	return ""
+}
+
+func countByDetermination(eligibilities map[int]*data.EligibilityInfo) map[string]int {
+	result := make(map[string]int)
+	for _, info := range eligibilities {
+		if info.EligibilityDetermination != "" {
+			result[info.EligibilityDetermination]++
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package exporter_test
+
+import (
+	"encoding/csv"
+	"gogen/data"
+	. "gogen/exporter"
+	. "gogen/test_fixtures"
+	"gogen/utilities"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ComparisonExporter", func() {
+	var (
+		outputDir           string
+		comparisonExporter  ComparisonExporter
+		dojInformation      *data.DOJInformation
+		configEligibilities []map[int]*data.EligibilityInfo
+		err                 error
+	)
+	COUNTY := "SACRAMENTO"
+	labels := []string{"reduce 11359", "dismiss all"}
+
+	BeforeEach(func() {
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		inputPath := path.Join("..", "test_fixtures", "configurable_flow.xlsx")
+		pathToDOJ, _, err := ExtractFullCSVFixtures(inputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		comparisonTime := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+
+		reduceFlow := createFlow([]string{"11357", "11358"}, []string{"11359", "11360"}, COUNTY)
+		dismissAllFlow := createFlow([]string{"11357", "11358", "11359", "11360"}, []string{}, COUNTY)
+
+		dojInformation, _ = data.NewDOJInformation(pathToDOJ, comparisonTime, data.Calendar{}, reduceFlow)
+		configEligibilities = []map[int]*data.EligibilityInfo{
+			dojInformation.DetermineEligibility(COUNTY, reduceFlow),
+			dojInformation.DetermineEligibility(COUNTY, dismissAllFlow),
+		}
+
+		comparisonWriter, _ := NewComparisonWriter(utilities.OutputFiles{}, path.Join(outputDir, "comparison.csv"), labels)
+		comparisonExporter = NewComparisonExporter(dojInformation, labels, configEligibilities, comparisonWriter)
+	})
+
+	It("writes only the rows whose determination differs between configurations", func() {
+		summary, err := comparisonExporter.Export(COUNTY)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(summary.DifferingRowsCount).To(Equal(1))
+
+		outputFile, err := os.Open(path.Join(outputDir, "comparison.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		outputCSV, err := csv.NewReader(outputFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(outputCSV).To(HaveLen(2))
+		Expect(outputCSV[0][len(ComparisonRowHeaders)]).To(Equal("Eligibility Determination (reduce 11359)"))
+		Expect(outputCSV[1][len(ComparisonRowHeaders):]).To(Equal([]string{
+			"Eligible for Reduction", "Reduce all HS 11359 convictions",
+			"Eligible for Dismissal", "Dismiss all HS 11359 convictions",
+		}))
+	})
+
+	It("returns the error of a comparison file that couldn't be written", func() {
+		comparisonWriter, err := NewComparisonWriter(utilities.OutputFiles{}, "/dev/full", labels)
+		Expect(err).ToNot(HaveOccurred())
+		comparisonExporter = NewComparisonExporter(dojInformation, labels, configEligibilities, comparisonWriter)
+
+		_, err = comparisonExporter.Export(COUNTY)
+		Expect(err).To(MatchError(ContainSubstring("/dev/full")))
+	})
+
+	It("summarizes relief for each configuration", func() {
+		summary, _ := comparisonExporter.Export(COUNTY)
+		Expect(summary.Configurations).To(HaveLen(2))
+		Expect(summary.Configurations[0].EligibilityOptions).To(Equal("reduce 11359"))
+		Expect(summary.Configurations[0].ConvictionCountByDetermination["Eligible for Reduction"]).To(Equal(1))
+		Expect(summary.Configurations[1].ConvictionCountByDetermination["Eligible for Reduction"]).To(Equal(0))
+		Expect(summary.Configurations[1].SubjectsWithSomeReliefCount).To(Equal(12))
+	})
+
+	It("accumulates summaries across input files", func() {
+		fileSummary, _ := comparisonExporter.Export(COUNTY)
+		runSummary := comparisonExporter.AccumulateComparisonData(ComparisonSummary{}, fileSummary)
+		runSummary = comparisonExporter.AccumulateComparisonData(runSummary, fileSummary)
+
+		Expect(runSummary.LineCount).To(Equal(76))
+		Expect(runSummary.DifferingRowsCount).To(Equal(2))
+		Expect(runSummary.Configurations[1].ConvictionCountByDetermination["Eligible for Dismissal"]).To(Equal(36))
+	})
+})
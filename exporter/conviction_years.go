@@ -0,0 +1,54 @@
+package exporter
+
+import (
+	"gogen/utilities"
+	"sort"
+)
+
+var ConvictionYearsHeaders = []string{"Year", "Felony", "NonFelony"}
+
+func NewConvictionYearsWriter(files utilities.OutputFiles, outputFilePath string, determinations []string) (DOJWriter, error) {
+	return NewWriter(files, outputFilePath, append(append([]string{}, ConvictionYearsHeaders...), determinations...))
+}
+
+func ConvictionYearDeterminations(summary Summary) []string {
+	var determinations []string
+	for _, counts := range summary.Prop64ConvictionsCountByYearByDetermination {
+		for determination := range counts {
+			if !containsString(determinations, determination) {
+				determinations = append(determinations, determination)
+			}
+		}
+	}
+	sort.Strings(determinations)
+	return determinations
+}
+
+func ConvictionYears(summary Summary) []string {
+	var years []string
+	for _, yearCounts := range []map[string]map[string]int{summary.Prop64ConvictionsCountInCountyByYear, summary.Prop64ConvictionsCountByYearByDetermination} {
+		for year := range yearCounts {
+			if !containsString(years, year) {
+				years = append(years, year)
+			}
+		}
+	}
+	sort.Strings(years)
+	return years
+}
+
+func ExportConvictionYears(summary Summary, outputConvictionYearsWriter DOJWriter) error {
+	determinations := ConvictionYearDeterminations(summary)
+	for _, year := range ConvictionYears(summary) {
+		line := []string{
+			year,
+			writeInt(summary.Prop64ConvictionsCountInCountyByYear[year]["Felony"]),
+			writeInt(summary.Prop64ConvictionsCountInCountyByYear[year]["NonFelony"]),
+		}
+		for _, determination := range determinations {
+			line = append(line, writeInt(summary.Prop64ConvictionsCountByYearByDetermination[year][determination]))
+		}
+		outputConvictionYearsWriter.Write(line)
+	}
+	return outputConvictionYearsWriter.Flush()
+}
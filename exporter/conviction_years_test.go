@@ -0,0 +1,50 @@
+package exporter_test
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("ConvictionYears", func() {
+	It("writes a row of counts for each disposition year", func() {
+		outputDir, err := ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(outputDir)
+
+		summary := Summary{
+			Prop64ConvictionsCountInCountyByYear: map[string]map[string]int{
+				"UNKNOWN": {"NonFelony": 1},
+				"2015":    {"Felony": 2, "NonFelony": 1},
+				"1981":    {"Felony": 2},
+			},
+			Prop64ConvictionsCountByYearByDetermination: map[string]map[string]int{
+				"2015": {"Eligible for Dismissal": 2, "Eligible for Reduction": 1},
+				"1981": {"Hand Review": 2},
+			},
+		}
+
+		outputFilePath := path.Join(outputDir, "Conviction_Years.csv")
+		writer, err := NewConvictionYearsWriter(utilities.OutputFiles{}, outputFilePath, ConvictionYearDeterminations(summary))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ExportConvictionYears(summary, writer)).To(Succeed())
+
+		outputFile, err := os.Open(outputFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer outputFile.Close()
+		rows, err := csv.NewReader(outputFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(Equal([][]string{
+			{"Year", "Felony", "NonFelony", "Eligible for Dismissal", "Eligible for Reduction", "Hand Review"},
+			{"1981", "2", "0", "0", "0", "2"},
+			{"2015", "2", "1", "2", "1", "0"},
+			{"UNKNOWN", "0", "1", "0", "0", "0"},
+		}))
+	})
+})
@@ -0,0 +1,12 @@
+package exporter
+
+import "gogen/data"
+
+func ExportUnmatchedCourtConvictions(dojInformation *data.DOJInformation, eligibilities map[int]*data.EligibilityInfo, unmatched []int, outputUnmatchedDOJWriter DOJWriter) error {
+	for _, index := range unmatched {
+		row := dojInformation.Rows[index]
+		possibleOtherP64Charges := data.PossibleP64ChargeOnlyInComment(row[data.OFFENSE_DESCR], row[data.COMMENT_TEXT])
+		outputUnmatchedDOJWriter.WriteCondensedEntryWithEligibilityInfo(row, dojInformation.ParsedRow(index), eligibilities[index], possibleOtherP64Charges)
+	}
+	return outputUnmatchedDOJWriter.Flush()
+}
@@ -17,24 +17,35 @@ type DataExporter struct {
 	outputDOJWriter                         DOJWriter
 	outputCondensedDOJWriter                DOJWriter
 	outputProp64ConvictionsDOJWriter        DOJWriter
+	outputHandReviewDOJWriter               DOJWriter
+	outputSubjectsWriter                    DOJWriter
 	outputJsonFilePath                      string
+	rowIndexes                              []int
 }
 
 type Summary struct {
-	County                                      string         `json:"county"`
-	EarliestConviction                          time.Time      `json:"earliestConviction"`
-	LineCount                                   int            `json:"lineCount"`
-	ProcessingTimeInSeconds                     float64        `json:"processingTimeInSeconds"`
-	ReliefWithCurrentEligibilityChoices         map[string]int `json:"reliefWithCurrentEligibilityChoices"`
-	ReliefWithDismissAllProp64                  map[string]int `json:"reliefWithDismissAllProp64"`
-	Prop64ConvictionsCountInCountyByCodeSection map[string]int `json:"prop64ConvictionsCountInCountyByCodeSection"`
-	SubjectsWithProp64ConvictionCountInCounty   int            `json:"subjectsWithProp64ConvictionCountInCounty"`
-	Prop64FelonyConvictionsCountInCounty        int            `json:"prop64FelonyConvictionsCountInCounty"`
-	Prop64NonFelonyConvictionsCountInCounty     int            `json:"prop64NonFelonyConvictionsCountInCounty"`
-	SubjectsWithSomeReliefCount                 int            `json:"subjectsWithSomeReliefCount"`
-	ConvictionDismissalCountByCodeSection       map[string]int `json:"convictionDismissalCountByCodeSection"`
-	ConvictionReductionCountByCodeSection       map[string]int `json:"convictionReductionCountByCodeSection"`
-	ConvictionDismissalCountByAdditionalRelief  map[string]int `json:"convictionDismissalCountByAdditionalRelief"`
+	County                                      string                    `json:"county"`
+	EarliestConviction                          time.Time                 `json:"earliestConviction"`
+	LineCount                                   int                       `json:"lineCount"`
+	ProcessingTimeInSeconds                     float64                   `json:"processingTimeInSeconds"`
+	ReliefWithCurrentEligibilityChoices         map[string]int            `json:"reliefWithCurrentEligibilityChoices"`
+	ReliefWithDismissAllProp64                  map[string]int            `json:"reliefWithDismissAllProp64"`
+	ReliefWithDismissAllProp64AndRelated        map[string]int            `json:"reliefWithDismissAllProp64AndRelated"`
+	Prop64ConvictionsCountInCountyByCodeSection map[string]int            `json:"prop64ConvictionsCountInCountyByCodeSection"`
+	Prop64ConvictionsCountInCountyByYear        map[string]map[string]int `json:"prop64ConvictionsCountInCountyByYear"`
+	Prop64ConvictionsCountByYearByDetermination map[string]map[string]int `json:"prop64ConvictionsCountByYearByDetermination"`
+	SubjectsWithProp64ConvictionCountInCounty   int                       `json:"subjectsWithProp64ConvictionCountInCounty"`
+	Prop64FelonyConvictionsCountInCounty        int                       `json:"prop64FelonyConvictionsCountInCounty"`
+	Prop64NonFelonyConvictionsCountInCounty     int                       `json:"prop64NonFelonyConvictionsCountInCounty"`
+	SubjectsWithSomeReliefCount                 int                       `json:"subjectsWithSomeReliefCount"`
+	ConvictionDismissalCountByCodeSection       map[string]int            `json:"convictionDismissalCountByCodeSection"`
+	ConvictionReductionCountByCodeSection       map[string]int            `json:"convictionReductionCountByCodeSection"`
+	ConvictionDismissalCountByAdditionalRelief  map[string]int            `json:"convictionDismissalCountByAdditionalRelief"`
+	HandReviewCountByTrigger                    map[string]int            `json:"handReviewCountByTrigger"`
+	PreviouslyProcessedCount                    int                       `json:"previouslyProcessedCount"`
+	EquityBreakdowns                            *EquityBreakdowns         `json:"equityBreakdowns,omitempty"`
+	Warnings                                    []string                  `json:"warnings"`
+	Manifest                                    *Manifest                 `json:"manifest,omitempty"`
 }
 
 func NewDataExporter(
@@ -45,6 +56,8 @@ func NewDataExporter(
 	outputDOJWriter DOJWriter,
 	outputCondensedDOJWriter DOJWriter,
 	outputProp64ConvictionsDOJWriter DOJWriter,
+	outputHandReviewDOJWriter DOJWriter,
+	outputSubjectsWriter DOJWriter,
 ) DataExporter {
 
 	return DataExporter{
@@ -55,56 +68,63 @@ func NewDataExporter(
 		outputDOJWriter:                         outputDOJWriter,
 		outputCondensedDOJWriter:                outputCondensedDOJWriter,
 		outputProp64ConvictionsDOJWriter:        outputProp64ConvictionsDOJWriter,
+		outputHandReviewDOJWriter:               outputHandReviewDOJWriter,
+		outputSubjectsWriter:                    outputSubjectsWriter,
 	}
 }
 
-func (d *DataExporter) Export(county string, configurableEligibilityFlow data.ConfigurableEligibilityFlow) Summary {
-	for i, row := range d.dojInformation.Rows {
-		possibleOtherP64Charges := PossibleP64ChargeOnlyInComment(row[data.OFFENSE_DESCR], row[data.COMMENT_TEXT])
-		d.outputDOJWriter.WriteEntryWithEligibilityInfo(row, d.normalFlowEligibilities[i], possibleOtherP64Charges)
-		d.outputCondensedDOJWriter.WriteCondensedEntryWithEligibilityInfo(row, d.normalFlowEligibilities[i], possibleOtherP64Charges)
+// WithRowIndexes limits the rows written to All_Results and the other row
+// outputs, so that a statewide run gives each county folder only its own rows.
+func (d DataExporter) WithRowIndexes(rowIndexes []int) DataExporter {
+	d.rowIndexes = rowIndexes
+	return d
+}
+
+func (d *DataExporter) Export(county string, configurableEligibilityFlow data.ConfigurableEligibilityFlow) (Summary, error) {
+	for _, i := range exportedRowIndexes(d.dojInformation, d.rowIndexes) {
+		row := d.dojInformation.Rows[i]
+		possibleOtherP64Charges := data.PossibleP64ChargeOnlyInComment(row[data.OFFENSE_DESCR], row[data.COMMENT_TEXT])
+		parsedRow := d.dojInformation.ParsedRow(i)
+		d.outputDOJWriter.WriteEntryWithEligibilityInfo(row, parsedRow, d.normalFlowEligibilities[i], possibleOtherP64Charges)
+		d.outputCondensedDOJWriter.WriteCondensedEntryWithEligibilityInfo(row, parsedRow, d.normalFlowEligibilities[i], possibleOtherP64Charges)
 		if d.normalFlowEligibilities[i] != nil {
-			d.outputProp64ConvictionsDOJWriter.WriteEntryWithEligibilityInfo(row, d.normalFlowEligibilities[i], possibleOtherP64Charges)
+			d.outputProp64ConvictionsDOJWriter.WriteEntryWithEligibilityInfo(row, parsedRow, d.normalFlowEligibilities[i], possibleOtherP64Charges)
+			if needsReview(d.normalFlowEligibilities[i]) {
+				d.outputHandReviewDOJWriter.WriteEntryWithEligibilityInfo(row, parsedRow, d.normalFlowEligibilities[i], possibleOtherP64Charges)
+			}
+		}
+		if d.writeFailed() {
+			break
 		}
 	}
 
-	d.outputDOJWriter.Flush()
-	d.outputCondensedDOJWriter.Flush()
-	d.outputProp64ConvictionsDOJWriter.Flush()
-	return d.NewSummary(county, configurableEligibilityFlow)
-}
-
-func PossibleP64ChargeOnlyInComment(offenseDescription, commentText string) string {
-	if !data.IsCodeSectionInComment(offenseDescription) {
-		prop64InComment, commentCodeSection := matchers.ExtractProp64Section(commentText)
-		if prop64InComment {
-			prop64InOffenseDescription, offenseDescriptionCodeSection := matchers.ExtractProp64Section(offenseDescription)
-			if !prop64InOffenseDescription {
-				return commentText
-			} else if offenseDescriptionCodeSection != commentCodeSection {
-				return commentText
-			} else if offenseDescriptionCodeSection == "11357" {
-				offenseDescriptionHasSubSection, offenseDescriptionSubSection := matchers.Extract11357SubSection(offenseDescription)
-				commentTextHasSubSection, commentTextSubSection := matchers.Extract11357SubSection(commentText)
-				if offenseDescriptionHasSubSection && commentTextHasSubSection {
-					if (offenseDescriptionSubSection == "A" || offenseDescriptionSubSection == "B") &&
-						(commentTextSubSection == "C" || commentTextSubSection == "D") {
-						return commentText
-					} else if (offenseDescriptionSubSection == "C" || offenseDescriptionSubSection == "D") &&
-						(commentTextSubSection == "A" || commentTextSubSection == "B") {
-						return commentText
-					} else {
-						return ""
-					}
-				} else if commentTextHasSubSection {
-					return commentText
-				}
-			}
-		}
+	err := CollectWriteErrors(
+		d.outputDOJWriter.Flush(),
+		d.outputCondensedDOJWriter.Flush(),
+		d.outputProp64ConvictionsDOJWriter.Flush(),
+		d.outputHandReviewDOJWriter.Flush(),
+		d.exportSubjects(),
+	)
+	return d.NewSummary(county, configurableEligibilityFlow), err
+}
+
+func exportedRowIndexes(dojInformation *data.DOJInformation, rowIndexes []int) []int {
+	if rowIndexes != nil {
+		return rowIndexes
+	}
+	indexes := make([]int, len(dojInformation.Rows))
+	for index := range indexes {
+		indexes[index] = index
 	}
-	return ""
+	return indexes
 }
 
+func (d *DataExporter) writeFailed() bool {
+	return d.outputDOJWriter.Error() != nil ||
+		d.outputCondensedDOJWriter.Error() != nil ||
+		d.outputProp64ConvictionsDOJWriter.Error() != nil ||
+		d.outputHandReviewDOJWriter.Error() != nil
+}
 
 func (d *DataExporter) AccumulateSummaryData(runSummary Summary, fileSummary Summary) Summary {
 	return Summary{
@@ -113,14 +133,21 @@ func (d *DataExporter) AccumulateSummaryData(runSummary Summary, fileSummary Sum
 		EarliestConviction:                  findEarliest(runSummary.EarliestConviction, fileSummary.EarliestConviction),
 		ReliefWithCurrentEligibilityChoices: utilities.AddMaps(runSummary.ReliefWithCurrentEligibilityChoices, fileSummary.ReliefWithCurrentEligibilityChoices),
 		ReliefWithDismissAllProp64:          utilities.AddMaps(runSummary.ReliefWithDismissAllProp64, fileSummary.ReliefWithDismissAllProp64),
+		ReliefWithDismissAllProp64AndRelated: utilities.AddMaps(runSummary.ReliefWithDismissAllProp64AndRelated, fileSummary.ReliefWithDismissAllProp64AndRelated),
 		Prop64ConvictionsCountInCountyByCodeSection: utilities.AddMaps(runSummary.Prop64ConvictionsCountInCountyByCodeSection, fileSummary.Prop64ConvictionsCountInCountyByCodeSection),
+		Prop64ConvictionsCountInCountyByYear:        addNestedMaps(runSummary.Prop64ConvictionsCountInCountyByYear, fileSummary.Prop64ConvictionsCountInCountyByYear),
+		Prop64ConvictionsCountByYearByDetermination: addNestedMaps(runSummary.Prop64ConvictionsCountByYearByDetermination, fileSummary.Prop64ConvictionsCountByYearByDetermination),
 		Prop64FelonyConvictionsCountInCounty:        runSummary.Prop64FelonyConvictionsCountInCounty + fileSummary.Prop64FelonyConvictionsCountInCounty,
 		Prop64NonFelonyConvictionsCountInCounty:     runSummary.Prop64NonFelonyConvictionsCountInCounty + fileSummary.Prop64NonFelonyConvictionsCountInCounty,
 		SubjectsWithSomeReliefCount:                 runSummary.SubjectsWithSomeReliefCount + fileSummary.SubjectsWithSomeReliefCount,
 		ConvictionDismissalCountByAdditionalRelief:  utilities.AddMaps(runSummary.ConvictionDismissalCountByAdditionalRelief, fileSummary.ConvictionDismissalCountByAdditionalRelief),
 		ConvictionDismissalCountByCodeSection:       utilities.AddMaps(runSummary.ConvictionDismissalCountByCodeSection, fileSummary.ConvictionDismissalCountByCodeSection),
 		ConvictionReductionCountByCodeSection:       utilities.AddMaps(runSummary.ConvictionReductionCountByCodeSection, fileSummary.ConvictionReductionCountByCodeSection),
+		HandReviewCountByTrigger:                    utilities.AddMaps(runSummary.HandReviewCountByTrigger, fileSummary.HandReviewCountByTrigger),
+		PreviouslyProcessedCount:                    runSummary.PreviouslyProcessedCount + fileSummary.PreviouslyProcessedCount,
 		SubjectsWithProp64ConvictionCountInCounty:   runSummary.SubjectsWithProp64ConvictionCountInCounty + fileSummary.SubjectsWithProp64ConvictionCountInCounty,
+		EquityBreakdowns:                            mergeEquityBreakdowns(runSummary.EquityBreakdowns, fileSummary.EquityBreakdowns),
+		Warnings:                                    append(runSummary.Warnings, fileSummary.Warnings...),
 	}
 }
 
@@ -139,10 +166,19 @@ func (d *DataExporter) NewSummary(county string, configurableEligibilityFlow dat
 			"CountSubjectsNoConvictionLast7Years": d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.dismissAllProp64Eligibilities),
 			"CountSubjectsNoConviction":           d.dojInformation.CountIndividualsNoLongerHaveConviction(d.dismissAllProp64Eligibilities),
 		},
+		ReliefWithDismissAllProp64AndRelated: map[string]int{
+			"CountSubjectsNoFelony":               d.dojInformation.CountIndividualsNoLongerHaveFelony(d.dismissAllProp64AndRelatedEligibilities),
+			"CountSubjectsNoConvictionLast7Years": d.dojInformation.CountIndividualsNoLongerHaveConvictionInLast7Years(d.dismissAllProp64AndRelatedEligibilities),
+			"CountSubjectsNoConviction":           d.dojInformation.CountIndividualsNoLongerHaveConviction(d.dismissAllProp64AndRelatedEligibilities),
+		},
 		Prop64ConvictionsCountInCountyByCodeSection: d.dojInformation.Prop64ConvictionsInThisCountyByCodeSection(county),
+		Prop64ConvictionsCountInCountyByYear:        d.dojInformation.Prop64ConvictionsInThisCountyByYearByFelonyStatus(county),
+		Prop64ConvictionsCountByYearByDetermination: d.dojInformation.Prop64ConvictionsInThisCountyByYearByEligibility(county, d.normalFlowEligibilities),
 		ConvictionDismissalCountByCodeSection:       d.getDismissalsByCodeSection(county, configurableEligibilityFlow),
 		ConvictionReductionCountByCodeSection:       d.getReductionsByCodeSection(county, configurableEligibilityFlow),
 		ConvictionDismissalCountByAdditionalRelief:  d.getDismissalsByAdditionalRelief(county, configurableEligibilityFlow),
+		HandReviewCountByTrigger:                    d.getHandReviewsByTrigger(county),
+		PreviouslyProcessedCount:                    d.getPreviouslyProcessedCount(county),
 		SubjectsWithSomeReliefCount:                 d.dojInformation.CountIndividualsWithSomeRelief(d.normalFlowEligibilities),
 		Prop64FelonyConvictionsCountInCounty:        d.dojInformation.TotalConvictionsInCountyFiltered(county, data.IsFelonyFilter, matchers.IsProp64Charge),
 		Prop64NonFelonyConvictionsCountInCounty:     d.dojInformation.TotalConvictionsInCountyFiltered(county, data.IsNotFelonyFilter, matchers.IsProp64Charge),
@@ -150,6 +186,16 @@ func (d *DataExporter) NewSummary(county string, configurableEligibilityFlow dat
 	}
 }
 
+func addNestedMaps(map1 map[string]map[string]int, map2 map[string]map[string]int) map[string]map[string]int {
+	sum := make(map[string]map[string]int)
+	for _, nestedMap := range []map[string]map[string]int{map1, map2} {
+		for key, counts := range nestedMap {
+			sum[key] = utilities.AddMaps(sum[key], counts)
+		}
+	}
+	return sum
+}
+
 func findEarliest(time1 time.Time, time2 time.Time) time.Time {
 	if !time1.IsZero() && time1.Before(time2) {
 		return time1
@@ -172,7 +218,8 @@ func (d *DataExporter) getReductionsByCodeSection(county string, configurableEli
 	var eligibilityReasonKey string
 	for _, codeSection := range configurableEligibilityFlow.ReduceSections {
 		eligibilityReasonKey = fmt.Sprintf("Reduce all HS %s convictions", codeSection)
-		result[codeSection] = d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)["Eligible for Reduction"][eligibilityReasonKey]
+		reductions := d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)["Eligible for Reduction"]
+		result[codeSection] = reductions[eligibilityReasonKey] + reductions[data.SentenceNotCompletedReason(eligibilityReasonKey)]
 	}
 	return result
 }
@@ -186,3 +233,26 @@ func (d *DataExporter) getDismissalsByAdditionalRelief(county string, configurab
 	}
 	return result
 }
+
+func (d *DataExporter) getHandReviewsByTrigger(county string) map[string]int {
+	result := make(map[string]int)
+	eligibilitiesByReason := d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)
+	for _, determination := range []string{"Hand Review", "Maybe Eligible - Flag for Review"} {
+		for key, value := range eligibilitiesByReason[determination] {
+			result[key] += value
+		}
+	}
+	return result
+}
+
+func (d *DataExporter) getPreviouslyProcessedCount(county string) int {
+	count := 0
+	for _, value := range d.dojInformation.Prop64ConvictionsInThisCountyByEligibilityByReason(county, d.normalFlowEligibilities)["Previously Processed"] {
+		count += value
+	}
+	return count
+}
+
+func needsReview(info *data.EligibilityInfo) bool {
+	return info.EligibilityDetermination == "Hand Review" || info.EligibilityDetermination == "Maybe Eligible - Flag for Review"
+}
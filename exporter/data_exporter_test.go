@@ -10,9 +10,11 @@ import (
 	"gogen/data"
 	. "gogen/exporter"
 	. "gogen/test_fixtures"
+	"gogen/utilities"
 	"io/ioutil"
 	"os"
 	path "path/filepath"
+	"sort"
 	"time"
 )
 
@@ -42,7 +44,7 @@ var _ = Describe("DataExporter", func() {
 			reduceCodeSections := []string{"11359", "11360"}
 			flow = createFlow(dismissCodeSections, reduceCodeSections, COUNTY)
 
-			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, flow)
+			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, data.Calendar{}, flow)
 			dojEligibilities := dojInformation.DetermineEligibility(COUNTY, flow)
 			dismissAllProp64Eligibilities := dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64"])
 			dismissAllProp64AndRelatedEligibilities := dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"])
@@ -50,9 +52,11 @@ var _ = Describe("DataExporter", func() {
 			dojResultsPath := path.Join(outputDir, "results.csv")
 			dojCondensedResultsPath := path.Join(outputDir, "condensed.csv")
 
-			dojWriter, _ := NewDOJWriter(dojResultsPath)
-			dojCondensedWriter, _ := NewCondensedDOJWriter(dojCondensedResultsPath)
-			dojProp64ConvictionsWriter, _ := NewDOJWriter(path.Join(outputDir, "convictions.csv"))
+			dojWriter, _ := NewDOJWriter(utilities.OutputFiles{}, dojResultsPath)
+			dojCondensedWriter, _ := NewCondensedDOJWriter(utilities.OutputFiles{}, dojCondensedResultsPath)
+			dojProp64ConvictionsWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "convictions.csv"))
+			dojHandReviewWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "hand_review.csv"))
+			subjectsWriter, _ := NewSubjectsWriter(utilities.OutputFiles{}, path.Join(outputDir, "subjects.csv"))
 
 			dataExporter = NewDataExporter(
 				dojInformation,
@@ -60,7 +64,9 @@ var _ = Describe("DataExporter", func() {
 				dismissAllProp64Eligibilities,
 				dismissAllProp64AndRelatedEligibilities,
 				dojWriter, dojCondensedWriter,
-				dojProp64ConvictionsWriter)
+				dojProp64ConvictionsWriter,
+				dojHandReviewWriter,
+				subjectsWriter)
 		})
 
 		It("runs and has condensed output", func() {
@@ -101,14 +107,16 @@ var _ = Describe("DataExporter", func() {
 			reduceCodeSections := []string{"11359", "11360"}
 			flow = createFlow(dismissCodeSections, reduceCodeSections, COUNTY)
 
-			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, flow)
+			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, data.Calendar{}, flow)
 			dojEligibilities := dojInformation.DetermineEligibility(COUNTY, flow)
 			dismissAllProp64Eligibilities := dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64"])
 			dismissAllProp64AndRelatedEligibilities := dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"])
 
-			dojWriter, _ := NewDOJWriter(path.Join(outputDir, "results.csv"))
-			dojCondensedWriter, _ := NewDOJWriter(path.Join(outputDir, "condensed.csv"))
-			dojProp64ConvictionsWriter, _ := NewDOJWriter(path.Join(outputDir, "convictions.csv"))
+			dojWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "results.csv"))
+			dojCondensedWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "condensed.csv"))
+			dojProp64ConvictionsWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "convictions.csv"))
+			dojHandReviewWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "hand_review.csv"))
+			subjectsWriter, _ := NewSubjectsWriter(utilities.OutputFiles{}, path.Join(outputDir, "subjects.csv"))
 
 			dataExporter = NewDataExporter(
 				dojInformation,
@@ -117,7 +125,9 @@ var _ = Describe("DataExporter", func() {
 				dismissAllProp64AndRelatedEligibilities,
 				dojWriter,
 				dojCondensedWriter,
-				dojProp64ConvictionsWriter)
+				dojProp64ConvictionsWriter,
+				dojHandReviewWriter,
+				subjectsWriter)
 		})
 
 		It("runs and has condensed output", func() {
@@ -158,14 +168,16 @@ var _ = Describe("DataExporter", func() {
 			reduceCodeSections := []string{"11359", "11360"}
 			flow = createFlow(dismissCodeSections, reduceCodeSections, COUNTY)
 
-			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, flow)
+			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, data.Calendar{}, flow)
 			dojEligibilities := dojInformation.DetermineEligibility(COUNTY, flow)
 			dismissAllProp64Eligibilities := dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64"])
 			dismissAllProp64AndRelatedEligibilities := dojInformation.DetermineEligibility(COUNTY, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"])
 
-			dojWriter, _ := NewDOJWriter(path.Join(outputDir, "results.csv"))
-			dojCondensedWriter, _ := NewDOJWriter(path.Join(outputDir, "condensed.csv"))
-			dojProp64ConvictionsWriter, _ := NewDOJWriter(path.Join(outputDir, "convictions.csv"))
+			dojWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "results.csv"))
+			dojCondensedWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "condensed.csv"))
+			dojProp64ConvictionsWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "convictions.csv"))
+			dojHandReviewWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "hand_review.csv"))
+			subjectsWriter, _ := NewSubjectsWriter(utilities.OutputFiles{}, path.Join(outputDir, "subjects.csv"))
 
 			dataExporter = NewDataExporter(
 				dojInformation,
@@ -174,7 +186,9 @@ var _ = Describe("DataExporter", func() {
 				dismissAllProp64AndRelatedEligibilities,
 				dojWriter,
 				dojCondensedWriter,
-				dojProp64ConvictionsWriter)
+				dojProp64ConvictionsWriter,
+				dojHandReviewWriter,
+				subjectsWriter)
 		})
 
 		It("runs and has output", func() {
@@ -197,6 +211,84 @@ var _ = Describe("DataExporter", func() {
 		})
 	})
 
+	Describe("Hand review output file", func() {
+		It("holds the convictions flagged for review and nothing else", func() {
+			COUNTY := "SACRAMENTO"
+			outputDir, err = ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			inputPath := path.Join("..", "test_fixtures", "configurable_flow.xlsx")
+			pathToDOJ, _, err = ExtractFullCSVFixtures(inputPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			comparisonTime := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+			flow = createFlow([]string{"11357", "11358"}, []string{"11359", "11360"}, COUNTY)
+			dojInformation, _ := data.NewDOJInformation(pathToDOJ, comparisonTime, data.Calendar{}, flow)
+
+			eligibilities := dojInformation.DetermineEligibility(COUNTY, flow)
+			var indexes []int
+			for index := range eligibilities {
+				indexes = append(indexes, index)
+			}
+			sort.Ints(indexes)
+			Expect(len(indexes)).To(BeNumerically(">=", 4))
+
+			eligibilities[indexes[0]].SetHandReview("Missing date of birth")
+			eligibilities[indexes[1]].SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+			eligibilities[indexes[2]].SetMaybeEligible("Possible Prop 64 charge in comment")
+			eligibilities[indexes[3]].SetNotEligible("Sentence not completed")
+
+			dojWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "results.csv"))
+			dojCondensedWriter, _ := NewCondensedDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "condensed.csv"))
+			dojProp64ConvictionsWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "convictions.csv"))
+			dojHandReviewWriter, _ := NewDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "hand_review.csv"))
+			subjectsWriter, _ := NewSubjectsWriter(utilities.OutputFiles{}, path.Join(outputDir, "subjects.csv"))
+
+			dataExporter = NewDataExporter(
+				dojInformation,
+				eligibilities,
+				eligibilities,
+				eligibilities,
+				dojWriter,
+				dojCondensedWriter,
+				dojProp64ConvictionsWriter,
+				dojHandReviewWriter,
+				subjectsWriter)
+			_, err = dataExporter.Export(COUNTY, flow)
+			Expect(err).ToNot(HaveOccurred())
+
+			handReviewFile, err := os.Open(path.Join(outputDir, "hand_review.csv"))
+			Expect(err).ToNot(HaveOccurred())
+			defer handReviewFile.Close()
+			handReview, err := csv.NewReader(handReviewFile).ReadAll()
+			Expect(err).ToNot(HaveOccurred())
+
+			columns := make(map[string]int)
+			for index, header := range handReview[0] {
+				columns[header] = index
+			}
+			var reviewed []string
+			for _, row := range handReview[1:] {
+				reviewed = append(reviewed, row[columns["SUBJECT_ID"]]+":"+row[columns["CNT_ORDER"]]+" "+row[columns["Eligibility Determination"]])
+			}
+			convictionKey := func(index int) string {
+				return dojInformation.Rows[index][data.SUBJECT_ID] + ":" + dojInformation.Rows[index][data.CNT_ORDER]
+			}
+			var expected []string
+			for _, index := range indexes {
+				determination := eligibilities[index].EligibilityDetermination
+				if determination == "Hand Review" || determination == "Maybe Eligible - Flag for Review" {
+					expected = append(expected, convictionKey(index)+" "+determination)
+				}
+			}
+			Expect(reviewed).To(Equal(expected))
+			Expect(reviewed).To(ContainElement(convictionKey(indexes[0]) + " Hand Review"))
+			Expect(reviewed).To(ContainElement(convictionKey(indexes[2]) + " Maybe Eligible - Flag for Review"))
+			Expect(reviewed).ToNot(ContainElement(HavePrefix(convictionKey(indexes[1]) + " ")))
+			Expect(reviewed).ToNot(ContainElement(HavePrefix(convictionKey(indexes[3]) + " ")))
+		})
+	})
+
 	Describe("AccumulateSummaryData", func() {
 		It("adds new stats to stats already accumulated", func() {
 			existingStats := Summary{
@@ -218,6 +310,11 @@ var _ = Describe("DataExporter", func() {
 					"11358": 6,
 					"11359": 7,
 				},
+				HandReviewCountByTrigger: map[string]int{
+					"Missing date of birth":        1,
+					"Code section only in comment": 2,
+				},
+				PreviouslyProcessedCount: 2,
 			}
 
 			newStats := Summary{
@@ -239,6 +336,10 @@ var _ = Describe("DataExporter", func() {
 					"11358": 7,
 					"11359": 8,
 				},
+				HandReviewCountByTrigger: map[string]int{
+					"Missing date of birth": 3,
+				},
+				PreviouslyProcessedCount: 1,
 			}
 
 			cumulativeStats := dataExporter.AccumulateSummaryData(existingStats, newStats)
@@ -262,6 +363,11 @@ var _ = Describe("DataExporter", func() {
 					"11358": Equal(13),
 					"11359": Equal(15),
 				}),
+				"HandReviewCountByTrigger": gstruct.MatchAllKeys(gstruct.Keys{
+					"Missing date of birth":        Equal(4),
+					"Code section only in comment": Equal(2),
+				}),
+				"PreviouslyProcessedCount": Equal(3),
 			}))
 		})
 
@@ -296,32 +402,6 @@ var _ = Describe("DataExporter", func() {
 	})
 })
 
-var _ = Describe("PossibleP64ChargeOnlyInComment", func() {
-	It("returns the comment text if the comment text has a Prop64 charge and the offense description doesn't", func() {
-		Expect(PossibleP64ChargeOnlyInComment("912", "11357(A)")).To(Equal("11357(A)"))
-		Expect(PossibleP64ChargeOnlyInComment("11350", "11357(A)")).To(Equal("11357(A)"))
-	})
-
-	It("returns empty string if the comment text is expected to override the offense description", func() {
-		Expect(PossibleP64ChargeOnlyInComment("", "11357(A)")).To(Equal(""))
-		Expect(PossibleP64ChargeOnlyInComment("SEE COMMENT FOR CHARGE", "11357(A)")).To(Equal(""))
-	})
-	It("returns the comment text if offense description and comment text have different Prop64 charges that are not both 11357 subsections", func() {
-		Expect(PossibleP64ChargeOnlyInComment("11358", "11359")).To(Equal("11359"))
-		Expect(PossibleP64ChargeOnlyInComment("11357(A)", "11358")).To(Equal("11358"))
-		Expect(PossibleP64ChargeOnlyInComment("11358", "11357(C)")).To(Equal("11357(C)"))
-	})
-	It("returns the comment text if offense description and comment text have different 11357 subsections, one from the (A,B) group and the other from the (C,D) group", func() {
-		Expect(PossibleP64ChargeOnlyInComment("11357(A)", "11357(C)")).To(Equal("11357(C)"))
-		Expect(PossibleP64ChargeOnlyInComment("11357(D)", "11357(B)")).To(Equal("11357(B)"))
-	})
-	It("returns the empty string if offense description and comment text have different 11357 subsections, but from the same group (A,B) or (C,D)", func() {
-		Expect(PossibleP64ChargeOnlyInComment("11357(A)", "11357(B)")).To(Equal(""))
-		Expect(PossibleP64ChargeOnlyInComment("11357(D)", "11357(C)")).To(Equal(""))
-	})
-
-})
-
 func expectCSVsToBeEqual(expectedCSV [][]string, actualCSV [][]string) {
 	for i, row := range actualCSV {
 		for j, item := range row {
@@ -0,0 +1,312 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"gogen/utilities"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const dispositionUpdateDateFormat = "20060102"
+
+type DispositionUpdateField struct {
+	Name   string
+	Length int
+}
+
+var DispositionUpdateLayout = []DispositionUpdateField{
+	{"RECORD_TYPE", 1},
+	{"SUBJECT_ID", 10},
+	{"CII_NUMBER", 10},
+	{"CNT_ORDER", 12},
+	{"OFN", 20},
+	{"RELIEF_CODE", 1},
+	{"DECISION_DATE", 8},
+}
+
+var RejectedDecisionHeaders = []string{
+	"LINE",
+	"SUBJECT_ID",
+	"CNT_ORDER",
+	"DECISION",
+	"REJECTION_REASON",
+}
+
+var reliefCodes = map[string]string{
+	"DISMISSED": "D",
+	"REDUCED":   "R",
+}
+
+type CourtDecision struct {
+	Line         int
+	SubjectID    string
+	CountOrder   string
+	Decision     string
+	Relief       string
+	DecisionDate string
+}
+
+type DispositionUpdate struct {
+	SubjectID    string
+	CII          string
+	CountOrder   string
+	OFN          string
+	ReliefCode   string
+	DecisionDate time.Time
+}
+
+type RejectedDecision struct {
+	Decision CourtDecision
+	Reason   string
+}
+
+type resultRow struct {
+	subjectID     string
+	cii           string
+	countOrder    string
+	ofn           string
+	determination string
+}
+
+func ReadCourtDecisions(courtDecisionsPath string) ([]CourtDecision, error) {
+	rows, columns, err := readCSVWithColumns(courtDecisionsPath, "SUBJECT_ID", "CNT_ORDER", "DECISION")
+	if err != nil {
+		return nil, err
+	}
+
+	var decisions []CourtDecision
+	for index, row := range rows {
+		decisions = append(decisions, CourtDecision{
+			Line:         index + 2,
+			SubjectID:    csvValue(row, columns, "SUBJECT_ID"),
+			CountOrder:   csvValue(row, columns, "CNT_ORDER"),
+			Decision:     strings.ToUpper(csvValue(row, columns, "DECISION")),
+			Relief:       strings.ToUpper(csvValue(row, columns, "RELIEF")),
+			DecisionDate: csvValue(row, columns, "DECISION_DATE"),
+		})
+	}
+	return decisions, nil
+}
+
+// BuildDispositionUpdates matches decisions to the convictions in an All_Results
+// file. decryption is only needed when the results file is encrypted.
+func BuildDispositionUpdates(resultsPath string, decryption *utilities.OutputDecryption, decisions []CourtDecision) ([]DispositionUpdate, []RejectedDecision, error) {
+	rows, columns, err := readCSV(resultsPath, decryption)
+	if err != nil {
+		return nil, nil, err
+	}
+	if column := missingColumn(columns, "SUBJECT_ID", "CII_NUMBER", "CNT_ORDER", "OFN", "Eligibility Determination"); column != "" {
+		return nil, nil, fmt.Errorf("%s is missing column %q; disposition-update needs All_Results written without --output-profile, or with a profile that keeps the default columns", resultsPath, column)
+	}
+
+	resultsByKey := make(map[string][]resultRow)
+	for _, row := range rows {
+		result := resultRow{
+			subjectID:     csvValue(row, columns, "SUBJECT_ID"),
+			cii:           csvValue(row, columns, "CII_NUMBER"),
+			countOrder:    csvValue(row, columns, "CNT_ORDER"),
+			ofn:           csvValue(row, columns, "OFN"),
+			determination: csvValue(row, columns, "Eligibility Determination"),
+		}
+		if result.determination == "" {
+			continue
+		}
+		key := result.subjectID + ":" + result.countOrder
+		resultsByKey[key] = append(resultsByKey[key], result)
+	}
+
+	var updates []DispositionUpdate
+	var rejected []RejectedDecision
+	seen := make(map[string]bool)
+	reject := func(decision CourtDecision, reason string) {
+		rejected = append(rejected, RejectedDecision{Decision: decision, Reason: reason})
+	}
+
+	for _, decision := range decisions {
+		key := decision.SubjectID + ":" + decision.CountOrder
+		results := resultsByKey[key]
+
+		switch {
+		case decision.Decision != "GRANTED" && decision.Decision != "DENIED":
+			reject(decision, fmt.Sprintf("decision should be GRANTED or DENIED, got %q", decision.Decision))
+		case seen[key]:
+			reject(decision, "duplicate decision for this conviction")
+		case len(results) == 0:
+			reject(decision, "no conviction in the results with this SUBJECT_ID and CNT_ORDER")
+		case len(results) > 1:
+			reject(decision, "more than one conviction in the results with this SUBJECT_ID and CNT_ORDER")
+		case decision.Decision == "DENIED":
+			seen[key] = true
+		default:
+			seen[key] = true
+			update, reason := dispositionUpdateFor(decision, results[0])
+			if reason != "" {
+				reject(decision, reason)
+			} else {
+				updates = append(updates, update)
+			}
+		}
+	}
+	return updates, rejected, nil
+}
+
+func dispositionUpdateFor(decision CourtDecision, result resultRow) (DispositionUpdate, string) {
+	relief := decision.Relief
+	if relief == "" {
+		switch result.determination {
+		case "Eligible for Dismissal":
+			relief = "DISMISSED"
+		case "Eligible for Reduction":
+			relief = "REDUCED"
+		default:
+			return DispositionUpdate{}, fmt.Sprintf("RELIEF is needed for a conviction determined %q", result.determination)
+		}
+	}
+	reliefCode, ok := reliefCodes[relief]
+	if !ok {
+		return DispositionUpdate{}, fmt.Sprintf("relief should be DISMISSED or REDUCED, got %q", relief)
+	}
+
+	decisionDate, err := time.Parse("01/02/2006", decision.DecisionDate)
+	if err != nil {
+		return DispositionUpdate{}, fmt.Sprintf("DECISION_DATE should be a date like 01/31/2020, got %q", decision.DecisionDate)
+	}
+
+	update := DispositionUpdate{
+		SubjectID:    result.subjectID,
+		CII:          result.cii,
+		CountOrder:   result.countOrder,
+		OFN:          result.ofn,
+		ReliefCode:   reliefCode,
+		DecisionDate: decisionDate,
+	}
+	for _, field := range []struct{ name, value string }{
+		{"SUBJECT_ID", update.SubjectID},
+		{"CII_NUMBER", update.CII},
+		{"CNT_ORDER", update.CountOrder},
+	} {
+		if field.value == "" {
+			return DispositionUpdate{}, fmt.Sprintf("%s is missing in the results", field.name)
+		}
+	}
+	for index, value := range dispositionUpdateValues(update) {
+		field := DispositionUpdateLayout[index]
+		if len(value) > field.Length {
+			return DispositionUpdate{}, fmt.Sprintf("%s %q is longer than %d characters", field.Name, value, field.Length)
+		}
+	}
+	return update, ""
+}
+
+func WriteDispositionUpdateFile(files utilities.OutputFiles, outputFilePath string, updates []DispositionUpdate, createdAt time.Time) error {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("H%s%08d", createdAt.Format(dispositionUpdateDateFormat), len(updates)))
+	for _, update := range updates {
+		var line strings.Builder
+		for index, value := range dispositionUpdateValues(update) {
+			line.WriteString(fmt.Sprintf("%-*s", DispositionUpdateLayout[index].Length, value))
+		}
+		lines = append(lines, line.String())
+	}
+	lines = append(lines, fmt.Sprintf("T%08d", len(updates)))
+
+	return files.WriteFile(outputFilePath, []byte(strings.Join(lines, "\r\n")+"\r\n"))
+}
+
+func NewRejectedDecisionWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	return NewWriter(files, outputFilePath, RejectedDecisionHeaders)
+}
+
+func ExportRejectedDecisions(rejected []RejectedDecision, outputRejectedDecisionWriter DOJWriter) error {
+	for _, rejection := range rejected {
+		outputRejectedDecisionWriter.Write([]string{
+			writeInt(rejection.Decision.Line),
+			rejection.Decision.SubjectID,
+			rejection.Decision.CountOrder,
+			rejection.Decision.Decision,
+			rejection.Reason,
+		})
+	}
+	return outputRejectedDecisionWriter.Flush()
+}
+
+func dispositionUpdateValues(update DispositionUpdate) []string {
+	return []string{
+		"D",
+		update.SubjectID,
+		update.CII,
+		update.CountOrder,
+		update.OFN,
+		update.ReliefCode,
+		update.DecisionDate.Format(dispositionUpdateDateFormat),
+	}
+}
+
+func readCSVWithColumns(filePath string, requiredColumns ...string) ([][]string, map[string]int, error) {
+	rows, columns, err := readCSV(filePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if column := missingColumn(columns, requiredColumns...); column != "" {
+		return nil, nil, fmt.Errorf("%s is missing column %q", filePath, column)
+	}
+	return rows, columns, nil
+}
+
+func readCSV(filePath string, decryption *utilities.OutputDecryption) ([][]string, map[string]int, error) {
+	csvFile, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer csvFile.Close()
+
+	var source io.Reader = csvFile
+	if strings.HasSuffix(filePath, utilities.EncryptedFileExtension) {
+		if decryption == nil {
+			return nil, nil, fmt.Errorf("%s is encrypted; pass --passphrase-file or --private-key", filePath)
+		}
+		var decrypted bytes.Buffer
+		err = decryption.Decrypt(csvFile, &decrypted)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", filePath, err)
+		}
+		source = &decrypted
+	}
+
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%s is empty", filePath)
+	}
+
+	columns := make(map[string]int)
+	for index, header := range rows[0] {
+		columns[strings.TrimSpace(header)] = index
+	}
+	return rows[1:], columns, nil
+}
+
+func missingColumn(columns map[string]int, requiredColumns ...string) string {
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			return column
+		}
+	}
+	return ""
+}
+
+func csvValue(row []string, columns map[string]int, column string) string {
+	index, ok := columns[column]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
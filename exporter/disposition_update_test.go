@@ -0,0 +1,139 @@
+package exporter_test
+
+import (
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("DispositionUpdate", func() {
+	var (
+		outputDir   string
+		resultsPath string
+	)
+
+	writeFile := func(name string, contents string) string {
+		filePath := path.Join(outputDir, name)
+		Expect(ioutil.WriteFile(filePath, []byte(contents), os.ModePerm)).To(Succeed())
+		return filePath
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		resultsPath = writeFile("All_Results.csv", "SUBJECT_ID,CII_NUMBER,CNT_ORDER,OFN,Eligibility Determination\n"+
+			"100,A111,101001001000,CR-1,Eligible for Dismissal\n"+
+			"100,A111,101001002000,CR-1,Eligible for Reduction\n"+
+			"100,A111,101001003000,,\n"+
+			"200,,101001001000,CR-2,Eligible for Dismissal\n"+
+			"300,A333,101001001000,,Not eligible\n")
+	})
+
+	It("traces granted convictions back to their DOJ identifiers", func() {
+		decisions, err := ReadCourtDecisions(writeFile("decisions.csv", "SUBJECT_ID,CNT_ORDER,DECISION,DECISION_DATE\n"+
+			"100,101001001000,granted,03/04/2020\n"+
+			"100,101001002000,GRANTED,03/04/2020\n"+
+			"300,101001001000,DENIED,\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		updates, rejected, err := BuildDispositionUpdates(resultsPath, nil, decisions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rejected).To(BeEmpty())
+		Expect(updates).To(Equal([]DispositionUpdate{
+			{SubjectID: "100", CII: "A111", CountOrder: "101001001000", OFN: "CR-1", ReliefCode: "D", DecisionDate: time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)},
+			{SubjectID: "100", CII: "A111", CountOrder: "101001002000", OFN: "CR-1", ReliefCode: "R", DecisionDate: time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)},
+		}))
+	})
+
+	It("rejects court decisions that cannot be traced", func() {
+		decisions, err := ReadCourtDecisions(writeFile("decisions.csv", "SUBJECT_ID,CNT_ORDER,DECISION,DECISION_DATE,RELIEF\n"+
+			"999,101001001000,GRANTED,03/04/2020,\n"+
+			"100,101001003000,GRANTED,03/04/2020,\n"+
+			"200,101001001000,GRANTED,03/04/2020,\n"+
+			"300,101001001000,GRANTED,03/04/2020,\n"+
+			"100,101001001000,MAYBE,03/04/2020,\n"+
+			"100,101001001000,GRANTED,March 4,\n"+
+			"100,101001002000,GRANTED,03/04/2020,VACATED\n"+
+			"100,101001002000,GRANTED,03/04/2020,REDUCED\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		updates, rejected, err := BuildDispositionUpdates(resultsPath, nil, decisions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(BeEmpty())
+
+		var reasons []string
+		for _, rejection := range rejected {
+			reasons = append(reasons, rejection.Reason)
+		}
+		Expect(reasons).To(Equal([]string{
+			"no conviction in the results with this SUBJECT_ID and CNT_ORDER",
+			"no conviction in the results with this SUBJECT_ID and CNT_ORDER",
+			"CII_NUMBER is missing in the results",
+			`RELIEF is needed for a conviction determined "Not eligible"`,
+			`decision should be GRANTED or DENIED, got "MAYBE"`,
+			`DECISION_DATE should be a date like 01/31/2020, got "March 4"`,
+			`relief should be DISMISSED or REDUCED, got "VACATED"`,
+			"duplicate decision for this conviction",
+		}))
+		Expect(rejected[0].Decision.Line).To(Equal(2))
+	})
+
+	It("reads encrypted results", func() {
+		contents, err := ioutil.ReadFile(resultsPath)
+		Expect(err).ToNot(HaveOccurred())
+		encryption, err := utilities.NewPassphraseEncryption([]byte("secret passphrase"))
+		Expect(err).ToNot(HaveOccurred())
+		encryptedPath := resultsPath + utilities.EncryptedFileExtension
+		file, err := os.Create(encryptedPath)
+		Expect(err).ToNot(HaveOccurred())
+		writer, err := encryption.NewWriter(file)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = writer.Write(contents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		decisions, err := ReadCourtDecisions(writeFile("decisions.csv", "SUBJECT_ID,CNT_ORDER,DECISION,DECISION_DATE\n"+
+			"100,101001001000,GRANTED,03/04/2020\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = BuildDispositionUpdates(encryptedPath, nil, decisions)
+		Expect(err).To(MatchError(ContainSubstring("is encrypted")))
+
+		updates, rejected, err := BuildDispositionUpdates(encryptedPath, utilities.NewPassphraseDecryption([]byte("secret passphrase")), decisions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rejected).To(BeEmpty())
+		Expect(updates).To(HaveLen(1))
+	})
+
+	It("explains that results need the default output profile columns", func() {
+		profiledPath := writeFile("All_Results_Profiled.csv", "SUBJECT_ID,CII_NUMBER,CNT_ORDER,Determination\n"+
+			"100,A111,101001001000,Eligible for Dismissal\n")
+
+		_, _, err := BuildDispositionUpdates(profiledPath, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring(`missing column "OFN"; disposition-update needs All_Results written without --output-profile`)))
+	})
+
+	It("writes the documented fixed layout", func() {
+		updates := []DispositionUpdate{
+			{SubjectID: "100", CII: "A111", CountOrder: "101001001000", OFN: "CR-1", ReliefCode: "D", DecisionDate: time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)},
+		}
+		updatePath := path.Join(outputDir, "update.txt")
+		Expect(WriteDispositionUpdateFile(utilities.OutputFiles{}, updatePath, updates, time.Date(2020, 3, 5, 0, 0, 0, 0, time.UTC))).To(Succeed())
+
+		contents, err := ioutil.ReadFile(updatePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal(
+			"H2020030500000001\r\n" +
+				"D100       A111      101001001000CR-1                D20200304\r\n" +
+				"T00000001\r\n",
+		))
+	})
+})
@@ -4,7 +4,9 @@ import (
 	"encoding/csv"
 	"fmt"
 	"gogen/data"
-	"os"
+	"gogen/utilities"
+	"io"
+	"strings"
 	"time"
 )
 
@@ -150,26 +152,86 @@ var DojCondensedHeaders = []string{
 }
 
 type DOJWriter interface {
-	WriteEntryWithEligibilityInfo([]string, *data.EligibilityInfo, string)
-	WriteCondensedEntryWithEligibilityInfo([]string, *data.EligibilityInfo, string)
+	WriteEntryWithEligibilityInfo([]string, *data.DOJRow, *data.EligibilityInfo, string)
+	WriteCondensedEntryWithEligibilityInfo([]string, *data.DOJRow, *data.EligibilityInfo, string)
 	Write([]string)
+	Error() error
+	Flush() error
+}
+
+var CourtMatchHeaders = []string{
+	"Court Docket Number",
+	"Court Match Confidence",
+}
+
+type rowWriter interface {
+	Write(record []string) error
 	Flush()
+	Error() error
+}
+
+type WriteError struct {
+	Destination string
+	Err         error
+}
+
+func (e WriteError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Destination, e.Err)
+}
+
+type WriteErrors []WriteError
+
+func (e WriteErrors) Error() string {
+	var messages []string
+	for _, writeError := range e {
+		messages = append(messages, writeError.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func CollectWriteErrors(errs ...error) error {
+	var writeErrors WriteErrors
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+		case WriteError:
+			writeErrors = append(writeErrors, e)
+		case WriteErrors:
+			writeErrors = append(writeErrors, e...)
+		default:
+			writeErrors = append(writeErrors, WriteError{Err: e})
+		}
+	}
+	if len(writeErrors) == 0 {
+		return nil
+	}
+	return writeErrors
 }
 
 type csvWriter struct {
-	outputFileWriter *csv.Writer
-	filename         string
+	outputFileWriter  rowWriter
+	outputFile        io.Closer
+	filename          string
+	courtMatchColumns bool
+	columns           *ColumnSelection
+	err               error
 }
 
-func NewWriter(outputFilePath string, headers []string) (DOJWriter, error) {
-	outputFile, err := os.Create(outputFilePath)
+func NewWriter(files utilities.OutputFiles, outputFilePath string, headers []string) (DOJWriter, error) {
+	return newCSVWriter(files, outputFilePath, headers, false)
+}
+
+func newCSVWriter(files utilities.OutputFiles, outputFilePath string, headers []string, courtMatchColumns bool) (*csvWriter, error) {
+	outputFile, err := files.Create(outputFilePath)
 	if err != nil {
 		return nil, err
 	}
 
 	w := new(csvWriter)
 	w.outputFileWriter = csv.NewWriter(outputFile)
+	w.outputFile = outputFile
 	w.filename = outputFilePath
+	w.courtMatchColumns = courtMatchColumns
 
 	err = w.outputFileWriter.Write(headers)
 	if err != nil {
@@ -179,49 +241,92 @@ func NewWriter(outputFilePath string, headers []string) (DOJWriter, error) {
 	return w, nil
 }
 
-func NewDOJWriter(outputFilePath string) (DOJWriter, error) {
+func resultHeaders(dojHeaders []string, courtMatchColumns bool) []string {
+	headers := append(append([]string{}, dojHeaders...), EligiblityHeaders...)
+	if courtMatchColumns {
+		headers = append(headers, CourtMatchHeaders...)
+	}
+	return headers
+}
+
+func NewDOJWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
 	headers := append(DojFullHeaders, EligiblityHeaders...)
-	return NewWriter(outputFilePath, headers)
+	return NewWriter(files, outputFilePath, headers)
 }
 
-func NewCondensedDOJWriter(outputFilePath string) (DOJWriter, error) {
+func NewCondensedDOJWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
 	headers := append(DojCondensedHeaders, EligiblityHeaders...)
-	return NewWriter(outputFilePath, headers)
-}
-
-func (cw csvWriter) WriteEntryWithEligibilityInfo(entry []string, info *data.EligibilityInfo, possibleOtherP64Charges string) {
-	var eligibilityCols []string
-
-	if info != nil {
-		eligibilityCols = []string{
-			info.CaseNumber,
-			writeInt(info.NumberOfConvictionsOnRecord),
-			possibleOtherP64Charges,
-			info.OccurredAfterEffectiveDate,
-			info.Superstrikes,
-			info.PC290CodeSections,
-			info.PC290Registration,
-			writeDate(info.DateOfConviction),
-			writeFloat(info.YearsSinceThisConviction),
-			writeFloat(info.YearsSinceMostRecentConviction),
-			writeInt(info.NumberOfProp64Convictions),
-			writeInt(info.NumberOf11357Convictions),
-			writeInt(info.NumberOf11358Convictions),
-			writeInt(info.NumberOf11359Convictions),
-			writeInt(info.NumberOf11360Convictions),
-			info.Deceased,
-			info.EligibilityDetermination,
-			info.EligibilityReason,
-		}
-	} else {
-		eligibilityCols = make([]string, len(EligiblityHeaders))
-		eligibilityCols[2] = possibleOtherP64Charges
+	return NewWriter(files, outputFilePath, headers)
+}
+
+func NewCourtMatchedDOJWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	headers := append(append(DojFullHeaders, EligiblityHeaders...), CourtMatchHeaders...)
+	return newCSVWriter(files, outputFilePath, headers, true)
+}
+
+func NewCourtMatchedCondensedDOJWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	headers := append(append(DojCondensedHeaders, EligiblityHeaders...), CourtMatchHeaders...)
+	return newCSVWriter(files, outputFilePath, headers, true)
+}
+
+func NewProfiledDOJWriter(files utilities.OutputFiles, outputFilePath string, columns ColumnSelection) (DOJWriter, error) {
+	w, err := newCSVWriter(files, outputFilePath, columns.Headers, false)
+	if err != nil {
+		return nil, err
+	}
+	w.columns = &columns
+	return w, nil
+}
+
+func (cw *csvWriter) WriteEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	if cw.columns != nil {
+		cw.Write(cw.columns.values(entry, parsedRow, info, possibleOtherP64Charges))
+		return
+	}
+
+	eligibilityCols := eligibilityCols(info, possibleOtherP64Charges)
+	if cw.courtMatchColumns {
+		eligibilityCols = append(eligibilityCols, courtMatchCols(info)...)
 	}
 
 	cw.Write(append(entry, eligibilityCols...))
 }
 
-func (cw csvWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+func eligibilityCols(info *data.EligibilityInfo, possibleOtherP64Charges string) []string {
+	if info == nil {
+		eligibilityCols := make([]string, len(EligiblityHeaders))
+		eligibilityCols[2] = possibleOtherP64Charges
+		return eligibilityCols
+	}
+
+	return []string{
+		info.CaseNumber,
+		writeInt(info.NumberOfConvictionsOnRecord),
+		possibleOtherP64Charges,
+		info.OccurredAfterEffectiveDate,
+		info.Superstrikes,
+		info.PC290CodeSections,
+		info.PC290Registration,
+		writeDate(info.DateOfConviction),
+		writeFloat(info.YearsSinceThisConviction),
+		writeFloat(info.YearsSinceMostRecentConviction),
+		writeInt(info.NumberOfProp64Convictions),
+		writeInt(info.NumberOf11357Convictions),
+		writeInt(info.NumberOf11358Convictions),
+		writeInt(info.NumberOf11359Convictions),
+		writeInt(info.NumberOf11360Convictions),
+		info.Deceased,
+		info.EligibilityDetermination,
+		info.EligibilityReason,
+	}
+}
+
+func (cw *csvWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	if cw.columns != nil {
+		cw.WriteEntryWithEligibilityInfo(entry, parsedRow, info, possibleOtherP64Charges)
+		return
+	}
+
 	var condensedRow []string
 
 	includedColumns := []int{
@@ -251,19 +356,94 @@ func (cw csvWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, info
 		condensedRow = append(condensedRow, entry[col])
 	}
 
-	cw.WriteEntryWithEligibilityInfo(condensedRow, info, possibleOtherP64Charges)
+	cw.WriteEntryWithEligibilityInfo(condensedRow, parsedRow, info, possibleOtherP64Charges)
+}
+
+func courtMatchCols(info *data.EligibilityInfo) []string {
+	if info == nil || info.CourtDocketNumber == "" {
+		return make([]string, len(CourtMatchHeaders))
+	}
+	return []string{info.CourtDocketNumber, fmt.Sprintf("%.2f", info.CourtMatchConfidence)}
+}
+
+type multiDOJWriter []DOJWriter
+
+func NewMultiDOJWriter(writers ...DOJWriter) DOJWriter {
+	if len(writers) == 1 {
+		return writers[0]
+	}
+	return multiDOJWriter(writers)
+}
+
+func (mw multiDOJWriter) WriteEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	for _, writer := range mw {
+		writer.WriteEntryWithEligibilityInfo(entry, parsedRow, info, possibleOtherP64Charges)
+	}
+}
+
+func (mw multiDOJWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	for _, writer := range mw {
+		writer.WriteCondensedEntryWithEligibilityInfo(entry, parsedRow, info, possibleOtherP64Charges)
+	}
+}
+
+func (mw multiDOJWriter) Write(record []string) {
+	for _, writer := range mw {
+		writer.Write(record)
+	}
+}
+
+func (mw multiDOJWriter) Error() error {
+	for _, writer := range mw {
+		if err := writer.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mw multiDOJWriter) Flush() error {
+	var errs []error
+	for _, writer := range mw {
+		errs = append(errs, writer.Flush())
+	}
+	return CollectWriteErrors(errs...)
 }
 
 func writeDate(val time.Time) string {
 	return val.Format("01/02/2006")
 }
 
-func (cw csvWriter) Flush() {
+func (cw *csvWriter) Flush() error {
 	cw.outputFileWriter.Flush()
+	err := cw.err
+	if err == nil {
+		err = cw.outputFileWriter.Error()
+	}
+	if cw.outputFile != nil {
+		closeErr := cw.outputFile.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return WriteError{Destination: cw.filename, Err: err}
+	}
+	return nil
 }
 
-func (cw csvWriter) Write(line []string) {
-	_ = cw.outputFileWriter.Write(line)
+// After the first failed write the rest are dropped; Error and Flush report it.
+func (cw *csvWriter) Write(line []string) {
+	if cw.err == nil {
+		cw.err = cw.outputFileWriter.Write(line)
+	}
+}
+
+func (cw *csvWriter) Error() error {
+	if cw.err != nil {
+		return WriteError{Destination: cw.filename, Err: cw.err}
+	}
+	return nil
 }
 
 func writeFloat(val float64) string {
@@ -0,0 +1,119 @@
+package exporter
+
+import (
+	"encoding/json"
+	"gogen/data"
+	"strings"
+)
+
+const unknownEquityGroup = "UNKNOWN"
+
+type EquityBreakdowns struct {
+	MinimumCellSize int                       `json:"minimumCellSize"`
+	ByRace          map[string]map[string]int `json:"byRace"`
+	ByGender        map[string]map[string]int `json:"byGender"`
+	ByAgeBand       map[string]map[string]int `json:"byAgeBand"`
+}
+
+func NewEquityBreakdowns(dojInformation *data.DOJInformation, county string, eligibilities map[int]*data.EligibilityInfo, minimumCellSize int) *EquityBreakdowns {
+	computeAt := dojInformation.ComparisonTime()
+	calendar := dojInformation.Calendar()
+	return &EquityBreakdowns{
+		MinimumCellSize: minimumCellSize,
+		ByRace: dojInformation.Prop64ReliefByGroup(county, eligibilities, func(subject *data.Subject) string {
+			return equityGroup(subject.Race)
+		}),
+		ByGender: dojInformation.Prop64ReliefByGroup(county, eligibilities, func(subject *data.Subject) string {
+			return equityGroup(subject.Gender)
+		}),
+		ByAgeBand: dojInformation.Prop64ReliefByGroup(county, eligibilities, func(subject *data.Subject) string {
+			if subject.DOB.IsZero() {
+				return unknownEquityGroup
+			}
+			return ageBand(calendar, subject.DOB, computeAt)
+		}),
+	}
+}
+
+func (e EquityBreakdowns) MarshalJSON() ([]byte, error) {
+	type equityBreakdowns EquityBreakdowns
+	return json.Marshal(equityBreakdowns{
+		MinimumCellSize: e.MinimumCellSize,
+		ByRace:          suppressSmallCells(e.ByRace, e.MinimumCellSize),
+		ByGender:        suppressSmallCells(e.ByGender, e.MinimumCellSize),
+		ByAgeBand:       suppressSmallCells(e.ByAgeBand, e.MinimumCellSize),
+	})
+}
+
+func mergeEquityBreakdowns(runBreakdowns *EquityBreakdowns, fileBreakdowns *EquityBreakdowns) *EquityBreakdowns {
+	if runBreakdowns == nil {
+		return fileBreakdowns
+	}
+	if fileBreakdowns == nil {
+		return runBreakdowns
+	}
+	return &EquityBreakdowns{
+		MinimumCellSize: fileBreakdowns.MinimumCellSize,
+		ByRace:          addNestedMaps(runBreakdowns.ByRace, fileBreakdowns.ByRace),
+		ByGender:        addNestedMaps(runBreakdowns.ByGender, fileBreakdowns.ByGender),
+		ByAgeBand:       addNestedMaps(runBreakdowns.ByAgeBand, fileBreakdowns.ByAgeBand),
+	}
+}
+
+// Cells below the minimum size are left out. When a count has only one such
+// cell it could be worked out from the totals, so its next smallest cell goes too,
+// or the whole count when every other cell is zero.
+func suppressSmallCells(groupCounts map[string]map[string]int, minimumCellSize int) map[string]map[string]int {
+	suppressed := make(map[string]map[string]int)
+	metrics := make(map[string]bool)
+	for group, counts := range groupCounts {
+		suppressed[group] = make(map[string]int)
+		for metric, count := range counts {
+			metrics[metric] = true
+			if count == 0 || count >= minimumCellSize {
+				suppressed[group][metric] = count
+			}
+		}
+	}
+
+	for metric := range metrics {
+		suppressedCells := 0
+		smallestGroup := ""
+		for group, counts := range groupCounts {
+			count, ok := counts[metric]
+			if !ok {
+				continue
+			}
+			if _, kept := suppressed[group][metric]; !kept {
+				suppressedCells++
+				continue
+			}
+			if count == 0 {
+				continue
+			}
+			smallest := groupCounts[smallestGroup][metric]
+			if smallestGroup == "" || count < smallest || (count == smallest && group < smallestGroup) {
+				smallestGroup = group
+			}
+		}
+		if suppressedCells != 1 {
+			continue
+		}
+		if smallestGroup != "" {
+			delete(suppressed[smallestGroup], metric)
+			continue
+		}
+		for group := range suppressed {
+			delete(suppressed[group], metric)
+		}
+	}
+	return suppressed
+}
+
+func equityGroup(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return unknownEquityGroup
+	}
+	return value
+}
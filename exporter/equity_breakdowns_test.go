@@ -0,0 +1,74 @@
+package exporter_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen/exporter"
+)
+
+var _ = Describe("EquityBreakdowns", func() {
+	marshalled := func(breakdowns EquityBreakdowns) EquityBreakdowns {
+		breakdownsBytes, err := json.Marshal(breakdowns)
+		Expect(err).ToNot(HaveOccurred())
+		var result EquityBreakdowns
+		Expect(json.Unmarshal(breakdownsBytes, &result)).To(Succeed())
+		return result
+	}
+
+	It("leaves out counts below the minimum cell size, and the next smallest count when only one is left out", func() {
+		breakdowns := marshalled(EquityBreakdowns{
+			MinimumCellSize: 11,
+			ByRace: map[string]map[string]int{
+				"BLACK":    {"Prop64Convictions": 40, "CountSubjectsNoFelony": 0},
+				"HISPANIC": {"Prop64Convictions": 25, "CountSubjectsNoFelony": 12},
+				"WHITE":    {"Prop64Convictions": 30, "CountSubjectsNoFelony": 3},
+				"OTHER":    {"Prop64Convictions": 4, "CountSubjectsNoFelony": 7},
+			},
+		})
+
+		Expect(breakdowns.MinimumCellSize).To(Equal(11))
+		Expect(breakdowns.ByRace).To(Equal(map[string]map[string]int{
+			"BLACK":    {"Prop64Convictions": 40, "CountSubjectsNoFelony": 0},
+			"HISPANIC": {"CountSubjectsNoFelony": 12},
+			"WHITE":    {"Prop64Convictions": 30},
+			"OTHER":    {},
+		}))
+	})
+
+	It("leaves out the whole count when the only other cells are zero", func() {
+		breakdowns := marshalled(EquityBreakdowns{
+			MinimumCellSize: 11,
+			ByGender: map[string]map[string]int{
+				"FEMALE": {"Prop64Convictions": 0, "EligibleConvictions": 15},
+				"MALE":   {"Prop64Convictions": 4, "EligibleConvictions": 20},
+			},
+		})
+
+		Expect(breakdowns.ByGender).To(Equal(map[string]map[string]int{
+			"FEMALE": {"EligibleConvictions": 15},
+			"MALE":   {"EligibleConvictions": 20},
+		}))
+	})
+
+	It("adds up the breakdowns of each input file", func() {
+		var d DataExporter
+		first := Summary{EquityBreakdowns: &EquityBreakdowns{
+			MinimumCellSize: 11,
+			ByGender:        map[string]map[string]int{"FEMALE": {"Prop64Convictions": 6}},
+		}}
+		second := Summary{EquityBreakdowns: &EquityBreakdowns{
+			MinimumCellSize: 11,
+			ByGender:        map[string]map[string]int{"FEMALE": {"Prop64Convictions": 7}, "MALE": {"Prop64Convictions": 20}},
+		}}
+
+		summary := d.AccumulateSummaryData(d.AccumulateSummaryData(Summary{}, first), second)
+		Expect(summary.EquityBreakdowns.ByGender).To(Equal(map[string]map[string]int{
+			"FEMALE": {"Prop64Convictions": 13},
+			"MALE":   {"Prop64Convictions": 20},
+		}))
+		Expect(first.EquityBreakdowns.ByGender["FEMALE"]["Prop64Convictions"]).To(Equal(6))
+		Expect(d.AccumulateSummaryData(Summary{}, Summary{}).EquityBreakdowns).To(BeNil())
+	})
+})
@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"fmt"
+	"gogen/data"
+	"gogen/utilities"
+)
+
+var IdentityLinkHeaders = []string{
+	"MERGED_SUBJECT_ID",
+	"SUBJECT_ID",
+	"PRI_NAME",
+	"PRI_DOB",
+	"CII_NUMBER",
+	"FBI_NUMBER",
+	"LINKED_SUBJECT_ID",
+	"LINKED_PRI_NAME",
+	"LINKED_PRI_DOB",
+	"LINKED_CII_NUMBER",
+	"LINKED_FBI_NUMBER",
+	"MATCHED_ON",
+	"NAME_SIMILARITY",
+}
+
+func NewIdentityLinkWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	return NewWriter(files, outputFilePath, IdentityLinkHeaders)
+}
+
+func ExportIdentityLinks(links []data.IdentityLink, outputIdentityLinkWriter DOJWriter) error {
+	for _, link := range links {
+		outputIdentityLinkWriter.Write([]string{
+			link.MergedSubjectID,
+			link.Subject.ID,
+			link.Subject.Name,
+			writeDate(link.Subject.DOB),
+			link.Subject.CII,
+			link.Subject.FBINumber,
+			link.LinkedSubject.ID,
+			link.LinkedSubject.Name,
+			writeDate(link.LinkedSubject.DOB),
+			link.LinkedSubject.CII,
+			link.LinkedSubject.FBINumber,
+			link.MatchedOn,
+			fmt.Sprintf("%.2f", link.NameSimilarity),
+		})
+	}
+	return outputIdentityLinkWriter.Flush()
+}
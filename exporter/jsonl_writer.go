@@ -0,0 +1,217 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"gogen/data"
+	"gogen/utilities"
+	"io"
+	"strings"
+	"time"
+)
+
+const JSONLinesSchemaVersion = 1
+
+type JSONLinesRow struct {
+	SchemaVersion int                   `json:"schemaVersion"`
+	DOJ           map[string]string     `json:"doj"`
+	Conviction    JSONLinesConviction   `json:"conviction"`
+	Eligibility   *JSONLinesEligibility `json:"eligibility"`
+}
+
+type JSONLinesConviction struct {
+	SubjectID                     string  `json:"subjectId"`
+	CodeSection                   string  `json:"codeSection"`
+	WasConvicted                  bool    `json:"wasConvicted"`
+	IsFelony                      bool    `json:"isFelony"`
+	FelonyStatusUnknown           bool    `json:"felonyStatusUnknown"`
+	DispositionDate               *string `json:"dispositionDate"`
+	SentenceEndDate               *string `json:"sentenceEndDate"`
+	County                        string  `json:"county"`
+	PossibleProp64ChargeInComment string  `json:"possibleProp64ChargeInComment"`
+}
+
+type JSONLinesEligibility struct {
+	CaseNumber                     string   `json:"caseNumber"`
+	NumberOfConvictionsOnRecord    int      `json:"numberOfConvictionsOnRecord"`
+	OccurredAfterEffectiveDate     string   `json:"occurredAfterEffectiveDate"`
+	Superstrikes                   string   `json:"superstrikes"`
+	PC290CodeSections              string   `json:"pc290CodeSections"`
+	PC290Registration              string   `json:"pc290Registration"`
+	DateOfConviction               *string  `json:"dateOfConviction"`
+	YearsSinceThisConviction       float64  `json:"yearsSinceThisConviction"`
+	YearsSinceMostRecentConviction float64  `json:"yearsSinceMostRecentConviction"`
+	NumberOfProp64Convictions      int      `json:"numberOfProp64Convictions"`
+	NumberOf11357Convictions       int      `json:"numberOf11357Convictions"`
+	NumberOf11358Convictions       int      `json:"numberOf11358Convictions"`
+	NumberOf11359Convictions       int      `json:"numberOf11359Convictions"`
+	NumberOf11360Convictions       int      `json:"numberOf11360Convictions"`
+	Deceased                       string   `json:"deceased"`
+	Determination                  string   `json:"determination"`
+	Reason                         string   `json:"reason"`
+	CourtDocketNumber              string   `json:"courtDocketNumber,omitempty"`
+	CourtMatchConfidence           *float64 `json:"courtMatchConfidence,omitempty"`
+}
+
+type jsonLinesRecord struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Fields        map[string]string `json:"fields"`
+}
+
+type jsonLinesWriter struct {
+	filename   string
+	encoder    *json.Encoder
+	buffer     *bufio.Writer
+	outputFile io.Closer
+	headers    []string
+	dojHeaders []string
+	redactor   *Redactor
+	err        error
+}
+
+func NewJSONLinesWriter(files utilities.OutputFiles, outputFilePath string, headers []string) (DOJWriter, error) {
+	return newJSONLinesWriter(files, outputFilePath, headers, nil)
+}
+
+func NewJSONLinesDOJWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	return newJSONLinesWriter(files, outputFilePath, nil, DojFullHeaders)
+}
+
+func NewCondensedJSONLinesDOJWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	return newJSONLinesWriter(files, outputFilePath, nil, DojCondensedHeaders)
+}
+
+func newJSONLinesWriter(files utilities.OutputFiles, outputFilePath string, headers []string, dojHeaders []string) (*jsonLinesWriter, error) {
+	outputFile, err := files.Create(outputFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bufio.NewWriter(outputFile)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	return &jsonLinesWriter{
+		filename:   outputFilePath,
+		encoder:    encoder,
+		buffer:     buffer,
+		outputFile: outputFile,
+		headers:    headers,
+		dojHeaders: dojHeaders,
+	}, nil
+}
+
+func NewJSONLinesRow(entry []string, row *data.DOJRow, dojHeaders []string, info *data.EligibilityInfo, possibleOtherP64Charges string, redactor *Redactor) JSONLinesRow {
+	dojFields := make(map[string]string)
+	for _, header := range dojHeaders {
+		dojFields[header] = strings.TrimSpace(entry[dojColumnIndex[header]])
+	}
+
+	return JSONLinesRow{
+		SchemaVersion: JSONLinesSchemaVersion,
+		DOJ:           dojFields,
+		Conviction: JSONLinesConviction{
+			SubjectID:                     redactor.RedactValue("SUBJECT_ID", row.SubjectID),
+			CodeSection:                   row.CodeSection,
+			WasConvicted:                  row.WasConvicted,
+			IsFelony:                      row.IsFelony,
+			FelonyStatusUnknown:           row.FelonyStatusUnknown,
+			DispositionDate:               jsonDate(row.DispositionDate),
+			SentenceEndDate:               jsonDate(row.SentenceEndDate),
+			County:                        row.County,
+			PossibleProp64ChargeInComment: possibleOtherP64Charges,
+		},
+		Eligibility: jsonLinesEligibility(info),
+	}
+}
+
+func jsonLinesEligibility(info *data.EligibilityInfo) *JSONLinesEligibility {
+	if info == nil {
+		return nil
+	}
+
+	eligibility := &JSONLinesEligibility{
+		CaseNumber:                     info.CaseNumber,
+		NumberOfConvictionsOnRecord:    info.NumberOfConvictionsOnRecord,
+		OccurredAfterEffectiveDate:     info.OccurredAfterEffectiveDate,
+		Superstrikes:                   info.Superstrikes,
+		PC290CodeSections:              info.PC290CodeSections,
+		PC290Registration:              info.PC290Registration,
+		DateOfConviction:               jsonDate(info.DateOfConviction),
+		YearsSinceThisConviction:       info.YearsSinceThisConviction,
+		YearsSinceMostRecentConviction: info.YearsSinceMostRecentConviction,
+		NumberOfProp64Convictions:      info.NumberOfProp64Convictions,
+		NumberOf11357Convictions:       info.NumberOf11357Convictions,
+		NumberOf11358Convictions:       info.NumberOf11358Convictions,
+		NumberOf11359Convictions:       info.NumberOf11359Convictions,
+		NumberOf11360Convictions:       info.NumberOf11360Convictions,
+		Deceased:                       info.Deceased,
+		Determination:                  info.EligibilityDetermination,
+		Reason:                         info.EligibilityReason,
+	}
+	if info.CourtDocketNumber != "" {
+		confidence := info.CourtMatchConfidence
+		eligibility.CourtDocketNumber = info.CourtDocketNumber
+		eligibility.CourtMatchConfidence = &confidence
+	}
+	return eligibility
+}
+
+func (w *jsonLinesWriter) WriteEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	w.encode(NewJSONLinesRow(entry, parsedRow, w.dojHeaders, info, possibleOtherP64Charges, w.redactor))
+}
+
+func (w *jsonLinesWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	w.encode(NewJSONLinesRow(entry, parsedRow, w.dojHeaders, info, possibleOtherP64Charges, w.redactor))
+}
+
+func (w *jsonLinesWriter) Write(record []string) {
+	fields := make(map[string]string)
+	for index, value := range record {
+		if index < len(w.headers) {
+			fields[w.headers[index]] = value
+		}
+	}
+	w.encode(jsonLinesRecord{SchemaVersion: JSONLinesSchemaVersion, Fields: fields})
+}
+
+func (w *jsonLinesWriter) Error() error {
+	if w.err != nil {
+		return WriteError{Destination: w.filename, Err: w.err}
+	}
+	return nil
+}
+
+func (w *jsonLinesWriter) Flush() error {
+	if w.err == nil {
+		w.err = w.buffer.Flush()
+	}
+	if w.err == nil {
+		w.err = w.outputFile.Close()
+	}
+	if w.err != nil {
+		return WriteError{Destination: w.filename, Err: w.err}
+	}
+	return nil
+}
+
+func (w *jsonLinesWriter) encode(value interface{}) {
+	if w.err == nil {
+		w.err = w.encoder.Encode(value)
+	}
+}
+
+func jsonDate(val time.Time) *string {
+	if val.IsZero() {
+		return nil
+	}
+	date := val.Format("2006-01-02")
+	return &date
+}
+
+var dojColumnIndex = func() map[string]int {
+	columns := make(map[string]int)
+	for index, header := range DojFullHeaders {
+		columns[header] = index
+	}
+	return columns
+}()
@@ -0,0 +1,130 @@
+package exporter_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen/data"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("jsonLinesWriter", func() {
+	var (
+		outputDir string
+		entry     []string
+	)
+
+	readLines := func(name string) []map[string]interface{} {
+		file, err := os.Open(path.Join(outputDir, name))
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		var lines []map[string]interface{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var line map[string]interface{}
+			Expect(json.Unmarshal(scanner.Bytes(), &line)).To(Succeed())
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		entry = make([]string, len(DojFullHeaders))
+		entry[data.SUBJECT_ID] = "100"
+		entry[data.CII_NUMBER] = "0123456789 "
+		entry[data.PRI_NAME] = "SKYWALKER,LUKE"
+		entry[data.PRI_DOB] = "19800302"
+		entry[data.STP_EVENT_DATE] = "20010504"
+		entry[data.DISP_DESCR] = "CONVICTED"
+		entry[data.OFFENSE_DESCR] = "11357(C) HS-POSSESS MARIJUANA"
+		entry[data.CONV_STAT_DESCR] = "FELONY"
+	})
+
+	It("writes the DOJ fields, conviction facts and eligibility of each row", func() {
+		writer, err := NewJSONLinesDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "All_Results.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+
+		info := &data.EligibilityInfo{CaseNumber: "CR-2", DateOfConviction: time.Date(2001, time.May, 4, 0, 0, 0, 0, time.UTC)}
+		info.SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		parsedRow.SentenceEndDate = time.Date(2003, time.May, 4, 0, 0, 0, 0, time.UTC)
+		writer.WriteEntryWithEligibilityInfo(entry, &parsedRow, info, "")
+		writer.WriteEntryWithEligibilityInfo(entry, &parsedRow, nil, "")
+		writer.Flush()
+
+		lines := readLines("All_Results.jsonl")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]["schemaVersion"]).To(Equal(float64(JSONLinesSchemaVersion)))
+
+		doj := lines[0]["doj"].(map[string]interface{})
+		Expect(doj).To(HaveLen(len(DojFullHeaders)))
+		Expect(doj["CII_NUMBER"]).To(Equal("0123456789"))
+
+		conviction := lines[0]["conviction"].(map[string]interface{})
+		Expect(conviction["subjectId"]).To(Equal("100"))
+		Expect(conviction["codeSection"]).To(Equal("11357(C) HS"))
+		Expect(conviction["isFelony"]).To(BeTrue())
+		Expect(conviction["dispositionDate"]).To(Equal("2001-05-04"))
+		Expect(conviction["sentenceEndDate"]).To(Equal("2003-05-04"))
+
+		eligibility := lines[0]["eligibility"].(map[string]interface{})
+		Expect(eligibility["caseNumber"]).To(Equal("CR-2"))
+		Expect(eligibility["dateOfConviction"]).To(Equal("2001-05-04"))
+		Expect(eligibility["determination"]).To(Equal("Eligible for Dismissal"))
+		Expect(eligibility).ToNot(HaveKey("courtDocketNumber"))
+
+		Expect(lines[1]["eligibility"]).To(BeNil())
+	})
+
+	It("only writes the condensed DOJ fields for condensed entries", func() {
+		writer, err := NewCondensedJSONLinesDOJWriter(utilities.OutputFiles{}, path.Join(outputDir, "All_Results_Condensed.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		writer.WriteCondensedEntryWithEligibilityInfo(entry, &parsedRow, nil, "")
+		writer.Flush()
+
+		doj := readLines("All_Results_Condensed.jsonl")[0]["doj"].(map[string]interface{})
+		Expect(doj).To(HaveLen(len(DojCondensedHeaders)))
+		Expect(doj).ToNot(HaveKey("SUBJECT_ID"))
+	})
+
+	It("pseudonymizes the conviction subject when the sink redacts", func() {
+		redactor, err := NewRedactor([]byte("secret"), "year", time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC), data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+		sink, err := NewSink("jsonl", SinkOptions{OutputFolder: outputDir, FileIndex: 1, FileCount: 1, Redactor: redactor})
+		Expect(err).ToNot(HaveOccurred())
+
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		writer := RedactWriters(sink.Writers(), redactor).DOJ
+		writer.WriteEntryWithEligibilityInfo(entry, &parsedRow, nil, "")
+		Expect(writer.Flush()).To(Succeed())
+
+		lines := readLines("All_Results.jsonl")
+		Expect(lines[0]["doj"].(map[string]interface{})["SUBJECT_ID"]).To(Equal(redactor.Pseudonym("subject", "100")))
+		Expect(lines[0]["conviction"].(map[string]interface{})["subjectId"]).To(Equal(redactor.Pseudonym("subject", "100")))
+	})
+
+	It("writes other records as named fields", func() {
+		writer, err := NewJSONLinesWriter(utilities.OutputFiles{}, path.Join(outputDir, "Subjects_Results.jsonl"), []string{"SUBJECT_ID", "PRI_NAME"})
+		Expect(err).ToNot(HaveOccurred())
+
+		writer.Write([]string{"100", "SKYWALKER,LUKE"})
+		writer.Flush()
+
+		lines := readLines("Subjects_Results.jsonl")
+		Expect(lines[0]["fields"]).To(Equal(map[string]interface{}{"SUBJECT_ID": "100", "PRI_NAME": "SKYWALKER,LUKE"}))
+	})
+})
@@ -0,0 +1,154 @@
+package exporter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gogen/data"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Manifest struct {
+	GogenVersion       string                   `json:"gogenVersion"`
+	Options            map[string]interface{}   `json:"options"`
+	EligibilityOptions *data.EligibilityOptions `json:"eligibilityOptions,omitempty"`
+	County             string                   `json:"county"`
+	ComputeAt          string                   `json:"computeAt"`
+	StartedAt          time.Time                `json:"startedAt"`
+	FinishedAt         time.Time                `json:"finishedAt"`
+	Inputs             []ManifestFile           `json:"inputs"`
+	Outputs            []ManifestFile           `json:"outputs"`
+}
+
+type ManifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Bytes  int64  `json:"bytes"`
+	Rows   *int   `json:"rows,omitempty"`
+}
+
+type ManifestProblem struct {
+	Path    string
+	Problem string
+}
+
+func NewManifestFile(filePath string, baseFolder string, rows *int) (ManifestFile, error) {
+	hash, size, err := hashFile(filePath)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+
+	manifestPath := filePath
+	if baseFolder != "" {
+		relativePath, err := filepath.Rel(baseFolder, filePath)
+		if err == nil {
+			manifestPath = filepath.ToSlash(relativePath)
+		}
+	}
+	return ManifestFile{Path: manifestPath, SHA256: hash, Bytes: size, Rows: rows}, nil
+}
+
+func NewCountedManifestFile(filePath string, baseFolder string) (ManifestFile, error) {
+	rows, err := CountRows(filePath)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	return NewManifestFile(filePath, baseFolder, rows)
+}
+
+func CountRows(filePath string) (*int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows := 0
+	switch filepath.Ext(filePath) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", filePath, err)
+			}
+			rows++
+		}
+		if rows > 0 {
+			rows--
+		}
+	case ".jsonl":
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			rows++
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %s", filePath, err)
+		}
+	default:
+		return nil, nil
+	}
+	return &rows, nil
+}
+
+func ReadManifest(summaryBytes []byte) (Manifest, error) {
+	var summary struct {
+		Manifest *Manifest `json:"manifest"`
+	}
+	err := json.Unmarshal(summaryBytes, &summary)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if summary.Manifest == nil {
+		return Manifest{}, errors.New("summary has no manifest: it was written by a gogen version before manifests were added")
+	}
+	return *summary.Manifest, nil
+}
+
+func VerifyManifest(manifest Manifest, baseFolder string) []ManifestProblem {
+	var problems []ManifestProblem
+	for _, output := range manifest.Outputs {
+		filePath := filepath.FromSlash(output.Path)
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(baseFolder, filePath)
+		}
+
+		hash, size, err := hashFile(filePath)
+		switch {
+		case os.IsNotExist(err):
+			problems = append(problems, ManifestProblem{Path: output.Path, Problem: "missing"})
+		case err != nil:
+			problems = append(problems, ManifestProblem{Path: output.Path, Problem: err.Error()})
+		case hash != output.SHA256:
+			problems = append(problems, ManifestProblem{Path: output.Path, Problem: fmt.Sprintf("SHA-256 is %s, expected %s (%d bytes, expected %d)", hash, output.SHA256, size, output.Bytes)})
+		}
+	}
+	return problems
+}
+
+func hashFile(filePath string) (string, int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
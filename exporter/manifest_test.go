@@ -0,0 +1,78 @@
+package exporter_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen/exporter"
+)
+
+var _ = Describe("Manifest", func() {
+	var outputDir string
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ioutil.WriteFile(path.Join(outputDir, "results.csv"), []byte("A,B\n1,2\n3,\"4\n5\"\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(outputDir, "results.jsonl"), []byte("{}\n{}\n{}\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(path.Join(outputDir, "results.txt"), []byte("hello"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	It("records the SHA-256, size and row count of a file relative to the output folder", func() {
+		csvFile, err := NewCountedManifestFile(path.Join(outputDir, "results.csv"), outputDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csvFile.Path).To(Equal("results.csv"))
+		Expect(csvFile.Bytes).To(Equal(int64(16)))
+		Expect(*csvFile.Rows).To(Equal(2))
+
+		jsonlFile, err := NewCountedManifestFile(path.Join(outputDir, "results.jsonl"), outputDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*jsonlFile.Rows).To(Equal(3))
+
+		textFile, err := NewCountedManifestFile(path.Join(outputDir, "results.txt"), outputDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(textFile.SHA256).To(Equal("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"))
+		Expect(textFile.Rows).To(BeNil())
+	})
+
+	It("reports outputs that changed or are missing", func() {
+		var manifest Manifest
+		for _, name := range []string{"results.csv", "results.jsonl", "results.txt"} {
+			file, err := NewCountedManifestFile(path.Join(outputDir, name), outputDir)
+			Expect(err).ToNot(HaveOccurred())
+			manifest.Outputs = append(manifest.Outputs, file)
+		}
+		Expect(VerifyManifest(manifest, outputDir)).To(BeEmpty())
+
+		Expect(ioutil.WriteFile(path.Join(outputDir, "results.txt"), []byte("hullo"), 0644)).To(Succeed())
+		Expect(os.Remove(path.Join(outputDir, "results.jsonl"))).To(Succeed())
+
+		problems := VerifyManifest(manifest, outputDir)
+		Expect(problems).To(HaveLen(2))
+		Expect(problems[0]).To(Equal(ManifestProblem{Path: "results.jsonl", Problem: "missing"}))
+		Expect(problems[1].Path).To(Equal("results.txt"))
+		Expect(problems[1].Problem).To(ContainSubstring("SHA-256 is"))
+	})
+
+	It("reads the manifest from a summary", func() {
+		summaryBytes, err := json.Marshal(Summary{County: "SAN JOAQUIN", Manifest: &Manifest{County: "SAN JOAQUIN", ComputeAt: "2019-11-11"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := ReadManifest(summaryBytes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.ComputeAt).To(Equal("2019-11-11"))
+
+		_, err = ReadManifest([]byte(`{"county": "SAN JOAQUIN"}`))
+		Expect(err).To(MatchError(ContainSubstring("summary has no manifest")))
+	})
+})
@@ -0,0 +1,262 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"gogen/data"
+	"gogen/matchers"
+	"gogen/utilities"
+	htmlTemplate "html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	textTemplate "text/template"
+)
+
+const ReliefAuthority = "Health and Safety Code section 11361.8"
+
+var OrderFormatExtensions = map[string]string{
+	"text": "txt",
+	"html": "html",
+	"csv":  "csv",
+}
+
+var defaultOrderTemplates = map[string]string{
+	"text": `{{range .Orders}}SUPERIOR COURT OF CALIFORNIA, COUNTY OF {{$.County}}
+Case Number: {{.CaseNumber}}
+
+Defendant: {{.Defendant.Name}}
+Date of Birth: {{.Defendant.DOB}}
+CII Number: {{.Defendant.CII}}
+
+Pursuant to {{$.Authority}}, the court orders the following counts:
+{{range .Counts}}  - {{.Citation}}, convicted {{.DispositionDate}}: {{.Relief}}
+{{end}}
+{{end}}`,
+	"html": `<html>
+<body>
+{{range .Orders}}<section>
+<h1>Superior Court of California, County of {{$.County}}</h1>
+<p>Case Number: {{.CaseNumber}}</p>
+<p>Defendant: {{.Defendant.Name}}<br>Date of Birth: {{.Defendant.DOB}}<br>CII Number: {{.Defendant.CII}}</p>
+<p>Pursuant to {{$.Authority}}, the court orders the following counts:</p>
+<ul>
+{{range .Counts}}<li>{{.Citation}}, convicted {{.DispositionDate}}: {{.Relief}}</li>
+{{end}}</ul>
+</section>
+{{end}}</body>
+</html>
+`,
+	"csv": `{{csv "CASE_NUMBER" "SUBJECT_ID" "PRI_NAME" "PRI_DOB" "CII_NUMBER" "CITATION" "DISP_DATE" "RELIEF" "AUTHORITY"}}{{range $order := .Orders}}{{range .Counts}}{{csv $order.CaseNumber $order.Defendant.SubjectID $order.Defendant.Name $order.Defendant.DOB $order.Defendant.CII .Citation .DispositionDate .Relief $.Authority}}{{end}}{{end}}`,
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+type OrderDefendant struct {
+	SubjectID string
+	Name      string
+	DOB       string
+	CII       string
+}
+
+type OrderCount struct {
+	CodeSection     string
+	Citation        string
+	DispositionDate string
+	Relief          string
+	Reason          string
+}
+
+type Order struct {
+	CaseNumber string
+	Defendant  OrderDefendant
+	Counts     []OrderCount
+}
+
+type OrderDocument struct {
+	Number    int
+	County    string
+	Authority string
+	Orders    []Order
+}
+
+type orderTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+type OrderExporter struct {
+	files     utilities.OutputFiles
+	format    string
+	batchSize int
+	template  orderTemplate
+}
+
+func NewOrderExporter(files utilities.OutputFiles, templatePath string, format string, batchSize int) (OrderExporter, error) {
+	if _, ok := OrderFormatExtensions[format]; !ok {
+		return OrderExporter{}, fmt.Errorf("order format should be one of text, html or csv, got %q", format)
+	}
+	if batchSize < 0 {
+		return OrderExporter{}, fmt.Errorf("order batch size should not be negative, got %d", batchSize)
+	}
+
+	templateText := defaultOrderTemplates[format]
+	if templatePath != "" {
+		templateBytes, err := ioutil.ReadFile(templatePath)
+		if err != nil {
+			return OrderExporter{}, err
+		}
+		templateText = string(templateBytes)
+	}
+
+	var parsedTemplate orderTemplate
+	var err error
+	if format == "html" {
+		parsedTemplate, err = htmlTemplate.New("order").Funcs(htmlTemplate.FuncMap{"csv": csvRecord}).Parse(templateText)
+	} else {
+		parsedTemplate, err = textTemplate.New("order").Funcs(textTemplate.FuncMap{"csv": csvRecord}).Parse(templateText)
+	}
+	if err != nil {
+		return OrderExporter{}, err
+	}
+
+	return OrderExporter{files: files, format: format, batchSize: batchSize, template: parsedTemplate}, nil
+}
+
+func GroupOrders(dojInformation *data.DOJInformation, eligibilities map[int]*data.EligibilityInfo) []Order {
+	ordersByKey := make(map[string]*Order)
+	for _, subject := range dojInformation.Subjects {
+		for _, conviction := range subject.Convictions {
+			info := eligibilities[conviction.Index]
+			if info == nil {
+				continue
+			}
+			relief := orderRelief(info)
+			if relief == "" {
+				continue
+			}
+
+			caseNumber := info.CaseNumber
+			if caseNumber == "" {
+				caseNumber = strings.TrimSpace(conviction.OFN)
+			}
+			key := conviction.SubjectID + ":" + caseNumber
+			if ordersByKey[key] == nil {
+				ordersByKey[key] = &Order{
+					CaseNumber: caseNumber,
+					Defendant: OrderDefendant{
+						SubjectID: conviction.SubjectID,
+						Name:      conviction.Name,
+						DOB:       writeDate(conviction.DOB),
+						CII:       conviction.CII,
+					},
+				}
+			}
+			ordersByKey[key].Counts = append(ordersByKey[key].Counts, OrderCount{
+				CodeSection:     conviction.CodeSection,
+				Citation:        statuteCitation(conviction.CodeSection),
+				DispositionDate: writeDate(conviction.DispositionDate),
+				Relief:          relief,
+				Reason:          info.EligibilityReason,
+			})
+		}
+	}
+
+	var orders []Order
+	for _, order := range ordersByKey {
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(a, b int) bool {
+		if orders[a].CaseNumber != orders[b].CaseNumber {
+			return orders[a].CaseNumber < orders[b].CaseNumber
+		}
+		return orders[a].Defendant.SubjectID < orders[b].Defendant.SubjectID
+	})
+	return orders
+}
+
+func (e OrderExporter) Export(dojInformation *data.DOJInformation, eligibilities map[int]*data.EligibilityInfo, county string, outputFolder string) error {
+	err := os.MkdirAll(outputFolder, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	orders := GroupOrders(dojInformation, eligibilities)
+	batchSize := e.batchSize
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	for start, number := 0, 1; start < len(orders); start, number = start+batchSize, number+1 {
+		end := start + batchSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		document := OrderDocument{Number: number, County: county, Authority: ReliefAuthority, Orders: orders[start:end]}
+		fileName := fmt.Sprintf("Orders_Batch_%03d.%s", number, OrderFormatExtensions[e.format])
+		if e.batchSize == 0 {
+			fileName = fmt.Sprintf("Order_%s.%s", orderFileName(orders[start]), OrderFormatExtensions[e.format])
+		}
+
+		err = e.writeDocument(filepath.Join(outputFolder, fileName), document)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e OrderExporter) writeDocument(documentPath string, document OrderDocument) error {
+	documentFile, err := e.files.Create(documentPath)
+	if err != nil {
+		return err
+	}
+	err = e.template.Execute(documentFile, document)
+	if err != nil {
+		documentFile.Close()
+		return err
+	}
+	return documentFile.Close()
+}
+
+func orderRelief(info *data.EligibilityInfo) string {
+	switch info.EligibilityDetermination {
+	case "Eligible for Dismissal":
+		return "Dismissal"
+	case "Eligible for Reduction":
+		return "Reduction"
+	}
+	return ""
+}
+
+func statuteCitation(codeSection string) string {
+	if ok, subSection := matchers.ExtractProp64SubSection(codeSection); ok {
+		return "Health and Safety Code section " + subSection[:5] + strings.ToLower(subSection[5:])
+	}
+	if ok, section := matchers.ExtractProp64Section(codeSection); ok {
+		return "Health and Safety Code section " + section
+	}
+	return strings.TrimSpace(codeSection)
+}
+
+func orderFileName(order Order) string {
+	name := unsafeFileNameCharacters.ReplaceAllString(order.CaseNumber, "_")
+	if strings.Trim(name, "_") == "" {
+		name = "NO_CASE_NUMBER"
+	}
+	return name + "_" + order.Defendant.SubjectID
+}
+
+func csvRecord(fields ...string) (string, error) {
+	var record strings.Builder
+	writer := csv.NewWriter(&record)
+	err := writer.Write(fields)
+	if err != nil {
+		return "", err
+	}
+	writer.Flush()
+	return record.String(), writer.Error()
+}
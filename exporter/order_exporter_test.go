@@ -0,0 +1,126 @@
+package exporter_test
+
+import (
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen/data"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("OrderExporter", func() {
+	var (
+		outputDir      string
+		dojInformation *data.DOJInformation
+		eligibilities  map[int]*data.EligibilityInfo
+	)
+	birthDate := time.Date(1980, time.March, 2, 0, 0, 0, 0, time.UTC)
+	dispositionDate := time.Date(2001, time.May, 4, 0, 0, 0, 0, time.UTC)
+
+	pushRow := func(row data.DOJRow) {
+		if dojInformation.Subjects[row.SubjectID] == nil {
+			dojInformation.Subjects[row.SubjectID] = new(data.Subject)
+		}
+		dojInformation.Subjects[row.SubjectID].PushRow(row, nil)
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		dojInformation = &data.DOJInformation{Subjects: make(map[string]*data.Subject)}
+		pushRow(data.DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", OFN: "CR-2", WasConvicted: true, CodeSection: "11357(c) HS", DispositionDate: dispositionDate, CountOrder: "101001001000", Index: 0})
+		pushRow(data.DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", OFN: "CR-2", WasConvicted: true, CodeSection: "11359 HS", DispositionDate: dispositionDate, CountOrder: "101001002000", Index: 1})
+		pushRow(data.DOJRow{SubjectID: "100", Name: "SKYWALKER,LUKE", DOB: birthDate, CII: "A111", OFN: "CR-3", WasConvicted: true, CodeSection: "11360 HS", DispositionDate: dispositionDate, CountOrder: "102001001000", Index: 2})
+		pushRow(data.DOJRow{SubjectID: "200", Name: "O'HARA,<SCARLETT>", DOB: birthDate, CII: "A222", OFN: "CR-1", WasConvicted: true, CodeSection: "11358 HS", DispositionDate: dispositionDate, CountOrder: "101001001000", Index: 3})
+
+		eligibilities = map[int]*data.EligibilityInfo{0: {}, 1: {}, 2: {}, 3: {}}
+		eligibilities[0].SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+		eligibilities[1].SetEligibleForReduction("Reduce all HS 11359 convictions")
+		eligibilities[2].SetNotEligible("Occurred after 11/09/2016")
+		eligibilities[3].SetEligibleForDismissal("Dismiss all HS 11358 convictions")
+	})
+
+	readFile := func(name string) string {
+		contents, err := ioutil.ReadFile(path.Join(outputDir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	It("groups eligible convictions by case", func() {
+		orders := GroupOrders(dojInformation, eligibilities)
+
+		Expect(orders).To(HaveLen(2))
+		Expect(orders[0].CaseNumber).To(Equal("CR-1"))
+		Expect(orders[1].CaseNumber).To(Equal("CR-2"))
+		Expect(orders[1].Defendant).To(Equal(OrderDefendant{SubjectID: "100", Name: "SKYWALKER,LUKE", DOB: "03/02/1980", CII: "A111"}))
+		Expect(orders[1].Counts).To(Equal([]OrderCount{
+			{CodeSection: "11357(c) HS", Citation: "Health and Safety Code section 11357(c)", DispositionDate: "05/04/2001", Relief: "Dismissal", Reason: "Dismiss all HS 11357 convictions"},
+			{CodeSection: "11359 HS", Citation: "Health and Safety Code section 11359", DispositionDate: "05/04/2001", Relief: "Reduction", Reason: "Reduce all HS 11359 convictions"},
+		}))
+	})
+
+	It("writes one text document per case by default", func() {
+		orderExporter, err := NewOrderExporter(utilities.OutputFiles{}, "", "text", 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(orderExporter.Export(dojInformation, eligibilities, "SACRAMENTO", outputDir)).To(Succeed())
+
+		files, err := ioutil.ReadDir(outputDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(2))
+
+		order := readFile("Order_CR-2_100.txt")
+		Expect(order).To(ContainSubstring("COUNTY OF SACRAMENTO"))
+		Expect(order).To(ContainSubstring("Defendant: SKYWALKER,LUKE"))
+		Expect(order).To(ContainSubstring("Health and Safety Code section 11357(c), convicted 05/04/2001: Dismissal"))
+		Expect(order).To(ContainSubstring("Health and Safety Code section 11359, convicted 05/04/2001: Reduction"))
+		Expect(order).ToNot(ContainSubstring("11360"))
+	})
+
+	It("writes batches of cases as CSV", func() {
+		orderExporter, err := NewOrderExporter(utilities.OutputFiles{}, "", "csv", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(orderExporter.Export(dojInformation, eligibilities, "SACRAMENTO", outputDir)).To(Succeed())
+
+		Expect(readFile("Orders_Batch_001.csv")).To(Equal(
+			"CASE_NUMBER,SUBJECT_ID,PRI_NAME,PRI_DOB,CII_NUMBER,CITATION,DISP_DATE,RELIEF,AUTHORITY\n" +
+				"CR-1,200,\"O'HARA,<SCARLETT>\",03/02/1980,A222,Health and Safety Code section 11358,05/04/2001,Dismissal,Health and Safety Code section 11361.8\n" +
+				"CR-2,100,\"SKYWALKER,LUKE\",03/02/1980,A111,Health and Safety Code section 11357(c),05/04/2001,Dismissal,Health and Safety Code section 11361.8\n" +
+				"CR-2,100,\"SKYWALKER,LUKE\",03/02/1980,A111,Health and Safety Code section 11359,05/04/2001,Reduction,Health and Safety Code section 11361.8\n",
+		))
+	})
+
+	It("escapes HTML output", func() {
+		orderExporter, err := NewOrderExporter(utilities.OutputFiles{}, "", "html", 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(orderExporter.Export(dojInformation, eligibilities, "SACRAMENTO", outputDir)).To(Succeed())
+
+		Expect(readFile("Order_CR-1_200.html")).To(ContainSubstring("O&#39;HARA,&lt;SCARLETT&gt;"))
+	})
+
+	It("renders a user supplied template", func() {
+		templatePath := path.Join(outputDir, "order.tmpl")
+		Expect(ioutil.WriteFile(templatePath, []byte("Batch {{.Number}}:{{range .Orders}} {{.CaseNumber}} ({{len .Counts}}){{end}}"), os.ModePerm)).To(Succeed())
+
+		orderExporter, err := NewOrderExporter(utilities.OutputFiles{}, templatePath, "text", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(orderExporter.Export(dojInformation, eligibilities, "SACRAMENTO", path.Join(outputDir, "orders"))).To(Succeed())
+
+		Expect(readFile(path.Join("orders", "Orders_Batch_001.txt"))).To(Equal("Batch 1: CR-1 (1)"))
+		Expect(readFile(path.Join("orders", "Orders_Batch_002.txt"))).To(Equal("Batch 2: CR-2 (2)"))
+	})
+
+	It("returns an error for a template that does not parse", func() {
+		templatePath := path.Join(outputDir, "order.tmpl")
+		Expect(ioutil.WriteFile(templatePath, []byte("{{range .Orders}"), os.ModePerm)).To(Succeed())
+
+		_, err := NewOrderExporter(utilities.OutputFiles{}, templatePath, "text", 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
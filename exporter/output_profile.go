@@ -0,0 +1,206 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"gogen/data"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ProfileOutputs = []string{
+	"All_Results",
+	"All_Results_Condensed",
+	"Prop64_Results",
+	"Hand_Review",
+	"Court_Unmatched",
+}
+
+var profileParsedFields = map[string]func(row data.DOJRow) string{
+	"subjectId":           func(row data.DOJRow) string { return row.SubjectID },
+	"name":                func(row data.DOJRow) string { return row.Name },
+	"dob":                 func(row data.DOJRow) string { return profileDate(row.DOB) },
+	"cii":                 func(row data.DOJRow) string { return row.CII },
+	"codeSection":         func(row data.DOJRow) string { return row.CodeSection },
+	"wasConvicted":        func(row data.DOJRow) string { return writeYesNo(row.WasConvicted) },
+	"isFelony":            func(row data.DOJRow) string { return writeYesNo(row.IsFelony) },
+	"felonyStatusUnknown": func(row data.DOJRow) string { return writeYesNo(row.FelonyStatusUnknown) },
+	"dispositionDate":     func(row data.DOJRow) string { return profileDate(row.DispositionDate) },
+	"sentenceEndDate":     func(row data.DOJRow) string { return profileDate(row.SentenceEndDate) },
+	"county":              func(row data.DOJRow) string { return row.County },
+	"countOrder":          func(row data.DOJRow) string { return row.CountOrder },
+	"courtCaseNumber":     func(row data.DOJRow) string { return row.NumCrtCase },
+}
+
+// Parsed fields that --redact treats like the DOJ column they come from.
+var profileParsedFieldColumns = map[string]string{
+	"subjectId":       "SUBJECT_ID",
+	"name":            "PRI_NAME",
+	"dob":             "PRI_DOB",
+	"cii":             "CII_NUMBER",
+	"courtCaseNumber": "FE_NUM_CRT_CASE",
+}
+
+type ProfileColumn struct {
+	DOJ         string `json:"doj,omitempty"`
+	Eligibility string `json:"eligibility,omitempty"`
+	Parsed      string `json:"parsed,omitempty"`
+	Label       string `json:"label,omitempty"`
+}
+
+type OutputProfile struct {
+	ColumnSets map[string][]ProfileColumn `json:"columnSets"`
+	Outputs    map[string]string          `json:"outputs"`
+}
+
+type ColumnSelection struct {
+	Headers  []string
+	columns  []ProfileColumn
+	redactor *Redactor
+}
+
+func ReadOutputProfile(profilePath string) (*OutputProfile, error) {
+	profileBytes, err := ioutil.ReadFile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile OutputProfile
+	err = json.Unmarshal(profileBytes, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read output profile %s: %s", profilePath, err)
+	}
+	err = profile.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid output profile %s: %s", profilePath, err)
+	}
+	return &profile, nil
+}
+
+func (p OutputProfile) validate() error {
+	for output, columnSet := range p.Outputs {
+		if !containsString(ProfileOutputs, output) {
+			return fmt.Errorf("unknown output %q: expected one of %s", output, strings.Join(ProfileOutputs, ", "))
+		}
+		if _, ok := p.ColumnSets[columnSet]; !ok {
+			return fmt.Errorf("output %q uses column set %q which is not defined", output, columnSet)
+		}
+	}
+
+	eligibilityHeaders := append(append([]string{}, EligiblityHeaders...), CourtMatchHeaders...)
+	for name, columns := range p.ColumnSets {
+		if len(columns) == 0 {
+			return fmt.Errorf("column set %q has no columns", name)
+		}
+		for index, column := range columns {
+			switch {
+			case column.DOJ != "" && column.Eligibility == "" && column.Parsed == "":
+				if _, ok := dojColumnIndex[column.DOJ]; !ok {
+					return fmt.Errorf("column %d of %q: unknown DOJ column %q", index+1, name, column.DOJ)
+				}
+			case column.Eligibility != "" && column.DOJ == "" && column.Parsed == "":
+				if !containsString(eligibilityHeaders, column.Eligibility) {
+					return fmt.Errorf("column %d of %q: unknown eligibility column %q: expected one of %s", index+1, name, column.Eligibility, strings.Join(eligibilityHeaders, ", "))
+				}
+			case column.Parsed != "" && column.DOJ == "" && column.Eligibility == "":
+				if _, ok := profileParsedFields[column.Parsed]; !ok {
+					return fmt.Errorf("column %d of %q: unknown parsed field %q: expected one of %s", index+1, name, column.Parsed, strings.Join(ProfileParsedFields(), ", "))
+				}
+			default:
+				return fmt.Errorf("column %d of %q must name exactly one of doj, eligibility or parsed", index+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+func ProfileParsedFields() []string {
+	var fields []string
+	for field := range profileParsedFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (p *OutputProfile) Columns(output string) (ColumnSelection, bool) {
+	if p == nil {
+		return ColumnSelection{}, false
+	}
+	columnSet, ok := p.Outputs[output]
+	if !ok {
+		return ColumnSelection{}, false
+	}
+
+	selection := ColumnSelection{columns: p.ColumnSets[columnSet]}
+	for _, column := range selection.columns {
+		selection.Headers = append(selection.Headers, column.header())
+	}
+	return selection, true
+}
+
+func (c ColumnSelection) WithRedactor(redactor *Redactor) ColumnSelection {
+	c.redactor = redactor
+	return c
+}
+
+func (c ColumnSelection) DOJColumns() []string {
+	var dojColumns []string
+	for _, column := range c.columns {
+		if column.DOJ != "" {
+			dojColumns = append(dojColumns, column.DOJ)
+		}
+	}
+	return dojColumns
+}
+
+func (c ColumnSelection) values(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) []string {
+	var eligibility map[string]string
+	values := make([]string, 0, len(c.columns))
+
+	for _, column := range c.columns {
+		switch {
+		case column.DOJ != "":
+			values = append(values, entry[dojColumnIndex[column.DOJ]])
+		case column.Eligibility != "":
+			if eligibility == nil {
+				eligibility = eligibilityValues(info, possibleOtherP64Charges)
+			}
+			values = append(values, eligibility[column.Eligibility])
+		case column.Parsed != "":
+			value := profileParsedFields[column.Parsed](*parsedRow)
+			values = append(values, c.redactor.RedactValue(profileParsedFieldColumns[column.Parsed], value))
+		}
+	}
+	return values
+}
+
+func (c ProfileColumn) header() string {
+	switch {
+	case c.Label != "":
+		return c.Label
+	case c.DOJ != "":
+		return c.DOJ
+	case c.Eligibility != "":
+		return c.Eligibility
+	}
+	return c.Parsed
+}
+
+func eligibilityValues(info *data.EligibilityInfo, possibleOtherP64Charges string) map[string]string {
+	values := make(map[string]string)
+	columns := append(eligibilityCols(info, possibleOtherP64Charges), courtMatchCols(info)...)
+	for index, header := range append(append([]string{}, EligiblityHeaders...), CourtMatchHeaders...) {
+		values[header] = columns[index]
+	}
+	return values
+}
+
+func profileDate(val time.Time) string {
+	if val.IsZero() {
+		return ""
+	}
+	return writeDate(val)
+}
@@ -0,0 +1,147 @@
+package exporter_test
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen/data"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("OutputProfile", func() {
+	var outputDir string
+
+	writeProfile := func(contents string) string {
+		profilePath := path.Join(outputDir, "profile.json")
+		Expect(ioutil.WriteFile(profilePath, []byte(contents), 0644)).To(Succeed())
+		return profilePath
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reads the column sets applied to each output", func() {
+		profile, err := ReadOutputProfile(path.Join("..", "test_fixtures", "output_profile.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		columns, ok := profile.Columns("All_Results_Condensed")
+		Expect(ok).To(BeTrue())
+		Expect(columns.Headers).To(Equal([]string{"SUBJECT_ID", "CII_NUMBER", "Name", "Date of Birth", "Court Case Number", "Code Section", "Disposition Date", "Eligibility Determination", "Eligibility Reason"}))
+		Expect(columns.DOJColumns()).To(Equal([]string{"SUBJECT_ID", "CII_NUMBER", "PRI_NAME", "PRI_DOB", "FE_NUM_CRT_CASE"}))
+
+		_, ok = profile.Columns("Court_Unmatched")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rejects unknown outputs, column sets and columns", func() {
+		_, err := ReadOutputProfile(writeProfile(`{"columnSets": {"a": [{"doj": "SUBJECT_ID"}]}, "outputs": {"Everything": "a"}}`))
+		Expect(err).To(MatchError(ContainSubstring(`unknown output "Everything"`)))
+
+		_, err = ReadOutputProfile(writeProfile(`{"columnSets": {}, "outputs": {"All_Results": "a"}}`))
+		Expect(err).To(MatchError(ContainSubstring(`column set "a" which is not defined`)))
+
+		_, err = ReadOutputProfile(writeProfile(`{"columnSets": {"a": [{"doj": "SSN"}]}}`))
+		Expect(err).To(MatchError(ContainSubstring(`unknown DOJ column "SSN"`)))
+
+		_, err = ReadOutputProfile(writeProfile(`{"columnSets": {"a": [{"parsed": "race"}]}}`))
+		Expect(err).To(MatchError(ContainSubstring(`unknown parsed field "race"`)))
+
+		_, err = ReadOutputProfile(writeProfile(`{"columnSets": {"a": [{"doj": "SUBJECT_ID", "eligibility": "Eligibility Reason"}]}}`))
+		Expect(err).To(MatchError(ContainSubstring("exactly one of doj, eligibility or parsed")))
+	})
+
+	It("writes the selected columns in order for full and condensed entries", func() {
+		profile, err := ReadOutputProfile(path.Join("..", "test_fixtures", "output_profile.json"))
+		Expect(err).ToNot(HaveOccurred())
+		columns, _ := profile.Columns("All_Results")
+
+		outputPath := path.Join(outputDir, "All_Results.csv")
+		writer, err := NewProfiledDOJWriter(utilities.OutputFiles{}, outputPath, columns)
+		Expect(err).ToNot(HaveOccurred())
+
+		entry := make([]string, len(DojFullHeaders))
+		entry[data.SUBJECT_ID] = "100"
+		entry[data.PRI_NAME] = "SKYWALKER,LUKE"
+		entry[data.PRI_SSN] = "123456789"
+		entry[data.FE_NUM_CRT_CASE] = "CR-1"
+		entry[data.OFFENSE_DESCR] = "11357(C) HS-POSSESS MARIJUANA"
+		entry[data.DISP_DESCR] = "CONVICTED"
+		entry[data.STP_EVENT_DATE] = "20010504"
+		info := &data.EligibilityInfo{}
+		info.SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		writer.WriteEntryWithEligibilityInfo(entry, &parsedRow, info, "")
+		writer.WriteCondensedEntryWithEligibilityInfo(entry, &parsedRow, nil, "")
+		Expect(writer.Flush()).To(Succeed())
+
+		resultsFile, err := os.Open(outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		defer resultsFile.Close()
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(results[0]).To(Equal(columns.Headers))
+		Expect(results[1]).To(Equal([]string{"100", "", "SKYWALKER,LUKE", "", "CR-1", "11357(C) HS", "05/04/2001", "Eligible for Dismissal", "Dismiss all HS 11357 convictions"}))
+		Expect(results[2]).To(Equal([]string{"100", "", "SKYWALKER,LUKE", "", "CR-1", "11357(C) HS", "05/04/2001", "", ""}))
+	})
+
+	It("takes parsed fields from the accumulated conviction and redacts them like their DOJ columns", func() {
+		profile, err := ReadOutputProfile(writeProfile(`{"columnSets": {"a": [{"parsed": "subjectId"}, {"parsed": "dob"}, {"parsed": "sentenceEndDate"}]}, "outputs": {"All_Results": "a"}}`))
+		Expect(err).ToNot(HaveOccurred())
+		columns, _ := profile.Columns("All_Results")
+		redactor, err := NewRedactor([]byte("secret"), "year", time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC), data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+
+		outputPath := path.Join(outputDir, "All_Results.csv")
+		writer, err := NewProfiledDOJWriter(utilities.OutputFiles{}, outputPath, columns.WithRedactor(redactor))
+		Expect(err).ToNot(HaveOccurred())
+
+		entry := make([]string, len(DojFullHeaders))
+		entry[data.SUBJECT_ID] = "100"
+		entry[data.PRI_DOB] = "19800302"
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		parsedRow.SentenceEndDate = time.Date(2003, time.May, 4, 0, 0, 0, 0, time.UTC)
+
+		NewRedactingWriter(writer, redactor, nil).WriteEntryWithEligibilityInfo(entry, &parsedRow, nil, "")
+		Expect(writer.Flush()).To(Succeed())
+
+		resultsFile, err := os.Open(outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		defer resultsFile.Close()
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(results[1]).To(Equal([]string{redactor.Pseudonym("subject", "100"), "1980", "05/04/2003"}))
+	})
+
+	It("limits the raw rows and result tables of a SQLite database to the profile", func() {
+		profile, err := ReadOutputProfile(path.Join("..", "test_fixtures", "output_profile.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		sink, err := NewSink("sqlite", SinkOptions{OutputFolder: outputDir, Profile: profile})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.Close(Summary{})).To(Succeed())
+
+		database, err := sql.Open("sqlite3", path.Join(outputDir, "Results.db"))
+		Expect(err).ToNot(HaveOccurred())
+		defer database.Close()
+
+		_, err = database.Query("SELECT pri_ssn FROM raw_rows")
+		Expect(err).To(HaveOccurred())
+		_, err = database.Query("SELECT race_descr FROM all_results")
+		Expect(err).To(HaveOccurred())
+		_, err = database.Query("SELECT court_case_number FROM all_results")
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,245 @@
+package exporter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gogen/data"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pseudonymSubject = "subject"
+	pseudonymCII     = "cii"
+	pseudonymCase    = "case"
+)
+
+var redactedColumns = map[string]string{
+	"SUBJECT_ID":          pseudonymSubject,
+	"MERGED_SUBJECT_ID":   pseudonymSubject,
+	"LINKED_SUBJECT_ID":   pseudonymSubject,
+	"CII_NUMBER":          pseudonymCII,
+	"REQ_CII_NUMBER":      pseudonymCII,
+	"LINKED_CII_NUMBER":   pseudonymCII,
+	"FE_NUM_CRT_CASE":     pseudonymCase,
+	"Case Number":         pseudonymCase,
+	"Case Numbers":        pseudonymCase,
+	"Court Docket Number": pseudonymCase,
+	"REQ_NAME":            "",
+	"REQ_SSN":             "",
+	"REQ_CDL":             "",
+	"PRI_NAME":            "",
+	"PRI_SSN":             "",
+	"PRI_CDL":             "",
+	"PRI_IDN":             "",
+	"PRI_INN":             "",
+	"FBI_NUMBER":          "",
+	"LINKED_PRI_NAME":     "",
+	"LINKED_FBI_NUMBER":   "",
+	"OFN":                 "",
+	"FE_NUM_BNCH_WARR":    "",
+	"FE_NUM_CITE":         "",
+	"FE_NUM_DOCKET":       "",
+	"FE_NUM_INCIDENT":     "",
+	"FE_NUM_BOOKING":      "",
+	"FE_NUM_NUMBER":       "",
+	"FE_NUM_REMAND":       "",
+	"FE_NUM_OOS_INN":      "",
+	"FE_NUM_WARRANT":      "",
+	"COMMENT_TEXT":        "",
+}
+
+var redactedDateColumns = map[string]bool{
+	"REQ_DOB":        true,
+	"PRI_DOB":        true,
+	"LINKED_PRI_DOB": true,
+}
+
+// With CYC_DATE an exact age at each arrest cycle would give back the date of birth.
+var redactedAgeColumns = map[string]bool{
+	"CYC_AGE": true,
+}
+
+var redactedDateLayouts = []string{"20060102", "01/02/2006", "2006-01-02"}
+
+var DOBGeneralizations = []string{"year", "age-band"}
+
+type Redactor struct {
+	key               []byte
+	dobGeneralization string
+	computeAt         time.Time
+	calendar          data.Calendar
+}
+
+func NewRedactor(key []byte, dobGeneralization string, computeAt time.Time, calendar data.Calendar) (*Redactor, error) {
+	if len(key) == 0 {
+		return nil, errors.New("redaction key is empty")
+	}
+	if !containsString(DOBGeneralizations, dobGeneralization) {
+		return nil, fmt.Errorf("unknown date of birth generalization %q: expected one of %s", dobGeneralization, strings.Join(DOBGeneralizations, ", "))
+	}
+	return &Redactor{key: key, dobGeneralization: dobGeneralization, computeAt: computeAt, calendar: calendar}, nil
+}
+
+func ReadRedactor(keyFilePath string, dobGeneralization string, computeAt time.Time, calendar data.Calendar) (*Redactor, error) {
+	key, err := ioutil.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := NewRedactor([]byte(strings.TrimSpace(string(key))), dobGeneralization, computeAt, calendar)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", keyFilePath, err)
+	}
+	return redactor, nil
+}
+
+func (r *Redactor) Pseudonym(kind string, value string) string {
+	value = strings.TrimSpace(value)
+	if r == nil || value == "" {
+		return value
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+func (r *Redactor) RedactValue(header string, value string) string {
+	if r == nil {
+		return value
+	}
+	if redactedDateColumns[header] {
+		return r.generalizeDOB(value)
+	}
+	if redactedAgeColumns[header] {
+		return generalizeAge(value)
+	}
+	kind, redacted := redactedColumns[header]
+	if !redacted {
+		return value
+	}
+	if kind == "" {
+		return ""
+	}
+
+	var pseudonyms []string
+	for _, part := range strings.Split(value, "; ") {
+		pseudonyms = append(pseudonyms, r.Pseudonym(kind, part))
+	}
+	return strings.Join(pseudonyms, "; ")
+}
+
+func (r *Redactor) RedactRecord(headers []string, record []string) []string {
+	if r == nil {
+		return record
+	}
+	redacted := make([]string, len(record))
+	for index, value := range record {
+		if index < len(headers) {
+			value = r.RedactValue(headers[index], value)
+		}
+		redacted[index] = value
+	}
+	return redacted
+}
+
+func (r *Redactor) RedactEligibility(info *data.EligibilityInfo) *data.EligibilityInfo {
+	if r == nil || info == nil {
+		return info
+	}
+	redacted := *info
+	redacted.CaseNumber = r.RedactValue("Case Number", info.CaseNumber)
+	redacted.CourtDocketNumber = r.RedactValue("Court Docket Number", info.CourtDocketNumber)
+	return &redacted
+}
+
+func (r *Redactor) generalizeDOB(value string) string {
+	dob, ok := parseRedactedDate(strings.TrimSpace(value))
+	if !ok {
+		return ""
+	}
+	if r.dobGeneralization == "year" {
+		return fmt.Sprintf("%d", dob.Year())
+	}
+	return ageBand(r.calendar, dob, r.computeAt)
+}
+
+func ageBand(calendar data.Calendar, dob time.Time, at time.Time) string {
+	return tenYearBand(calendar.AgeAt(dob, at))
+}
+
+func generalizeAge(value string) string {
+	age, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || age < 0 {
+		return ""
+	}
+	return tenYearBand(age)
+}
+
+func tenYearBand(age int) string {
+	if age >= 90 {
+		return "90+"
+	}
+	band := age / 10 * 10
+	return fmt.Sprintf("%d-%d", band, band+9)
+}
+
+func parseRedactedDate(value string) (time.Time, bool) {
+	for _, layout := range redactedDateLayouts {
+		date, err := time.Parse(layout, value)
+		if err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+type redactingWriter struct {
+	writer   DOJWriter
+	redactor *Redactor
+	headers  []string
+}
+
+func NewRedactingWriter(writer DOJWriter, redactor *Redactor, headers []string) DOJWriter {
+	if redactor == nil {
+		return writer
+	}
+	return redactingWriter{writer: writer, redactor: redactor, headers: headers}
+}
+
+func RedactWriters(writers ResultWriters, redactor *Redactor) ResultWriters {
+	resultHeaders := resultHeaders(DojFullHeaders, true)
+	return ResultWriters{
+		DOJ:               NewRedactingWriter(writers.DOJ, redactor, resultHeaders),
+		Condensed:         NewRedactingWriter(writers.Condensed, redactor, resultHeaders),
+		Prop64Convictions: NewRedactingWriter(writers.Prop64Convictions, redactor, resultHeaders),
+		HandReview:        NewRedactingWriter(writers.HandReview, redactor, resultHeaders),
+		Subjects:          NewRedactingWriter(writers.Subjects, redactor, SubjectHeaders),
+	}
+}
+
+// The parsed row is passed on as is; writers that show parsed fields redact them
+// with their own redactor, the way the SQLite sink does.
+func (w redactingWriter) WriteEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	w.writer.WriteEntryWithEligibilityInfo(w.redactor.RedactRecord(DojFullHeaders, entry), parsedRow, w.redactor.RedactEligibility(info), possibleOtherP64Charges)
+}
+
+func (w redactingWriter) WriteCondensedEntryWithEligibilityInfo(entry []string, parsedRow *data.DOJRow, info *data.EligibilityInfo, possibleOtherP64Charges string) {
+	w.writer.WriteCondensedEntryWithEligibilityInfo(w.redactor.RedactRecord(DojFullHeaders, entry), parsedRow, w.redactor.RedactEligibility(info), possibleOtherP64Charges)
+}
+
+func (w redactingWriter) Write(record []string) {
+	w.writer.Write(w.redactor.RedactRecord(w.headers, record))
+}
+
+func (w redactingWriter) Error() error {
+	return w.writer.Error()
+}
+
+func (w redactingWriter) Flush() error {
+	return w.writer.Flush()
+}
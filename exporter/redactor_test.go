@@ -0,0 +1,125 @@
+package exporter_test
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen/data"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("Redactor", func() {
+	var (
+		redactor  *Redactor
+		computeAt time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		computeAt = time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+		redactor, err = NewRedactor([]byte("secret"), "year", computeAt, data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("gives the same pseudonym for the same secret and value", func() {
+		other, err := NewRedactor([]byte("secret"), "age-band", computeAt, data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+		differentSecret, err := NewRedactor([]byte("another secret"), "year", computeAt, data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+
+		pseudonym := redactor.RedactValue("SUBJECT_ID", "100")
+		Expect(pseudonym).To(HaveLen(16))
+		Expect(pseudonym).ToNot(Equal("100"))
+		Expect(other.RedactValue("SUBJECT_ID", "100")).To(Equal(pseudonym))
+		Expect(redactor.RedactValue("LINKED_SUBJECT_ID", "100 ")).To(Equal(pseudonym))
+		Expect(redactor.RedactValue("CII_NUMBER", "100")).ToNot(Equal(pseudonym))
+		Expect(differentSecret.RedactValue("SUBJECT_ID", "100")).ToNot(Equal(pseudonym))
+		Expect(redactor.RedactValue("SUBJECT_ID", "")).To(BeEmpty())
+	})
+
+	It("drops direct identifiers and keeps other columns", func() {
+		Expect(redactor.RedactValue("PRI_NAME", "SKYWALKER,LUKE")).To(BeEmpty())
+		Expect(redactor.RedactValue("PRI_SSN", "123456789")).To(BeEmpty())
+		Expect(redactor.RedactValue("COMMENT_TEXT", "AKA VADER,ANAKIN")).To(BeEmpty())
+		Expect(redactor.RedactValue("OFFENSE_DESCR", "11357 HS-POSSESS MARIJUANA")).To(Equal("11357 HS-POSSESS MARIJUANA"))
+		Expect(redactor.RedactValue("Case Numbers", "CR-1; CR-2")).To(Equal(redactor.RedactValue("Case Number", "CR-1") + "; " + redactor.RedactValue("Case Number", "CR-2")))
+	})
+
+	It("generalizes dates of birth to the year or an age band", func() {
+		ageBand, err := NewRedactor([]byte("secret"), "age-band", computeAt, data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(redactor.RedactValue("PRI_DOB", "19800302")).To(Equal("1980"))
+		Expect(redactor.RedactValue("PRI_DOB", "03/02/1980")).To(Equal("1980"))
+		Expect(ageBand.RedactValue("PRI_DOB", "19800302")).To(Equal("30-39"))
+		Expect(ageBand.RedactValue("PRI_DOB", "19891112")).To(Equal("20-29"))
+		Expect(ageBand.RedactValue("PRI_DOB", "19201112")).To(Equal("90+"))
+		Expect(redactor.RedactValue("PRI_DOB", "")).To(BeEmpty())
+	})
+
+	It("works out age bands with the leap day rule", func() {
+		onFebruary28 := time.Date(2030, time.February, 28, 0, 0, 0, 0, time.UTC)
+		march1, err := NewRedactor([]byte("secret"), "age-band", onFebruary28, data.Calendar{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(march1.RedactValue("PRI_DOB", "20000229")).To(Equal("20-29"))
+
+		february28Calendar, err := data.NewCalendar(data.LeapDayFebruary28)
+		Expect(err).ToNot(HaveOccurred())
+		february28, err := NewRedactor([]byte("secret"), "age-band", onFebruary28, february28Calendar)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(february28.RedactValue("PRI_DOB", "20000229")).To(Equal("30-39"))
+	})
+
+	It("generalizes the age at each arrest cycle to an age band", func() {
+		Expect(redactor.RedactValue("CYC_AGE", "23")).To(Equal("20-29"))
+		Expect(redactor.RedactValue("CYC_AGE", " 091")).To(Equal("90+"))
+		Expect(redactor.RedactValue("CYC_AGE", "")).To(BeEmpty())
+	})
+
+	It("rejects an empty secret or unknown generalization", func() {
+		_, err := NewRedactor([]byte{}, "year", computeAt, data.Calendar{})
+		Expect(err).To(HaveOccurred())
+		_, err = NewRedactor([]byte("secret"), "decade", computeAt, data.Calendar{})
+		Expect(err).To(MatchError(ContainSubstring(`unknown date of birth generalization "decade"`)))
+	})
+
+	It("redacts the entries and eligibility given to a writer", func() {
+		outputDir, err := ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		outputPath := path.Join(outputDir, "All_Results.csv")
+		writer, err := NewDOJWriter(utilities.OutputFiles{}, outputPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		entry := make([]string, len(DojFullHeaders))
+		entry[data.SUBJECT_ID] = "100"
+		entry[data.PRI_NAME] = "SKYWALKER,LUKE"
+		entry[data.PRI_DOB] = "19800302"
+		entry[data.OFFENSE_DESCR] = "11357 HS-POSSESS MARIJUANA"
+		info := &data.EligibilityInfo{CaseNumber: "CR-1"}
+
+		redactingWriter := NewRedactingWriter(writer, redactor, nil)
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		redactingWriter.WriteEntryWithEligibilityInfo(entry, &parsedRow, info, "")
+		Expect(redactingWriter.Flush()).To(Succeed())
+
+		resultsFile, err := os.Open(outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		defer resultsFile.Close()
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(results[1][data.SUBJECT_ID]).To(Equal(redactor.RedactValue("SUBJECT_ID", "100")))
+		Expect(results[1][data.PRI_NAME]).To(BeEmpty())
+		Expect(results[1][data.PRI_DOB]).To(Equal("1980"))
+		Expect(results[1][data.OFFENSE_DESCR]).To(Equal("11357 HS-POSSESS MARIJUANA"))
+		Expect(results[1][len(DojFullHeaders)]).To(Equal(redactor.RedactValue("Case Number", "CR-1")))
+		Expect(entry[data.PRI_NAME]).To(Equal("SKYWALKER,LUKE"))
+		Expect(info.CaseNumber).To(Equal("CR-1"))
+	})
+})
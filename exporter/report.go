@@ -0,0 +1,363 @@
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gogen/utilities"
+	htmlTemplate "html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+var reliefCountLabels = []struct {
+	Key   string
+	Label string
+}{
+	{"CountSubjectsNoFelony", "People left with no felony"},
+	{"CountSubjectsNoConviction", "People left with no conviction"},
+	{"CountSubjectsNoConvictionLast7Years", "People left with no conviction in the last 7 years"},
+}
+
+var equityCountLabels = []struct {
+	Key   string
+	Label string
+}{
+	{"Prop64Convictions", "Prop 64 convictions"},
+	{"EligibleConvictions", "Eligible convictions"},
+	{"CountSubjectsNoFelony", "People left with no felony"},
+	{"CountSubjectsNoConviction", "People left with no conviction"},
+}
+
+var reportTemplate = htmlTemplate.Must(htmlTemplate.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 2em auto; max-width: 960px; padding: 0 1em; }
+h1 { margin-bottom: 0.2em; }
+.details { color: #666; margin-top: 0; }
+.metrics { display: flex; flex-wrap: wrap; gap: 1em; margin: 1.5em 0; }
+.metric { border: 1px solid #ddd; border-radius: 4px; padding: 0.8em 1em; min-width: 180px; }
+.metric .value { font-size: 1.6em; font-weight: bold; }
+.metric .label { color: #666; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 0.5em; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; }
+td.bar { width: 40%; }
+td.bar span { display: inline-block; height: 0.9em; background: #4a7bb7; }
+.note { color: #666; font-size: 0.9em; margin-top: 0; }
+.warnings { background: #fff6e0; border: 1px solid #e8c872; border-radius: 4px; padding: 0.5em 1.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="details">{{range $index, $detail := .Details}}{{if $index}} &middot; {{end}}{{$detail}}{{end}}</p>
+<div class="metrics">
+{{range .Metrics}}<div class="metric"><div class="value">{{.Value}}</div><div class="label">{{.Label}}</div></div>
+{{end}}</div>
+{{if .Warnings}}<h2>Data quality</h2>
+<ul class="warnings">
+{{range .Warnings}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}{{range .Tables}}<h2>{{.Title}}</h2>
+{{if .Note}}<p class="note">{{.Note}}</p>
+{{end}}<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}{{if .IsBar}}<td class="bar"><span style="width: {{.Bar}}%"></span></td>{{else}}<td>{{.Text}}</td>{{end}}{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+type Report struct {
+	Summary  Summary
+	Counties map[string]Summary
+}
+
+type reportMetric struct {
+	Label string
+	Value string
+}
+
+type reportCell struct {
+	Text  string
+	IsBar bool
+	Bar   int
+}
+
+type reportTable struct {
+	Title   string
+	Note    string
+	Headers []string
+	Rows    [][]reportCell
+}
+
+type reportPage struct {
+	Title    string
+	Details  []string
+	Metrics  []reportMetric
+	Warnings []string
+	Tables   []reportTable
+}
+
+func ReadReport(summaryBytes []byte) (Report, error) {
+	var statewideSummary StatewideSummary
+	err := json.Unmarshal(summaryBytes, &statewideSummary)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(statewideSummary.Counties) > 0 {
+		summary := statewideSummary.Statewide
+		summary.Manifest = statewideSummary.Manifest
+		return Report{Summary: summary, Counties: statewideSummary.CountySummaries}, nil
+	}
+
+	var summary Summary
+	err = json.Unmarshal(summaryBytes, &summary)
+	if err != nil {
+		return Report{}, err
+	}
+	if summary.County == "" {
+		return Report{}, errors.New("not a summary written by gogen run")
+	}
+	return Report{Summary: summary}, nil
+}
+
+func WriteReport(files utilities.OutputFiles, report Report, filePath string) error {
+	reportFile, err := files.Create(filePath)
+	if err != nil {
+		return err
+	}
+	err = reportTemplate.Execute(reportFile, newReportPage(report))
+	if err != nil {
+		reportFile.Close()
+		return err
+	}
+	return reportFile.Close()
+}
+
+func newReportPage(report Report) reportPage {
+	summary := report.Summary
+	county := summary.County
+	if county == "ALL" || len(report.Counties) > 0 {
+		county = "all counties"
+	}
+
+	page := reportPage{
+		Title: "Prop 64 relief report for " + county,
+		Metrics: []reportMetric{
+			{"Lines read", reportCount(summary.LineCount)},
+			{"People with a Prop 64 conviction", reportCount(summary.SubjectsWithProp64ConvictionCountInCounty)},
+			{"Prop 64 felony convictions", reportCount(summary.Prop64FelonyConvictionsCountInCounty)},
+			{"Prop 64 non-felony convictions", reportCount(summary.Prop64NonFelonyConvictionsCountInCounty)},
+			{"People getting some relief", reportCount(summary.SubjectsWithSomeReliefCount)},
+		},
+	}
+	if !summary.EarliestConviction.IsZero() {
+		page.Metrics = append(page.Metrics, reportMetric{"Earliest Prop 64 conviction", summary.EarliestConviction.Format("January 2, 2006")})
+	}
+	if manifest := summary.Manifest; manifest != nil {
+		page.Details = append(page.Details, "Eligibility computed at "+manifest.ComputeAt, "gogen "+manifest.GogenVersion, "Run finished "+manifest.FinishedAt.Format(time.RFC1123))
+	}
+
+	page.Warnings = append(page.Warnings, summary.Warnings...)
+	if handReviews := sumCounts(summary.HandReviewCountByTrigger); handReviews > 0 {
+		page.Warnings = append(page.Warnings, fmt.Sprintf("%s convictions need hand review, see the table below", reportCount(handReviews)))
+	}
+	if summary.PreviouslyProcessedCount > 0 {
+		page.Warnings = append(page.Warnings, fmt.Sprintf("%s convictions were already processed and are left out of the relief counts", reportCount(summary.PreviouslyProcessedCount)))
+	}
+
+	reliefTable := reportTable{
+		Title:   "Relief under each set of eligibility choices",
+		Headers: []string{"", "Current eligibility options", "Dismiss all Prop 64", "Dismiss all Prop 64 and related"},
+	}
+	for _, relief := range reliefCountLabels {
+		reliefTable.Rows = append(reliefTable.Rows, textCells(
+			relief.Label,
+			reportCount(summary.ReliefWithCurrentEligibilityChoices[relief.Key]),
+			reportCount(summary.ReliefWithDismissAllProp64[relief.Key]),
+			reliefCount(summary.ReliefWithDismissAllProp64AndRelated, relief.Key),
+		))
+	}
+	page.Tables = append(page.Tables, reliefTable)
+
+	codeSectionTable := reportTable{
+		Title:   "Convictions by code section",
+		Headers: []string{"Code section", "Prop 64 convictions", "Eligible for dismissal", "Eligible for reduction"},
+	}
+	for _, codeSection := range sortedKeys(summary.Prop64ConvictionsCountInCountyByCodeSection, summary.ConvictionDismissalCountByCodeSection, summary.ConvictionReductionCountByCodeSection) {
+		codeSectionTable.Rows = append(codeSectionTable.Rows, textCells(
+			codeSection,
+			reportCount(summary.Prop64ConvictionsCountInCountyByCodeSection[codeSection]),
+			reportCount(summary.ConvictionDismissalCountByCodeSection[codeSection]),
+			reportCount(summary.ConvictionReductionCountByCodeSection[codeSection]),
+		))
+	}
+	page.Tables = append(page.Tables, codeSectionTable)
+
+	page.Tables = appendCountTable(page.Tables, "Dismissals by reason", "Reason", summary.ConvictionDismissalCountByAdditionalRelief)
+	page.Tables = appendCountTable(page.Tables, "Hand review by reason", "Reason", summary.HandReviewCountByTrigger)
+
+	years := ConvictionYears(summary)
+	if len(years) > 0 {
+		yearTable := reportTable{
+			Title:   "Prop 64 convictions by disposition year",
+			Headers: []string{"Year", "Felony", "Non-felony", "Total", ""},
+		}
+		maximum := 0
+		for _, year := range years {
+			if total := sumCounts(summary.Prop64ConvictionsCountInCountyByYear[year]); total > maximum {
+				maximum = total
+			}
+		}
+		for _, year := range years {
+			counts := summary.Prop64ConvictionsCountInCountyByYear[year]
+			total := sumCounts(counts)
+			yearTable.Rows = append(yearTable.Rows, append(
+				textCells(year, reportCount(counts["Felony"]), reportCount(counts["NonFelony"]), reportCount(total)),
+				barCell(total, maximum),
+			))
+		}
+		page.Tables = append(page.Tables, yearTable)
+	}
+
+	if breakdowns := summary.EquityBreakdowns; breakdowns != nil {
+		note := fmt.Sprintf("Counts from 1 to %d are not shown to protect privacy.", breakdowns.MinimumCellSize-1)
+		for _, dimension := range []struct {
+			Title       string
+			Group       string
+			GroupCounts map[string]map[string]int
+		}{
+			{"Relief by race", "Race", breakdowns.ByRace},
+			{"Relief by gender", "Gender", breakdowns.ByGender},
+			{"Relief by age at the compute-at date", "Age", breakdowns.ByAgeBand},
+		} {
+			equityTable := reportTable{Title: dimension.Title, Headers: []string{dimension.Group}}
+			if breakdowns.MinimumCellSize > 1 {
+				equityTable.Note = note
+			}
+			for _, equityCount := range equityCountLabels {
+				equityTable.Headers = append(equityTable.Headers, equityCount.Label)
+			}
+			var groups []string
+			for group := range dimension.GroupCounts {
+				groups = append(groups, group)
+			}
+			sort.Strings(groups)
+			for _, group := range groups {
+				row := textCells(group)
+				for _, equityCount := range equityCountLabels {
+					count, shown := dimension.GroupCounts[group][equityCount.Key]
+					text := "Not shown"
+					if shown {
+						text = reportCount(count)
+					}
+					row = append(row, reportCell{Text: text})
+				}
+				equityTable.Rows = append(equityTable.Rows, row)
+			}
+			page.Tables = append(page.Tables, equityTable)
+		}
+	}
+
+	if len(report.Counties) > 0 {
+		countyTable := reportTable{
+			Title:   "Relief by county",
+			Headers: []string{"County", "Prop 64 convictions", "People getting some relief", "People left with no felony", "People left with no conviction"},
+		}
+		var counties []string
+		for county := range report.Counties {
+			counties = append(counties, county)
+		}
+		sort.Strings(counties)
+		for _, county := range counties {
+			countySummary := report.Counties[county]
+			countyTable.Rows = append(countyTable.Rows, textCells(
+				county,
+				reportCount(countySummary.Prop64FelonyConvictionsCountInCounty+countySummary.Prop64NonFelonyConvictionsCountInCounty),
+				reportCount(countySummary.SubjectsWithSomeReliefCount),
+				reportCount(countySummary.ReliefWithCurrentEligibilityChoices["CountSubjectsNoFelony"]),
+				reportCount(countySummary.ReliefWithCurrentEligibilityChoices["CountSubjectsNoConviction"]),
+			))
+		}
+		page.Tables = append(page.Tables, countyTable)
+	}
+	return page
+}
+
+func appendCountTable(tables []reportTable, title string, header string, counts map[string]int) []reportTable {
+	if len(counts) == 0 {
+		return tables
+	}
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(a, b int) bool {
+		return counts[keys[a]] > counts[keys[b]]
+	})
+
+	table := reportTable{Title: title, Headers: []string{header, "Convictions"}}
+	for _, key := range keys {
+		table.Rows = append(table.Rows, textCells(key, reportCount(counts[key])))
+	}
+	return append(tables, table)
+}
+
+func textCells(values ...string) []reportCell {
+	cells := make([]reportCell, len(values))
+	for index, value := range values {
+		cells[index] = reportCell{Text: value}
+	}
+	return cells
+}
+
+func barCell(count int, maximum int) reportCell {
+	if maximum == 0 {
+		return reportCell{IsBar: true}
+	}
+	return reportCell{IsBar: true, Bar: count * 100 / maximum}
+}
+
+func sortedKeys(countMaps ...map[string]int) []string {
+	var keys []string
+	for _, counts := range countMaps {
+		for key := range counts {
+			if !containsString(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+// reliefCount tells a comparison that an older gogen.json doesn't have apart
+// from a real count of zero.
+func reliefCount(counts map[string]int, key string) string {
+	if counts == nil {
+		return "Not recorded"
+	}
+	return reportCount(counts[key])
+}
+
+func reportCount(count int) string {
+	digits := fmt.Sprintf("%d", count)
+	if count < 0 {
+		return digits
+	}
+	var groups []string
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	return strings.Join(append([]string{digits}, groups...), ",")
+}
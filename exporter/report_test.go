@@ -0,0 +1,99 @@
+package exporter_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("Report", func() {
+	var (
+		outputDir string
+		summary   Summary
+	)
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		summary = Summary{
+			County:                                      "SAN JOAQUIN",
+			LineCount:                                   12345,
+			ReliefWithCurrentEligibilityChoices:         map[string]int{"CountSubjectsNoFelony": 7},
+			ReliefWithDismissAllProp64:                  map[string]int{"CountSubjectsNoFelony": 9},
+			ReliefWithDismissAllProp64AndRelated:        map[string]int{"CountSubjectsNoFelony": 11},
+			Prop64ConvictionsCountInCountyByCodeSection: map[string]int{"11357": 3, "11359": 4},
+			ConvictionReductionCountByCodeSection:       map[string]int{"11359": 4},
+			HandReviewCountByTrigger:                    map[string]int{"Missing date of birth": 2},
+			Prop64ConvictionsCountInCountyByYear:        map[string]map[string]int{"1999": {"Felony": 4}, "2001": {"Felony": 1, "NonFelony": 1}},
+			EquityBreakdowns: &EquityBreakdowns{
+				MinimumCellSize: 11,
+				ByRace:          map[string]map[string]int{"OTHER": {"Prop64Convictions": 20}, "<b>UNKNOWN</b>": {"Prop64Convictions": 3}},
+			},
+			Warnings: []string{"input.csv: no rows found for county SAN JOAQUIN"},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	writeReport := func(summaryBytes []byte) string {
+		report, err := ReadReport(summaryBytes)
+		Expect(err).ToNot(HaveOccurred())
+		reportFilePath := path.Join(outputDir, "Report.html")
+		Expect(WriteReport(utilities.OutputFiles{}, report, reportFilePath)).To(Succeed())
+		reportBytes, err := ioutil.ReadFile(reportFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		return string(reportBytes)
+	}
+
+	It("renders the metrics, comparisons, breakdowns and warnings of a summary", func() {
+		summaryBytes, err := json.Marshal(summary)
+		Expect(err).ToNot(HaveOccurred())
+
+		report := writeReport(summaryBytes)
+		Expect(report).To(ContainSubstring("Prop 64 relief report for SAN JOAQUIN"))
+		Expect(report).To(ContainSubstring("12,345"))
+		Expect(report).To(ContainSubstring("<tr><td>People left with no felony</td><td>7</td><td>9</td><td>11</td></tr>"))
+		Expect(report).To(ContainSubstring("<tr><td>11359</td><td>4</td><td>0</td><td>4</td></tr>"))
+		Expect(report).To(ContainSubstring("<tr><td>Missing date of birth</td><td>2</td></tr>"))
+		Expect(report).To(ContainSubstring(`<tr><td>1999</td><td>4</td><td>0</td><td>4</td><td class="bar"><span style="width: 100%"></span></td></tr>`))
+		Expect(report).To(ContainSubstring(`<tr><td>2001</td><td>1</td><td>1</td><td>2</td><td class="bar"><span style="width: 50%"></span></td></tr>`))
+		Expect(report).To(ContainSubstring("Counts from 1 to 10 are not shown to protect privacy."))
+		Expect(report).To(ContainSubstring("&lt;b&gt;UNKNOWN&lt;/b&gt;"))
+		Expect(report).To(ContainSubstring("<li>input.csv: no rows found for county SAN JOAQUIN</li>"))
+		Expect(report).To(ContainSubstring("<li>2 convictions need hand review, see the table below</li>"))
+	})
+
+	It("shows comparisons missing from older summaries as not recorded", func() {
+		summary.ReliefWithDismissAllProp64AndRelated = nil
+		summaryBytes, err := json.Marshal(summary)
+		Expect(err).ToNot(HaveOccurred())
+
+		report := writeReport(summaryBytes)
+		Expect(report).To(ContainSubstring("<tr><td>People left with no felony</td><td>7</td><td>9</td><td>Not recorded</td></tr>"))
+	})
+
+	It("reports on every county of a statewide summary", func() {
+		summaryBytes, err := json.Marshal(NewStatewideSummary(map[string]Summary{"SAN JOAQUIN": summary}, 12345))
+		Expect(err).ToNot(HaveOccurred())
+
+		report := writeReport(summaryBytes)
+		Expect(report).To(ContainSubstring("Prop 64 relief report for all counties"))
+		Expect(report).To(ContainSubstring("Relief by county"))
+		Expect(report).To(ContainSubstring("<td>SAN JOAQUIN</td>"))
+	})
+
+	It("fails for files that are not a gogen summary", func() {
+		_, err := ReadReport([]byte(`{"counts": 3}`))
+		Expect(err).To(MatchError("not a summary written by gogen run"))
+	})
+})
@@ -0,0 +1,283 @@
+package exporter
+
+import (
+	"fmt"
+	"gogen/data"
+	"gogen/utilities"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type ResultWriters struct {
+	DOJ               DOJWriter
+	Condensed         DOJWriter
+	Prop64Convictions DOJWriter
+	HandReview        DOJWriter
+	Subjects          DOJWriter
+}
+
+type SinkOptions struct {
+	OutputFolder      string
+	FileIndex         int
+	FileCount         int
+	FileNameSuffix    string
+	CourtMatchColumns bool
+	Profile           *OutputProfile
+	Redactor          *Redactor
+	Run               *RunMetadata
+	Files             utilities.OutputFiles
+}
+
+type Sink interface {
+	Writers() ResultWriters
+	Close(summary Summary) error
+}
+
+// EligibilitySink writes the rows at rowIndexes, or every row when rowIndexes is nil.
+type EligibilitySink interface {
+	WriteEligibilities(dojInformation *data.DOJInformation, rowIndexes []int, eligibilities map[int]*data.EligibilityInfo) error
+}
+
+type SinkFactory func(options SinkOptions) (Sink, error)
+
+type OutputDestination struct {
+	Scheme string
+	Folder string
+}
+
+var sinkFactories = map[string]SinkFactory{
+	"csv":    newCSVSink,
+	"jsonl":  newJSONLinesSink,
+	"sqlite": newSQLiteSink,
+	"xlsx":   newWorkbookSink,
+}
+
+var outputSchemePattern = regexp.MustCompile(`^([a-z][a-z0-9]+):(.*)$`)
+
+func RegisterSink(scheme string, factory SinkFactory) {
+	sinkFactories[scheme] = factory
+}
+
+func SinkSchemes() []string {
+	var schemes []string
+	for scheme := range sinkFactories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func ParseOutputDestination(destination string) (OutputDestination, error) {
+	match := outputSchemePattern.FindStringSubmatch(destination)
+	if match == nil {
+		return OutputDestination{Folder: destination}, nil
+	}
+	if _, ok := sinkFactories[match[1]]; !ok {
+		return OutputDestination{}, fmt.Errorf("unknown output scheme %q in %q: expected one of %s", match[1], destination, strings.Join(SinkSchemes(), ", "))
+	}
+	if match[2] == "" {
+		return OutputDestination{}, fmt.Errorf("output %q is missing a folder", destination)
+	}
+	return OutputDestination{Scheme: match[1], Folder: match[2]}, nil
+}
+
+func NewSink(scheme string, options SinkOptions) (Sink, error) {
+	factory, ok := sinkFactories[scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown output scheme %q: expected one of %s", scheme, strings.Join(SinkSchemes(), ", "))
+	}
+	err := os.MkdirAll(options.OutputFolder, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	return factory(options)
+}
+
+func CombineSinkWriters(sinks []Sink) ResultWriters {
+	var dojWriters, condensedWriters, prop64ConvictionsWriters, handReviewWriters, subjectsWriters []DOJWriter
+	for _, sink := range sinks {
+		writers := sink.Writers()
+		dojWriters = append(dojWriters, writers.DOJ)
+		condensedWriters = append(condensedWriters, writers.Condensed)
+		prop64ConvictionsWriters = append(prop64ConvictionsWriters, writers.Prop64Convictions)
+		handReviewWriters = append(handReviewWriters, writers.HandReview)
+		subjectsWriters = append(subjectsWriters, writers.Subjects)
+	}
+	return ResultWriters{
+		DOJ:               NewMultiDOJWriter(dojWriters...),
+		Condensed:         NewMultiDOJWriter(condensedWriters...),
+		Prop64Convictions: NewMultiDOJWriter(prop64ConvictionsWriters...),
+		HandReview:        NewMultiDOJWriter(handReviewWriters...),
+		Subjects:          NewMultiDOJWriter(subjectsWriters...),
+	}
+}
+
+func WriteSinkEligibilities(sinks []Sink, dojInformation *data.DOJInformation, rowIndexes []int, eligibilities map[int]*data.EligibilityInfo) error {
+	for _, sink := range sinks {
+		if eligibilitySink, ok := sink.(EligibilitySink); ok {
+			err := eligibilitySink.WriteEligibilities(dojInformation, rowIndexes, eligibilities)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func CloseSinks(sinks []Sink, summary Summary) error {
+	var errs []error
+	for _, sink := range sinks {
+		errs = append(errs, sink.Close(summary))
+	}
+	return CollectWriteErrors(errs...)
+}
+
+func (o SinkOptions) fileName(template string) string {
+	return utilities.GenerateIndexedFileName(o.OutputFolder, template, o.FileIndex, o.FileCount, o.FileNameSuffix)
+}
+
+type fileSink struct {
+	writers ResultWriters
+}
+
+func (s fileSink) Writers() ResultWriters {
+	return s.writers
+}
+
+func (s fileSink) Close(summary Summary) error {
+	return nil
+}
+
+func newCSVSink(options SinkOptions) (Sink, error) {
+	var writers ResultWriters
+	var err error
+
+	newDOJWriter, newCondensedDOJWriter := NewDOJWriter, NewCondensedDOJWriter
+	if options.CourtMatchColumns {
+		newDOJWriter, newCondensedDOJWriter = NewCourtMatchedDOJWriter, NewCourtMatchedCondensedDOJWriter
+	}
+	newResultWriter := func(output string, newDefaultWriter func(utilities.OutputFiles, string) (DOJWriter, error)) (DOJWriter, error) {
+		outputFilePath := options.fileName(output + "%s.csv")
+		if columns, ok := options.Profile.Columns(output); ok {
+			return NewProfiledDOJWriter(options.Files, outputFilePath, columns.WithRedactor(options.Redactor))
+		}
+		return newDefaultWriter(options.Files, outputFilePath)
+	}
+
+	writers.DOJ, err = newResultWriter("All_Results", newDOJWriter)
+	if err != nil {
+		return nil, err
+	}
+	writers.Condensed, err = newResultWriter("All_Results_Condensed", newCondensedDOJWriter)
+	if err != nil {
+		return nil, err
+	}
+	writers.Prop64Convictions, err = newResultWriter("Prop64_Results", newDOJWriter)
+	if err != nil {
+		return nil, err
+	}
+	writers.HandReview, err = newResultWriter("Hand_Review", newDOJWriter)
+	if err != nil {
+		return nil, err
+	}
+	writers.Subjects, err = NewSubjectsWriter(options.Files, options.fileName("Subjects_Results%s.csv"))
+	if err != nil {
+		return nil, err
+	}
+	return fileSink{writers: writers}, nil
+}
+
+func newJSONLinesSink(options SinkOptions) (Sink, error) {
+	var writers ResultWriters
+	var err error
+
+	newResultWriter := func(output string, dojHeaders []string) (DOJWriter, error) {
+		if columns, ok := options.Profile.Columns(output); ok {
+			dojHeaders = columns.DOJColumns()
+		}
+		writer, err := newJSONLinesWriter(options.Files, options.fileName(output+"%s.jsonl"), nil, dojHeaders)
+		if err != nil {
+			return nil, err
+		}
+		writer.redactor = options.Redactor
+		return writer, nil
+	}
+
+	writers.DOJ, err = newResultWriter("All_Results", DojFullHeaders)
+	if err != nil {
+		return nil, err
+	}
+	writers.Condensed, err = newResultWriter("All_Results_Condensed", DojCondensedHeaders)
+	if err != nil {
+		return nil, err
+	}
+	writers.Prop64Convictions, err = newResultWriter("Prop64_Results", DojFullHeaders)
+	if err != nil {
+		return nil, err
+	}
+	writers.HandReview, err = newResultWriter("Hand_Review", DojFullHeaders)
+	if err != nil {
+		return nil, err
+	}
+	writers.Subjects, err = NewJSONLinesWriter(options.Files, options.fileName("Subjects_Results%s.jsonl"), SubjectHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return fileSink{writers: writers}, nil
+}
+
+type workbookSink struct {
+	workbook *Workbook
+	writers  ResultWriters
+}
+
+func newWorkbookSink(options SinkOptions) (Sink, error) {
+	sink := &workbookSink{workbook: NewWorkbook(options.Files, options.fileName("Results%s.xlsx"))}
+	var err error
+
+	newResultWriter := func(sheetName string, newDefaultWriter func(*Workbook, string, bool) (DOJWriter, error)) (DOJWriter, error) {
+		if columns, ok := options.Profile.Columns(sheetName); ok {
+			return NewProfiledSheetWriter(sink.workbook, sheetName, columns.WithRedactor(options.Redactor))
+		}
+		return newDefaultWriter(sink.workbook, sheetName, options.CourtMatchColumns)
+	}
+
+	sink.writers.DOJ, err = newResultWriter("All_Results", NewDOJSheetWriter)
+	if err != nil {
+		return nil, err
+	}
+	sink.writers.Condensed, err = newResultWriter("All_Results_Condensed", NewCondensedDOJSheetWriter)
+	if err != nil {
+		return nil, err
+	}
+	sink.writers.Prop64Convictions, err = newResultWriter("Prop64_Results", NewDOJSheetWriter)
+	if err != nil {
+		return nil, err
+	}
+	sink.writers.HandReview, err = newResultWriter("Hand_Review", NewDOJSheetWriter)
+	if err != nil {
+		return nil, err
+	}
+	sink.writers.Subjects, err = NewSheetWriter(sink.workbook, "Subjects_Results", SubjectHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *workbookSink) Writers() ResultWriters {
+	return s.writers
+}
+
+func (s *workbookSink) Close(summary Summary) error {
+	err := s.workbook.AddSummarySheet(summary)
+	if err == nil {
+		err = s.workbook.Save()
+	}
+	if err != nil {
+		return WriteError{Destination: s.workbook.outputPath, Err: err}
+	}
+	return nil
+}
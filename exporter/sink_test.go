@@ -0,0 +1,222 @@
+package exporter_test
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen/data"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+type recordingSink struct {
+	closedWith *Summary
+	writers    ResultWriters
+}
+
+func (s *recordingSink) Writers() ResultWriters {
+	return s.writers
+}
+
+func (s *recordingSink) Close(summary Summary) error {
+	s.closedWith = &summary
+	return nil
+}
+
+var _ = Describe("Sinks", func() {
+	var outputDir string
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("ParseOutputDestination", func() {
+		It("treats a plain folder as a destination without a scheme", func() {
+			Expect(ParseOutputDestination("/path/to/output")).To(Equal(OutputDestination{Folder: "/path/to/output"}))
+			Expect(ParseOutputDestination(`C:\output`)).To(Equal(OutputDestination{Folder: `C:\output`}))
+		})
+
+		It("splits off a registered scheme", func() {
+			Expect(ParseOutputDestination("jsonl:/path/to/output")).To(Equal(OutputDestination{Scheme: "jsonl", Folder: "/path/to/output"}))
+			Expect(ParseOutputDestination("sqlite:relative")).To(Equal(OutputDestination{Scheme: "sqlite", Folder: "relative"}))
+		})
+
+		It("rejects unknown schemes and missing folders", func() {
+			_, err := ParseOutputDestination("parquet:/path/to/output")
+			Expect(err).To(MatchError(`unknown output scheme "parquet" in "parquet:/path/to/output": expected one of csv, jsonl, sqlite, xlsx`))
+
+			_, err = ParseOutputDestination("csv:")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("creates sinks registered for a new scheme", func() {
+		sink := &recordingSink{}
+		RegisterSink("recording", func(options SinkOptions) (Sink, error) {
+			return sink, nil
+		})
+
+		Expect(ParseOutputDestination("recording:" + outputDir)).To(Equal(OutputDestination{Scheme: "recording", Folder: outputDir}))
+		created, err := NewSink("recording", SinkOptions{OutputFolder: outputDir})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(CloseSinks([]Sink{created}, Summary{County: "SAN JOAQUIN"})).To(Succeed())
+		Expect(sink.closedWith.County).To(Equal("SAN JOAQUIN"))
+	})
+
+	It("returns write errors with the file they happened in", func() {
+		writer, err := NewWriter(utilities.OutputFiles{}, "/dev/full", []string{"SUBJECT_ID"})
+		Expect(err).ToNot(HaveOccurred())
+		otherWriter, err := NewWriter(utilities.OutputFiles{}, path.Join(outputDir, "Other.csv"), []string{"SUBJECT_ID"})
+		Expect(err).ToNot(HaveOccurred())
+
+		multiWriter := NewMultiDOJWriter(writer, otherWriter)
+		multiWriter.Write([]string{"100"})
+		err = multiWriter.Flush()
+
+		Expect(err).To(BeAssignableToTypeOf(WriteErrors{}))
+		Expect(err.(WriteErrors)).To(HaveLen(1))
+		Expect(err.(WriteErrors)[0].Destination).To(Equal("/dev/full"))
+	})
+
+	It("keeps the first write error and reports it from Error and Flush", func() {
+		writer, err := NewWriter(utilities.OutputFiles{}, "/dev/full", []string{"SUBJECT_ID"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Error()).ToNot(HaveOccurred())
+
+		for row := 0; row < 10000 && writer.Error() == nil; row++ {
+			writer.Write([]string{"100"})
+		}
+		Expect(writer.Error()).To(MatchError(ContainSubstring("no space left on device")))
+		Expect(writer.Flush()).To(MatchError(writer.Error()))
+	})
+
+	It("writes each result set to a table in a SQLite database", func() {
+		sink, err := NewSink("sqlite", SinkOptions{OutputFolder: path.Join(outputDir, "db"), FileIndex: 2, FileCount: 2})
+		Expect(err).ToNot(HaveOccurred())
+
+		writers := sink.Writers()
+		info := &data.EligibilityInfo{}
+		info.SetEligibleForDismissal("Dismiss all HS 11357 convictions")
+		entry := make([]string, len(DojFullHeaders))
+		entry[data.SUBJECT_ID] = "100"
+		parsedRow := data.NewDOJRow(entry, 0, data.Calendar{})
+		writers.DOJ.WriteEntryWithEligibilityInfo(entry, &parsedRow, info, "")
+		writers.DOJ.WriteEntryWithEligibilityInfo(entry, &parsedRow, nil, "")
+		writers.Subjects.Write([]string{"100", "SKYWALKER,LUKE"})
+		Expect(writers.DOJ.Flush()).To(Succeed())
+		Expect(writers.Subjects.Flush()).To(Succeed())
+		Expect(sink.Close(Summary{})).To(Succeed())
+
+		databasePath := path.Join(outputDir, "db", "Results_2.db")
+		Expect(databasePath).To(BeAnExistingFile())
+		database, err := sql.Open("sqlite3", databasePath)
+		Expect(err).ToNot(HaveOccurred())
+		defer database.Close()
+
+		var count int
+		Expect(database.QueryRow("SELECT COUNT(*) FROM all_results WHERE subject_id = ?", "100").Scan(&count)).To(Succeed())
+		Expect(count).To(Equal(2))
+		Expect(database.QueryRow("SELECT COUNT(*) FROM all_results WHERE eligibility_determination = ?", "Eligible for Dismissal").Scan(&count)).To(Succeed())
+		Expect(count).To(Equal(1))
+
+		var name string
+		Expect(database.QueryRow("SELECT pri_name FROM subjects_results WHERE subject_id = ?", "100").Scan(&name)).To(Succeed())
+		Expect(name).To(Equal("SKYWALKER,LUKE"))
+	})
+
+	It("writes normalized tables and the run to a SQLite database", func() {
+		computeAt := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+		dojInformation, gogenErr := data.NewDOJInformation(path.Join("..", "test_fixtures", "extra_comma.csv"), computeAt, data.Calendar{}, data.ConfigurableEligibilityFlow{})
+		Expect(gogenErr.ErrorType).To(BeEmpty())
+		eligibilities := dojInformation.DetermineEligibility("SAN JOAQUIN", data.EligibilityFlows["DISMISS ALL PROP 64"])
+
+		sink, err := NewSink("sqlite", SinkOptions{OutputFolder: outputDir, Run: &RunMetadata{GogenVersion: "1.2.3", County: "SAN JOAQUIN", ComputeAt: computeAt, InputFile: "extra_comma.csv", FinishedAt: computeAt.Add(time.Hour)}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.(EligibilitySink).WriteEligibilities(dojInformation, nil, eligibilities)).To(Succeed())
+		Expect(sink.Close(Summary{County: "SAN JOAQUIN", LineCount: len(dojInformation.Rows)})).To(Succeed())
+
+		database, err := sql.Open("sqlite3", path.Join(outputDir, "Results.db"))
+		Expect(err).ToNot(HaveOccurred())
+		defer database.Close()
+
+		count := func(query string, args ...interface{}) int {
+			var result int
+			Expect(database.QueryRow(query, args...).Scan(&result)).To(Succeed())
+			return result
+		}
+		Expect(count("SELECT COUNT(*) FROM raw_rows")).To(Equal(len(dojInformation.Rows)))
+		Expect(count("SELECT COUNT(*) FROM subjects")).To(Equal(len(dojInformation.Subjects)))
+		Expect(count("SELECT COUNT(*) FROM convictions WHERE subject_id = ?", "17954908")).To(Equal(len(dojInformation.Subjects["17954908"].Convictions)))
+		Expect(count("SELECT COUNT(*) FROM eligibility")).To(Equal(len(eligibilities)))
+		Expect(count("SELECT COUNT(*) FROM eligibility WHERE determination = ?", "Eligible for Dismissal")).To(BeNumerically(">", 0))
+
+		var version, county, computeAtDate string
+		var lineCount int
+		Expect(database.QueryRow("SELECT gogen_version, county, compute_at, line_count FROM run").Scan(&version, &county, &computeAtDate, &lineCount)).To(Succeed())
+		Expect([]interface{}{version, county, computeAtDate, lineCount}).To(Equal([]interface{}{"1.2.3", "SAN JOAQUIN", "2019-11-11", len(dojInformation.Rows)}))
+		var finishedAt string
+		Expect(database.QueryRow("SELECT finished_at FROM run").Scan(&finishedAt)).To(Succeed())
+		Expect(finishedAt).To(Equal("2019-11-11T01:00:00Z"))
+
+		rows, err := database.Query("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'convictions'")
+		Expect(err).ToNot(HaveOccurred())
+		var indexes []string
+		for rows.Next() {
+			var name string
+			Expect(rows.Scan(&name)).To(Succeed())
+			indexes = append(indexes, name)
+		}
+		Expect(indexes).To(ConsistOf("convictions_subject_id", "convictions_county", "convictions_code_section"))
+	})
+
+	It("links the convictions and eligibility of merged subjects to the subject they were merged into", func() {
+		computeAt := time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)
+		dojInformation, gogenErr := data.NewDOJInformation(path.Join("..", "test_fixtures", "extra_comma.csv"), computeAt, data.Calendar{}, data.ConfigurableEligibilityFlow{})
+		Expect(gogenErr.ErrorType).To(BeEmpty())
+		var subjectIDs []string
+		for subjectID := range dojInformation.Subjects {
+			subjectIDs = append(subjectIDs, subjectID)
+		}
+		sort.Strings(subjectIDs)
+		Expect(dojInformation.Subjects[subjectIDs[1]].Convictions).ToNot(BeEmpty())
+		dojInformation.Subjects[subjectIDs[0]].Merge(dojInformation.Subjects[subjectIDs[1]])
+		delete(dojInformation.Subjects, subjectIDs[1])
+		eligibilities := dojInformation.DetermineEligibility("SAN JOAQUIN", data.EligibilityFlows["DISMISS ALL PROP 64"])
+
+		sink, err := NewSink("sqlite", SinkOptions{OutputFolder: outputDir})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.(EligibilitySink).WriteEligibilities(dojInformation, nil, eligibilities)).To(Succeed())
+		Expect(sink.Close(Summary{})).To(Succeed())
+
+		database, err := sql.Open("sqlite3", path.Join(outputDir, "Results.db"))
+		Expect(err).ToNot(HaveOccurred())
+		defer database.Close()
+
+		count := func(query string, args ...interface{}) int {
+			var result int
+			Expect(database.QueryRow(query, args...).Scan(&result)).To(Succeed())
+			return result
+		}
+		Expect(count("SELECT COUNT(*) FROM convictions WHERE subject_id = ?", subjectIDs[1])).To(Equal(0))
+		Expect(count("SELECT COUNT(*) FROM eligibility WHERE subject_id = ?", subjectIDs[1])).To(Equal(0))
+		Expect(count("SELECT COUNT(*) FROM convictions WHERE subject_id = ?", subjectIDs[0])).To(Equal(len(dojInformation.Subjects[subjectIDs[0]].Convictions)))
+	})
+
+	It("replaces an existing SQLite database", func() {
+		for run := 0; run < 2; run++ {
+			sink, err := NewSink("sqlite", SinkOptions{OutputFolder: outputDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sink.Close(Summary{})).To(Succeed())
+		}
+		_, err := os.Stat(path.Join(outputDir, "Results.db"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,323 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"gogen/data"
+	"sort"
+	"strings"
+	"time"
+)
+
+var sqliteSchema = []string{
+	`CREATE TABLE run (
+		gogen_version TEXT,
+		county TEXT,
+		compute_at TEXT,
+		input_file TEXT,
+		eligibility_options TEXT,
+		started_at TEXT,
+		finished_at TEXT,
+		line_count INTEGER,
+		summary TEXT
+	)`,
+	`CREATE TABLE subjects (
+		subject_id TEXT PRIMARY KEY,
+		name TEXT,
+		dob TEXT,
+		cii_number TEXT,
+		fbi_number TEXT,
+		linked_subject_ids TEXT,
+		pc290_registration INTEGER,
+		is_deceased INTEGER
+	)`,
+	`CREATE TABLE convictions (
+		row_index INTEGER PRIMARY KEY,
+		subject_id TEXT REFERENCES subjects (subject_id),
+		county TEXT,
+		code_section TEXT,
+		is_felony INTEGER,
+		felony_status_unknown INTEGER,
+		disposition_date TEXT,
+		sentence_end_date TEXT,
+		ofn TEXT,
+		count_order TEXT,
+		court_case_number TEXT
+	)`,
+	`CREATE TABLE eligibility (
+		row_index INTEGER PRIMARY KEY REFERENCES convictions (row_index),
+		subject_id TEXT REFERENCES subjects (subject_id),
+		case_number TEXT,
+		number_of_convictions_on_record INTEGER,
+		occurred_after_effective_date TEXT,
+		superstrikes TEXT,
+		pc290_code_sections TEXT,
+		pc290_registration TEXT,
+		date_of_conviction TEXT,
+		years_since_this_conviction REAL,
+		years_since_most_recent_conviction REAL,
+		number_of_prop64_convictions INTEGER,
+		number_of_11357_convictions INTEGER,
+		number_of_11358_convictions INTEGER,
+		number_of_11359_convictions INTEGER,
+		number_of_11360_convictions INTEGER,
+		deceased TEXT,
+		determination TEXT,
+		reason TEXT,
+		court_docket_number TEXT,
+		court_match_confidence REAL
+	)`,
+}
+
+var sqliteIndexes = []string{
+	"CREATE INDEX raw_rows_subject_id ON raw_rows (subject_id)",
+	"CREATE INDEX raw_rows_county ON raw_rows (county)",
+	"CREATE INDEX convictions_subject_id ON convictions (subject_id)",
+	"CREATE INDEX convictions_county ON convictions (county)",
+	"CREATE INDEX convictions_code_section ON convictions (code_section)",
+	"CREATE INDEX eligibility_subject_id ON eligibility (subject_id)",
+	"CREATE INDEX eligibility_determination ON eligibility (determination)",
+}
+
+var eligibilityColumns = []string{
+	"row_index",
+	"subject_id",
+	"case_number",
+	"number_of_convictions_on_record",
+	"occurred_after_effective_date",
+	"superstrikes",
+	"pc290_code_sections",
+	"pc290_registration",
+	"date_of_conviction",
+	"years_since_this_conviction",
+	"years_since_most_recent_conviction",
+	"number_of_prop64_convictions",
+	"number_of_11357_convictions",
+	"number_of_11358_convictions",
+	"number_of_11359_convictions",
+	"number_of_11360_convictions",
+	"deceased",
+	"determination",
+	"reason",
+	"court_docket_number",
+	"court_match_confidence",
+}
+
+type RunMetadata struct {
+	GogenVersion       string
+	County             string
+	ComputeAt          time.Time
+	InputFile          string
+	EligibilityOptions string
+	StartedAt          time.Time
+	FinishedAt         time.Time
+}
+
+func (s *sqliteSink) createSchema() error {
+	rawRowColumns := []string{"row_index INTEGER PRIMARY KEY", "county TEXT"}
+	for _, header := range s.rawRowHeaders {
+		rawRowColumns = append(rawRowColumns, SQLColumnName(header)+" TEXT")
+	}
+	schema := append([]string{fmt.Sprintf("CREATE TABLE raw_rows (%s)", strings.Join(rawRowColumns, ", "))}, sqliteSchema...)
+
+	for _, statement := range schema {
+		_, err := s.transaction.Exec(statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteSink) WriteEligibilities(dojInformation *data.DOJInformation, rowIndexes []int, eligibilities map[int]*data.EligibilityInfo) error {
+	rawRowColumns := []string{"row_index", "county"}
+	for _, header := range s.rawRowHeaders {
+		rawRowColumns = append(rawRowColumns, SQLColumnName(header))
+	}
+	allRows := rowIndexes == nil
+	rowIndexes = exportedRowIndexes(dojInformation, rowIndexes)
+	writtenRows := make(map[int]bool)
+	for _, index := range rowIndexes {
+		writtenRows[index] = true
+	}
+	err := s.insertRows("raw_rows", rawRowColumns, len(rowIndexes), func(index int) []interface{} {
+		rowIndex := rowIndexes[index]
+		row := dojInformation.Rows[rowIndex]
+		values := []interface{}{rowIndex, dojInformation.ParsedRow(rowIndex).County}
+		for _, header := range s.rawRowHeaders {
+			values = append(values, s.redactor.RedactValue(header, strings.TrimSpace(row[dojColumnIndex[header]])))
+		}
+		return values
+	})
+	if err != nil {
+		return err
+	}
+
+	var subjectIDs []string
+	for subjectID, subject := range dojInformation.Subjects {
+		if allRows || hasWrittenConviction(subject, writtenRows) {
+			subjectIDs = append(subjectIDs, subjectID)
+		}
+	}
+	sort.Strings(subjectIDs)
+
+	err = s.insertRows("subjects", []string{"subject_id", "name", "dob", "cii_number", "fbi_number", "linked_subject_ids", "pc290_registration", "is_deceased"}, len(subjectIDs), func(index int) []interface{} {
+		subject := dojInformation.Subjects[subjectIDs[index]]
+		return []interface{}{
+			s.redactor.RedactValue("SUBJECT_ID", subjectIDs[index]),
+			s.redactor.RedactValue("PRI_NAME", subject.Name),
+			s.redactedDate("PRI_DOB", subject.DOB),
+			s.redactor.RedactValue("CII_NUMBER", subject.CII),
+			s.redactor.RedactValue("FBI_NUMBER", subject.FBINumber),
+			s.redactor.RedactValue("LINKED_SUBJECT_ID", strings.Join(subject.LinkedSubjectIDs, "; ")),
+			subject.PC290Registration,
+			subject.IsDeceased,
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Rows keep the SUBJECT_ID they were read with, but identity resolution can
+	// merge subjects, so convictions and eligibility use the subject they ended up in.
+	var convictions []*data.DOJRow
+	subjectIDByRow := make(map[int]string)
+	for _, subjectID := range subjectIDs {
+		for _, conviction := range dojInformation.Subjects[subjectID].Convictions {
+			if !writtenRows[conviction.Index] {
+				continue
+			}
+			convictions = append(convictions, conviction)
+			subjectIDByRow[conviction.Index] = subjectID
+		}
+	}
+	sort.Slice(convictions, func(a, b int) bool { return convictions[a].Index < convictions[b].Index })
+
+	err = s.insertRows("convictions", []string{"row_index", "subject_id", "county", "code_section", "is_felony", "felony_status_unknown", "disposition_date", "sentence_end_date", "ofn", "count_order", "court_case_number"}, len(convictions), func(index int) []interface{} {
+		conviction := convictions[index]
+		return []interface{}{conviction.Index, s.redactor.RedactValue("SUBJECT_ID", subjectIDByRow[conviction.Index]), conviction.County, conviction.CodeSection, conviction.IsFelony, conviction.FelonyStatusUnknown, sqlDate(conviction.DispositionDate), sqlDate(conviction.SentenceEndDate), s.redactor.RedactValue("OFN", strings.TrimSpace(conviction.OFN)), conviction.CountOrder, s.redactor.RedactValue("FE_NUM_CRT_CASE", conviction.NumCrtCase)}
+	})
+	if err != nil {
+		return err
+	}
+
+	var eligibleIndexes []int
+	for index := range eligibilities {
+		eligibleIndexes = append(eligibleIndexes, index)
+	}
+	sort.Ints(eligibleIndexes)
+
+	return s.insertRows("eligibility", eligibilityColumns, len(eligibleIndexes), func(index int) []interface{} {
+		rowIndex := eligibleIndexes[index]
+		info := s.redactor.RedactEligibility(eligibilities[rowIndex])
+		subjectID, ok := subjectIDByRow[rowIndex]
+		if !ok {
+			subjectID = dojInformation.Rows[rowIndex][data.SUBJECT_ID]
+		}
+		var courtMatchConfidence interface{}
+		if info.CourtDocketNumber != "" {
+			courtMatchConfidence = info.CourtMatchConfidence
+		}
+		return []interface{}{
+			rowIndex,
+			s.redactor.RedactValue("SUBJECT_ID", subjectID),
+			info.CaseNumber,
+			info.NumberOfConvictionsOnRecord,
+			info.OccurredAfterEffectiveDate,
+			info.Superstrikes,
+			info.PC290CodeSections,
+			info.PC290Registration,
+			sqlDate(info.DateOfConviction),
+			info.YearsSinceThisConviction,
+			info.YearsSinceMostRecentConviction,
+			info.NumberOfProp64Convictions,
+			info.NumberOf11357Convictions,
+			info.NumberOf11358Convictions,
+			info.NumberOf11359Convictions,
+			info.NumberOf11360Convictions,
+			info.Deceased,
+			info.EligibilityDetermination,
+			info.EligibilityReason,
+			info.CourtDocketNumber,
+			courtMatchConfidence,
+		}
+	})
+}
+
+func hasWrittenConviction(subject *data.Subject, writtenRows map[int]bool) bool {
+	for _, conviction := range subject.Convictions {
+		if writtenRows[conviction.Index] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sqliteSink) writeRun(summary Summary) error {
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	_, err = s.transaction.Exec(
+		"INSERT INTO run (gogen_version, county, compute_at, input_file, eligibility_options, started_at, finished_at, line_count, summary) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		s.run.GogenVersion,
+		s.run.County,
+		sqlDate(s.run.ComputeAt),
+		s.run.InputFile,
+		s.run.EligibilityOptions,
+		sqlTimestamp(s.run.StartedAt),
+		sqlTimestamp(s.run.FinishedAt),
+		summary.LineCount,
+		string(summaryJSON),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range sqliteIndexes {
+		_, err = s.transaction.Exec(statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteSink) insertRows(table string, columns []string, rowCount int, values func(index int) []interface{}) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	statement, err := s.transaction.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	for index := 0; index < rowCount; index++ {
+		_, err = statement.Exec(values(index)...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteSink) redactedDate(header string, val time.Time) interface{} {
+	date := sqlDate(val)
+	if date == nil || s.redactor == nil {
+		return date
+	}
+	return s.redactor.RedactValue(header, date.(string))
+}
+
+func sqlDate(val time.Time) interface{} {
+	if val.IsZero() {
+		return nil
+	}
+	return val.Format("2006-01-02")
+}
+
+func sqlTimestamp(val time.Time) interface{} {
+	if val.IsZero() {
+		return nil
+	}
+	return val.Format(time.RFC3339)
+}
@@ -0,0 +1,187 @@
+package exporter
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gogen/utilities"
+	"os"
+	"regexp"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var unsafeColumnNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+type sqliteSink struct {
+	files         utilities.OutputFiles
+	outputPath    string
+	database      *sql.DB
+	transaction   *sql.Tx
+	writers       ResultWriters
+	run           *RunMetadata
+	rawRowHeaders []string
+	redactor      *Redactor
+}
+
+type sqliteTableWriter struct {
+	statement   *sql.Stmt
+	columnCount int
+	err         error
+}
+
+func newSQLiteSink(options SinkOptions) (Sink, error) {
+	if options.Files.Encrypted() {
+		return nil, errors.New("sqlite output can't be encrypted: use csv, jsonl or xlsx")
+	}
+
+	outputPath := options.fileName("Results%s.db")
+	err := os.Remove(outputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	database, err := sql.Open("sqlite3", outputPath)
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := database.Begin()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	run := options.Run
+	if run == nil {
+		run = &RunMetadata{}
+	}
+	sink := &sqliteSink{files: options.Files, outputPath: outputPath, database: database, transaction: transaction, run: run, rawRowHeaders: DojFullHeaders, redactor: options.Redactor}
+	if columns, ok := options.Profile.Columns("All_Results"); ok {
+		sink.rawRowHeaders = profileRawRowHeaders(columns)
+	}
+
+	tables := []struct {
+		writer            *DOJWriter
+		output            string
+		name              string
+		headers           []string
+		courtMatchColumns bool
+	}{
+		{&sink.writers.DOJ, "All_Results", "all_results", resultHeaders(DojFullHeaders, options.CourtMatchColumns), options.CourtMatchColumns},
+		{&sink.writers.Condensed, "All_Results_Condensed", "all_results_condensed", resultHeaders(DojCondensedHeaders, options.CourtMatchColumns), options.CourtMatchColumns},
+		{&sink.writers.Prop64Convictions, "Prop64_Results", "prop64_results", resultHeaders(DojFullHeaders, options.CourtMatchColumns), options.CourtMatchColumns},
+		{&sink.writers.HandReview, "Hand_Review", "hand_review", resultHeaders(DojFullHeaders, options.CourtMatchColumns), options.CourtMatchColumns},
+		{&sink.writers.Subjects, "", "subjects_results", SubjectHeaders, false},
+	}
+	for _, table := range tables {
+		columns, profiled := options.Profile.Columns(table.output)
+		if profiled {
+			table.headers, table.courtMatchColumns = columns.Headers, false
+		}
+		writer, err := sink.newTableWriter(table.name, table.headers, table.courtMatchColumns)
+		if err != nil {
+			transaction.Rollback()
+			database.Close()
+			return nil, err
+		}
+		if profiled {
+			columns = columns.WithRedactor(options.Redactor)
+			writer.columns = &columns
+		}
+		*table.writer = writer
+	}
+
+	err = sink.createSchema()
+	if err != nil {
+		transaction.Rollback()
+		database.Close()
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *sqliteSink) newTableWriter(table string, headers []string, courtMatchColumns bool) (*csvWriter, error) {
+	var columns, placeholders []string
+	for _, header := range headers {
+		columns = append(columns, SQLColumnName(header)+" TEXT")
+		placeholders = append(placeholders, "?")
+	}
+
+	_, err := s.transaction.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(columns, ", ")))
+	if err != nil {
+		return nil, err
+	}
+
+	var columnNames []string
+	for _, header := range headers {
+		columnNames = append(columnNames, SQLColumnName(header))
+	}
+	statement, err := s.transaction.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columnNames, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{
+		outputFileWriter:  &sqliteTableWriter{statement: statement, columnCount: len(headers)},
+		filename:          s.outputPath + ":" + table,
+		courtMatchColumns: courtMatchColumns,
+	}, nil
+}
+
+func profileRawRowHeaders(columns ColumnSelection) []string {
+	selected := append([]string{"SUBJECT_ID"}, columns.DOJColumns()...)
+	var headers []string
+	for _, header := range DojFullHeaders {
+		if containsString(selected, header) {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+func (s *sqliteSink) Writers() ResultWriters {
+	return s.writers
+}
+
+func (s *sqliteSink) Close(summary Summary) error {
+	err := s.writeRun(summary)
+	if err == nil {
+		err = s.transaction.Commit()
+	} else {
+		s.transaction.Rollback()
+	}
+	closeErr := s.database.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return WriteError{Destination: s.outputPath, Err: err}
+	}
+	s.files.Record(s.outputPath)
+	return nil
+}
+
+func (w *sqliteTableWriter) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	values := make([]interface{}, w.columnCount)
+	for index := range values {
+		if index < len(record) {
+			values[index] = record[index]
+		}
+	}
+	_, w.err = w.statement.Exec(values...)
+	return w.err
+}
+
+func (w *sqliteTableWriter) Flush() {}
+
+func (w *sqliteTableWriter) Error() error {
+	return w.err
+}
+
+func SQLColumnName(header string) string {
+	return strings.Trim(unsafeColumnNameCharacters.ReplaceAllString(strings.ToLower(header), "_"), "_")
+}
@@ -0,0 +1,32 @@
+package exporter
+
+import "sort"
+
+type StatewideSummary struct {
+	Counties                []string           `json:"counties"`
+	LineCount               int                `json:"lineCount"`
+	ProcessingTimeInSeconds float64            `json:"processingTimeInSeconds"`
+	Statewide               Summary            `json:"statewide"`
+	CountySummaries         map[string]Summary `json:"countySummaries"`
+	Manifest                *Manifest          `json:"manifest,omitempty"`
+}
+
+func NewStatewideSummary(countySummaries map[string]Summary, lineCount int) StatewideSummary {
+	var d DataExporter
+	var counties []string
+	var statewide Summary
+	for county, summary := range countySummaries {
+		counties = append(counties, county)
+		statewide = d.AccumulateSummaryData(statewide, summary)
+	}
+	sort.Strings(counties)
+	statewide.County = "ALL"
+	statewide.LineCount = lineCount
+
+	return StatewideSummary{
+		Counties:        counties,
+		LineCount:       lineCount,
+		Statewide:       statewide,
+		CountySummaries: countySummaries,
+	}
+}
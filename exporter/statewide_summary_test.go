@@ -0,0 +1,45 @@
+package exporter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "gogen/exporter"
+	"time"
+)
+
+var _ = Describe("StatewideSummary", func() {
+	It("adds up the county summaries", func() {
+		countySummaries := map[string]Summary{
+			"YOLO": {
+				County:                      "YOLO",
+				LineCount:                   38,
+				EarliestConviction:          time.Date(1983, 6, 1, 0, 0, 0, 0, time.UTC),
+				SubjectsWithSomeReliefCount: 2,
+				ConvictionDismissalCountByCodeSection: map[string]int{
+					"11357": 1,
+				},
+			},
+			"SACRAMENTO": {
+				County:                      "SACRAMENTO",
+				LineCount:                   38,
+				EarliestConviction:          time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC),
+				SubjectsWithSomeReliefCount: 12,
+				ConvictionDismissalCountByCodeSection: map[string]int{
+					"11357": 2,
+					"11358": 6,
+				},
+			},
+		}
+
+		summary := NewStatewideSummary(countySummaries, 38)
+
+		Expect(summary.Counties).To(Equal([]string{"SACRAMENTO", "YOLO"}))
+		Expect(summary.LineCount).To(Equal(38))
+		Expect(summary.Statewide.County).To(Equal("ALL"))
+		Expect(summary.Statewide.LineCount).To(Equal(38))
+		Expect(summary.Statewide.EarliestConviction).To(Equal(time.Date(1979, 6, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(summary.Statewide.SubjectsWithSomeReliefCount).To(Equal(14))
+		Expect(summary.Statewide.ConvictionDismissalCountByCodeSection).To(Equal(map[string]int{"11357": 3, "11358": 6}))
+		Expect(summary.CountySummaries["YOLO"].SubjectsWithSomeReliefCount).To(Equal(2))
+	})
+})
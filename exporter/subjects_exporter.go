@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"gogen/utilities"
+	"sort"
+	"strings"
+)
+
+var subjectDeterminations = []string{
+	"Eligible for Dismissal",
+	"Eligible for Reduction",
+	"Maybe Eligible - Flag for Review",
+	"Hand Review",
+	"Previously Processed",
+	"Not eligible",
+}
+
+var SubjectHeaders = []string{
+	"SUBJECT_ID",
+	"PRI_NAME",
+	"PRI_DOB",
+	"CII_NUMBER",
+	"# of convictions on record",
+	"# Eligible for Dismissal",
+	"# Eligible for Reduction",
+	"# Maybe Eligible - Flag for Review",
+	"# Hand Review",
+	"# Previously Processed",
+	"# Not eligible",
+	"No Felony After Relief",
+	"No Conviction After Relief",
+	"Case Numbers",
+}
+
+func NewSubjectsWriter(files utilities.OutputFiles, outputFilePath string) (DOJWriter, error) {
+	return NewWriter(files, outputFilePath, SubjectHeaders)
+}
+
+func (d *DataExporter) exportSubjects() error {
+	var subjectIDs []string
+	for id := range d.dojInformation.Subjects {
+		subjectIDs = append(subjectIDs, id)
+	}
+	sort.Strings(subjectIDs)
+
+	for _, id := range subjectIDs {
+		subject := d.dojInformation.Subjects[id]
+		countsByDetermination := make(map[string]int)
+		var caseNumbers []string
+		for _, conviction := range subject.Convictions {
+			info := d.normalFlowEligibilities[conviction.Index]
+			if info == nil {
+				continue
+			}
+			countsByDetermination[info.EligibilityDetermination]++
+			for _, caseNumber := range subject.CaseNumbersFor(conviction) {
+				if !containsString(caseNumbers, caseNumber) {
+					caseNumbers = append(caseNumbers, caseNumber)
+				}
+			}
+		}
+		if len(countsByDetermination) == 0 {
+			continue
+		}
+
+		row := []string{
+			subject.ID,
+			subject.Name,
+			writeDate(subject.DOB),
+			subject.CII,
+			writeInt(len(subject.Convictions)),
+		}
+		for _, determination := range subjectDeterminations {
+			row = append(row, writeInt(countsByDetermination[determination]))
+		}
+		row = append(row,
+			writeYesNo(subject.NoLongerHasFelony(d.normalFlowEligibilities)),
+			writeYesNo(subject.NoLongerHasConviction(d.normalFlowEligibilities)),
+			strings.Join(caseNumbers, "; "),
+		)
+		d.outputSubjectsWriter.Write(row)
+	}
+	return d.outputSubjectsWriter.Flush()
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writeYesNo(val bool) string {
+	if val {
+		return "Y"
+	}
+	return "N"
+}
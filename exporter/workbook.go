@@ -0,0 +1,219 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"gogen/utilities"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+var workbookDateColumns = map[string]bool{
+	"REQ_DOB":            true,
+	"PRI_DOB":            true,
+	"CYC_DATE":           true,
+	"STP_EVENT_DATE":     true,
+	"DISP_DATE":          true,
+	"Date of Conviction": true,
+}
+
+var workbookDateLayouts = []string{"20060102", "01/02/2006"}
+
+// Excel opens at most this many rows in a sheet, header included.
+var MaxSheetRows = 1048576
+
+type Workbook struct {
+	File       *xlsx.File
+	files      utilities.OutputFiles
+	outputPath string
+	err        error
+}
+
+type sheetWriter struct {
+	workbook *Workbook
+	sheet    *xlsx.Sheet
+	headers  []string
+	err      error
+}
+
+func NewWorkbook(files utilities.OutputFiles, outputFilePath string) *Workbook {
+	return &Workbook{File: xlsx.NewFile(), files: files, outputPath: outputFilePath}
+}
+
+func NewSheetWriter(workbook *Workbook, sheetName string, headers []string) (DOJWriter, error) {
+	return newSheetWriter(workbook, sheetName, headers, false)
+}
+
+func NewDOJSheetWriter(workbook *Workbook, sheetName string, courtMatchColumns bool) (DOJWriter, error) {
+	return newSheetWriter(workbook, sheetName, resultHeaders(DojFullHeaders, courtMatchColumns), courtMatchColumns)
+}
+
+func NewCondensedDOJSheetWriter(workbook *Workbook, sheetName string, courtMatchColumns bool) (DOJWriter, error) {
+	return newSheetWriter(workbook, sheetName, resultHeaders(DojCondensedHeaders, courtMatchColumns), courtMatchColumns)
+}
+
+func NewProfiledSheetWriter(workbook *Workbook, sheetName string, columns ColumnSelection) (DOJWriter, error) {
+	w, err := newSheetWriter(workbook, sheetName, columns.Headers, false)
+	if err != nil {
+		return nil, err
+	}
+	w.columns = &columns
+	return w, nil
+}
+
+func newSheetWriter(workbook *Workbook, sheetName string, headers []string, courtMatchColumns bool) (*csvWriter, error) {
+	sheet, err := workbook.File.AddSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	sheet.SheetViews = []xlsx.SheetView{{Pane: &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"}}}
+
+	row := sheet.AddRow()
+	for _, header := range headers {
+		row.AddCell().SetString(header)
+	}
+
+	return &csvWriter{
+		outputFileWriter:  &sheetWriter{workbook: workbook, sheet: sheet, headers: headers},
+		filename:          sheetName,
+		courtMatchColumns: courtMatchColumns,
+	}, nil
+}
+
+func (w *sheetWriter) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.sheet.Rows) >= MaxSheetRows {
+		w.err = fmt.Errorf("sheet %s has more than the %d rows Excel can open; write this output as csv: or jsonl: instead", w.sheet.Name, MaxSheetRows)
+		if w.workbook.err == nil {
+			w.workbook.err = w.err
+		}
+		return w.err
+	}
+	row := w.sheet.AddRow()
+	for index, value := range record {
+		header := ""
+		if index < len(w.headers) {
+			header = w.headers[index]
+		}
+		setTypedCell(row.AddCell(), header, value)
+	}
+	return nil
+}
+
+func (w *sheetWriter) Error() error {
+	return w.err
+}
+
+func (w *sheetWriter) Flush() {
+	w.sheet.AutoFilter = &xlsx.AutoFilter{
+		TopLeftCell:     "A1",
+		BottomRightCell: xlsx.GetCellIDStringFromCoords(len(w.headers)-1, len(w.sheet.Rows)-1),
+	}
+}
+
+func (w *Workbook) AddSummarySheet(summary Summary) error {
+	sheet, err := w.File.AddSheet("Summary")
+	if err != nil {
+		return err
+	}
+
+	summaryBytes, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	err = json.Unmarshal(summaryBytes, &values)
+	if err != nil {
+		return err
+	}
+
+	header := sheet.AddRow()
+	header.AddCell().SetString("Statistic")
+	header.AddCell().SetString("Value")
+	for _, entry := range flattenSummary("", values) {
+		row := sheet.AddRow()
+		row.AddCell().SetString(entry[0])
+		setTypedCell(row.AddCell(), "", entry[1])
+	}
+	sheet.SheetViews = []xlsx.SheetView{{Pane: &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"}}}
+	return nil
+}
+
+func (w *Workbook) Save() error {
+	if w.err != nil {
+		return w.err
+	}
+	outputFile, err := w.files.Create(w.outputPath)
+	if err != nil {
+		return err
+	}
+	err = w.File.Write(outputFile)
+	if err != nil {
+		outputFile.Close()
+		return err
+	}
+	return outputFile.Close()
+}
+
+func flattenSummary(prefix string, values map[string]interface{}) [][2]string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var entries [][2]string
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch value := values[key].(type) {
+		case map[string]interface{}:
+			entries = append(entries, flattenSummary(name, value)...)
+		case []interface{}:
+			var items []string
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			entries = append(entries, [2]string{name, strings.Join(items, "; ")})
+		case nil:
+			entries = append(entries, [2]string{name, ""})
+		default:
+			entries = append(entries, [2]string{name, fmt.Sprint(value)})
+		}
+	}
+	return entries
+}
+
+func setTypedCell(cell *xlsx.Cell, header string, value string) {
+	if workbookDateColumns[header] {
+		for _, layout := range workbookDateLayouts {
+			if date, err := time.Parse(layout, value); err == nil {
+				cell.SetDate(date)
+				return
+			}
+		}
+	}
+	if isNumericColumn(header) {
+		if number, err := strconv.Atoi(value); err == nil {
+			cell.SetInt(number)
+			return
+		}
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			cell.SetFloat(number)
+			return
+		}
+	}
+	cell.SetString(value)
+}
+
+func isNumericColumn(header string) bool {
+	return header == "" || strings.HasPrefix(header, "# ") || strings.HasPrefix(header, "Years Since") || header == "Court Match Confidence"
+}
@@ -0,0 +1,84 @@
+package exporter_test
+
+import (
+	"io/ioutil"
+	path "path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tealeg/xlsx"
+	. "gogen/exporter"
+	"gogen/utilities"
+)
+
+var _ = Describe("Workbook", func() {
+	var (
+		outputPath string
+		workbook   *Workbook
+		writer     DOJWriter
+	)
+
+	BeforeEach(func() {
+		outputDir, err := ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		outputPath = path.Join(outputDir, "Results.xlsx")
+
+		workbook = NewWorkbook(utilities.OutputFiles{}, outputPath)
+		writer, err = NewSheetWriter(workbook, "Subjects_Results", []string{"CII_NUMBER", "PRI_DOB", "# of convictions on record"})
+		Expect(err).ToNot(HaveOccurred())
+		writer.Write([]string{"0123456789", "08/22/1985", "3"})
+		writer.Write([]string{"A234698573", "", "1"})
+		writer.Flush()
+	})
+
+	It("freezes the header row and filters every column", func() {
+		sheet := workbook.File.Sheet["Subjects_Results"]
+
+		Expect(sheet.SheetViews[0].Pane.State).To(Equal("frozen"))
+		Expect(sheet.SheetViews[0].Pane.YSplit).To(Equal(1.0))
+		Expect(sheet.AutoFilter.TopLeftCell).To(Equal("A1"))
+		Expect(sheet.AutoFilter.BottomRightCell).To(Equal("C3"))
+	})
+
+	It("keeps IDs as text and writes dates and counts as numbers", func() {
+		rows := workbook.File.Sheet["Subjects_Results"].Rows
+
+		Expect(rows[1].Cells[0].Type()).To(Equal(xlsx.CellTypeString))
+		Expect(rows[1].Cells[0].Value).To(Equal("0123456789"))
+		Expect(rows[1].Cells[1].Type()).To(Equal(xlsx.CellTypeNumeric))
+		Expect(rows[1].Cells[2].Type()).To(Equal(xlsx.CellTypeNumeric))
+		Expect(rows[2].Cells[1].Type()).To(Equal(xlsx.CellTypeString))
+	})
+
+	It("stops at the most rows Excel can open and doesn't save the workbook", func() {
+		MaxSheetRows = 4
+		defer func() { MaxSheetRows = 1048576 }()
+
+		writer.Write([]string{"B234698573", "", "1"})
+		writer.Write([]string{"C234698573", "", "1"})
+		err := writer.Flush()
+		Expect(err).To(MatchError(ContainSubstring("sheet Subjects_Results has more than the 4 rows Excel can open")))
+		Expect(workbook.File.Sheet["Subjects_Results"].Rows).To(HaveLen(4))
+
+		Expect(workbook.Save()).To(MatchError(ContainSubstring("more than the 4 rows")))
+		Expect(outputPath).ToNot(BeAnExistingFile())
+	})
+
+	It("saves a sheet for each result set and the summary", func() {
+		Expect(workbook.AddSummarySheet(Summary{County: "SAN JOAQUIN", LineCount: 12})).To(Succeed())
+		Expect(workbook.Save()).To(Succeed())
+
+		file, err := xlsx.OpenFile(outputPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Sheets).To(HaveLen(2))
+		Expect(file.Sheets[0].Name).To(Equal("Subjects_Results"))
+		Expect(file.Sheets[1].Name).To(Equal("Summary"))
+
+		var statistics []string
+		for _, row := range file.Sheet["Summary"].Rows {
+			statistics = append(statistics, row.Cells[0].Value+"="+row.Cells[1].Value)
+		}
+		Expect(statistics).To(ContainElement("county=SAN JOAQUIN"))
+		Expect(statistics).To(ContainElement("lineCount=12"))
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"github.com/jessevdk/go-flags"
 	. "gogen/data"
 	. "gogen/exporter"
+	"gogen/utilities"
 	"math"
 	"math/rand"
 	"path/filepath"
@@ -33,7 +34,7 @@ func main() {
 
 	rand.Seed(time.Now().UnixNano())
 
-	testWriter, _ := NewWriter(filepath.Join(opts.OutputFolder, "generated_test_data.csv"), DojFullHeaders)
+	testWriter, _ := NewWriter(utilities.OutputFiles{}, filepath.Join(opts.OutputFolder, "generated_test_data.csv"), DojFullHeaders)
 
 	totalRows := 0
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
@@ -21,13 +24,44 @@ const VERSION = "0.2.13"
 
 var defaultOpts struct{}
 
+var clock utilities.Clock = utilities.SystemClock
+
 type runOpts struct {
-	OutputFolder       string `long:"outputs" description:"The folder in which to place result files"`
-	DOJFiles           string `long:"input-doj" description:"The files containing criminal histories from CA DOJ"`
-	County             string `long:"county" short:"c" description:"The county for which eligibility will be computed"`
-	ComputeAt          string `long:"compute-at" description:"The date for which eligibility will be evaluated, ex: 2020-10-31"`
-	EligibilityOptions string `long:"eligibility-options" description:"File containing options for which eligibility logic to apply"`
-	FileNameSuffix     string `long:"file-name-suffix" hidden:"true" description:"string to append to file names"`
+	Outputs                  []string `long:"outputs" description:"The folder in which to place result files; repeat with a csv:, jsonl:, xlsx: or sqlite: prefix to also write results to other folders"`
+	DOJFiles                 string   `long:"input-doj" description:"The files containing criminal histories from CA DOJ"`
+	County                   string   `long:"county" short:"c" description:"The county for which eligibility will be computed; ALL or a comma separated list of counties writes a folder per county"`
+	ComputeAt                string   `long:"compute-at" description:"The date for which eligibility will be evaluated, ex: 2020-10-31"`
+	EligibilityOptions       string   `long:"eligibility-options" description:"File containing options for which eligibility logic to apply"`
+	CountyEligibilityOptions string   `long:"county-eligibility-options" description:"Folder of per-county options files named after the county, ex: SAN_JOAQUIN.json; counties without a file use --eligibility-options"`
+	LeapDayRule              string   `long:"leap-day-rule" default:"MAR1" choice:"MAR1" choice:"FEB28" description:"The day on which February 29 birthdays and anniversaries fall in common years"`
+	IdentityResolution       string   `long:"identity-resolution" default:"OFF" choice:"OFF" choice:"STRICT" choice:"STANDARD" choice:"LOOSE" description:"Merge subjects that share a CII_NUMBER (STRICT), also an FBI_NUMBER (STANDARD), or also a DOB and similar name (LOOSE)"`
+	NameSimilarity           float64  `long:"name-similarity" default:"0.85" description:"The name similarity between 0 and 1 needed to merge subjects with the same DOB when --identity-resolution=LOOSE"`
+	CourtCMS                 string   `long:"court-cms" description:"CSV export from the court case management system used to find the docket number of each eligible conviction"`
+	CourtCMSMapping          string   `long:"court-cms-mapping" description:"File naming the --court-cms columns that hold the docket number, name, date of birth, disposition date and charge"`
+	OrderFormat              string   `long:"order-format" choice:"text" choice:"html" choice:"csv" description:"Write court orders for eligible convictions grouped by case in this format"`
+	OrderTemplate            string   `long:"order-template" description:"text/template file used to render each order document, see README"`
+	OrderBatchSize           int      `long:"order-batch-size" default:"0" description:"Number of cases per order document; 0 writes one document per case"`
+	OutputFormat             []string `long:"output-format" choice:"csv" choice:"xlsx" choice:"jsonl" choice:"sqlite" description:"Write the results in --outputs folders as CSV files (the default), one Excel workbook with a sheet per result set and the summary, JSON Lines files or a SQLite database; repeat to write several formats. Can't be used with csv:, jsonl:, xlsx: or sqlite: prefixed --outputs"`
+	Redact                   bool     `long:"redact" description:"Leave out names, SSNs, CDLs and other direct identifiers, replace SUBJECT_ID, CII_NUMBER and case numbers with pseudonyms and generalize dates of birth"`
+	RedactKeyFile            string   `long:"redact-key-file" description:"File holding the secret used to make --redact pseudonyms; the same secret gives the same pseudonyms in every run"`
+	RedactDOB                string   `long:"redact-dob" default:"year" choice:"year" choice:"age-band" description:"Generalize dates of birth to the year or a ten year age band when using --redact"`
+	OutputProfile            string   `long:"output-profile" description:"JSON file of named column sets and the result files they are applied to, see README"`
+	EncryptPassphraseFile    string   `long:"encrypt-passphrase-file" description:"Encrypt every output file with the passphrase in this file; encrypted files end in .enc, see gogen decrypt"`
+	EncryptPublicKey         string   `long:"encrypt-public-key" description:"Encrypt every output file for the holder of the private key matching this RSA public key PEM file"`
+	EquityBreakdowns         bool     `long:"equity-breakdowns" description:"Add counts of Prop 64 convictions, eligible convictions and subjects left with no felony or no conviction by race, gender and age band to the summary"`
+	EquityMinCellSize        int      `long:"equity-min-cell-size" default:"11" description:"Leave --equity-breakdowns counts below this size out of the summary"`
+	ExcludeList              string   `long:"exclude-list" description:"CSV of SUBJECT_ID, CII_NUMBER or CASE_NUMBER, with an optional REASON, whose convictions were already processed"`
+	FileNameSuffix           string   `long:"file-name-suffix" hidden:"true" description:"string to append to file names"`
+}
+
+type compareOpts struct {
+	OutputFolder       string   `long:"outputs" description:"The folder in which to place comparison files"`
+	DOJFiles           string   `long:"input-doj" description:"The files containing criminal histories from CA DOJ"`
+	County             string   `long:"county" short:"c" description:"The county for which eligibility will be computed"`
+	ComputeAt          string   `long:"compute-at" description:"The date for which eligibility will be evaluated, ex: 2020-10-31"`
+	EligibilityOptions []string `long:"eligibility-options" description:"File containing options for which eligibility logic to apply; repeat to compare several files"`
+	LeapDayRule        string   `long:"leap-day-rule" default:"MAR1" choice:"MAR1" choice:"FEB28" description:"The day on which February 29 birthdays and anniversaries fall in common years"`
+	FileNameSuffix     string   `long:"file-name-suffix" hidden:"true" description:"string to append to file names"`
 }
 
 type exportTestCSVOpts struct {
@@ -35,123 +69,838 @@ type exportTestCSVOpts struct {
 	OutputFolder     string `long:"outputs" short:"o" description:"The folder in which to place result files"`
 }
 
+type dispositionUpdateOpts struct {
+	OutputFolder   string `long:"outputs" description:"The folder in which to place the update file"`
+	Results        string `long:"results" description:"An All_Results file written by gogen run"`
+	CourtDecisions string `long:"court-decisions" description:"CSV of SUBJECT_ID, CNT_ORDER and DECISION (GRANTED or DENIED), with DECISION_DATE and an optional RELIEF (DISMISSED or REDUCED)"`
+	PassphraseFile string `long:"passphrase-file" description:"File holding the passphrase given to --encrypt-passphrase-file, if --results is encrypted"`
+	PrivateKey     string `long:"private-key" description:"RSA private key PEM file matching the --encrypt-public-key, if --results is encrypted"`
+	FileNameSuffix string `long:"file-name-suffix" hidden:"true" description:"string to append to file names"`
+}
+
+type decryptOpts struct {
+	OutputFolder   string `long:"outputs" description:"The folder in which to place the decrypted files"`
+	Input          string `long:"input" description:"A file encrypted by gogen run, or a folder whose .enc files are all decrypted"`
+	PassphraseFile string `long:"passphrase-file" description:"File holding the passphrase given to --encrypt-passphrase-file"`
+	PrivateKey     string `long:"private-key" description:"RSA private key PEM file matching the --encrypt-public-key"`
+}
+
+type verifyManifestOpts struct {
+	Manifest       string `long:"manifest" description:"A gogen.json written by gogen run; the outputs it lists are checked relative to its folder"`
+	PassphraseFile string `long:"passphrase-file" description:"File holding the passphrase given to --encrypt-passphrase-file, if gogen.json is encrypted"`
+	PrivateKey     string `long:"private-key" description:"RSA private key PEM file matching the --encrypt-public-key, if gogen.json is encrypted"`
+}
+
+type reportOpts struct {
+	OutputFolder   string `long:"outputs" description:"The folder in which to place Report.html; defaults to the folder of the summary"`
+	Input          string `long:"input" description:"The output folder of gogen run, or the gogen.json in it"`
+	PassphraseFile string `long:"passphrase-file" description:"File holding the passphrase given to --encrypt-passphrase-file, if gogen.json is encrypted"`
+	PrivateKey     string `long:"private-key" description:"RSA private key PEM file matching the --encrypt-public-key, if gogen.json is encrypted"`
+	FileNameSuffix string `long:"file-name-suffix" hidden:"true" description:"string to append to file names"`
+}
+
 type versionOpts struct{}
 
 var opts struct {
-	Version   versionOpts       `command:"version" description:"Print the version"`
-	Run       runOpts           `command:"run" description:"Process an input DOJ file and produce an annotated DOJ data file"`
-	ExportCSV exportTestCSVOpts `command:"export-test-csv" description:"Export example data files from excel fixtures"`
+	Version           versionOpts           `command:"version" description:"Print the version"`
+	Run               runOpts               `command:"run" description:"Process an input DOJ file and produce an annotated DOJ data file"`
+	Compare           compareOpts           `command:"compare" description:"Compare the results of several eligibility options files against the same DOJ files"`
+	ExportCSV         exportTestCSVOpts     `command:"export-test-csv" description:"Export example data files from excel fixtures"`
+	DispositionUpdate dispositionUpdateOpts `command:"disposition-update" description:"Write a DOJ disposition update file for the convictions the court granted relief on"`
+	Decrypt           decryptOpts           `command:"decrypt" description:"Decrypt the files written by gogen run with --encrypt-passphrase-file or --encrypt-public-key"`
+	VerifyManifest    verifyManifestOpts    `command:"verify-manifest" description:"Check that the output files listed in the manifest of a gogen.json have not changed"`
+	Report            reportOpts            `command:"report" description:"Write a standalone HTML report of the summary of a gogen run"`
 }
 
 func (r runOpts) Execute(args []string) error {
 
 	var processingStartTime time.Time
+	startedAt := clock.Now()
 
-	utilities.SetErrorFileName(utilities.GenerateFileName(r.OutputFolder, "gogen%s.err", r.FileNameSuffix))
+	errorFilePath := utilities.GenerateFileName(r.outputFolder(), "gogen%s.err", r.FileNameSuffix)
+	utilities.SetErrorFileName(errorFilePath, utilities.OutputFiles{})
 
-	if r.OutputFolder == "" || r.DOJFiles == "" || r.County == "" || r.EligibilityOptions == "" {
+	files, err := r.outputFiles()
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	utilities.SetErrorFileName(errorFilePath, files)
+
+	if r.outputFolder() == "" || r.DOJFiles == "" || r.County == "" || r.EligibilityOptions == "" {
 		utilities.ExitWithError(errors.New("missing required field: Run gogen --help for more info"))
 	}
 
 	inputFiles := strings.Split(r.DOJFiles, ",")
 
-	computeAtDate := time.Now()
+	computeAtDate := parseComputeAt(r.ComputeAt)
 
-	if r.ComputeAt != "" {
-		computeAtOption, err := time.Parse("2006-01-02", r.ComputeAt)
+	calendar, err := data.NewCalendar(data.LeapDayRule(r.LeapDayRule))
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	counties, statewide, err := parseCounties(r.County)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	if statewide {
+		return r.executeStatewide(inputFiles, counties, computeAtDate, calendar, files, startedAt)
+	}
+	county := counties[0]
+
+	configurableEligibilityFlow, err := readEligibilityFlow(r.EligibilityOptions, county)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	identityResolutionOptions, err := data.NewIdentityResolutionOptions(r.IdentityResolution, r.NameSimilarity)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	exportSettings, err := r.countyExportSettings(startedAt, calendar, files)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	runErrors := make(map[string]utilities.GogenError)
+	inputRows := make(map[string]int)
+	var runSummary exporter.Summary
+	var summaries exporter.DataExporter
+	outputJsonFilePath := utilities.GenerateFileName(r.outputFolder(), "gogen%s.json", r.FileNameSuffix)
+
+	err = os.MkdirAll(r.outputFolder(), os.ModePerm)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	for fileIndex, inputFile := range inputFiles {
+		processingStartTime = clock.Now()
+		fileIndex = fileIndex + 1
+		dojInformation, gogenErr := data.NewDOJInformation(inputFile, computeAtDate, calendar, configurableEligibilityFlow)
+		if gogenErr.ErrorType != "" {
+			runErrors[inputFile] = gogenErr
+			continue
+		}
+		inputRows[inputFile] = dojInformation.TotalRows()
+
+		err = r.resolveIdentities(dojInformation, identityResolutionOptions, exportSettings, fileIndex, len(inputFiles))
 		if err != nil {
-			utilities.ExitWithError(errors.New("invalid --compute-at date: Must be a valid date in the format YYYY-MM-DD"))
-		} else {
-			computeAtDate = computeAtOption
+			runErrors[inputFile] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+			continue
 		}
+
+		exportSettings.run.InputFile = inputFile
+		fileSummary, err := r.exportCounty(dojInformation, county, configurableEligibilityFlow, exportSettings, "", fileIndex, len(inputFiles))
+		if err != nil {
+			addRunError(runErrors, inputFile, err)
+			continue
+		}
+		fileSummary.Warnings = checkCountyHasRows(dojInformation, county, inputFile)
+		runSummary = summaries.AccumulateSummaryData(runSummary, fileSummary)
 	}
 
-	var configurableEligibilityFlow data.ConfigurableEligibilityFlow
+	if encounteredErrors(runErrors) {
+		utilities.ExitWithErrors(runErrors)
+	}
 
-	var options data.EligibilityOptions
-	optionsFile, err := os.Open(r.EligibilityOptions)
+	err = r.exportConvictionYears(files, runSummary, r.outputFolder())
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
-	defer optionsFile.Close()
+	eligibilityOptions, err := readEligibilityOptions(r.EligibilityOptions)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	runSummary.Manifest, err = r.manifest(county, eligibilityOptions, *exportSettings.run, inputRows, files)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	ExportSummary(files, runSummary, processingStartTime, outputJsonFilePath)
+	return nil
+}
 
-	optionsBytes, err := ioutil.ReadAll(optionsFile)
+func (r runOpts) executeStatewide(inputFiles []string, counties []string, computeAtDate time.Time, calendar data.Calendar, files utilities.OutputFiles, startedAt time.Time) error {
+	processingStartTime := clock.Now()
+
+	defaultOptions, err := readEligibilityOptions(r.EligibilityOptions)
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
 
-	err = json.Unmarshal(optionsBytes, &options)
+	identityResolutionOptions, err := data.NewIdentityResolutionOptions(r.IdentityResolution, r.NameSimilarity)
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
-	configurableEligibilityFlow, err = data.NewConfigurableEligibilityFlow(options, r.County)
+
+	exportSettings, err := r.countyExportSettings(startedAt, calendar, files)
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
+	exportSettings.countyRowsOnly = true
+
+	flows := make(map[string]data.ConfigurableEligibilityFlow)
+	countyFlow := func(county string) (data.ConfigurableEligibilityFlow, error) {
+		if flow, ok := flows[county]; ok {
+			return flow, nil
+		}
+		options, err := r.countyEligibilityOptions(county, defaultOptions)
+		if err != nil {
+			return data.ConfigurableEligibilityFlow{}, err
+		}
+		flow, err := data.NewConfigurableEligibilityFlow(options, county)
+		if err != nil {
+			return data.ConfigurableEligibilityFlow{}, fmt.Errorf("%s: %s", county, err.Error())
+		}
+		flows[county] = flow
+		return flow, nil
+	}
+	for _, county := range counties {
+		_, err := countyFlow(county)
+		if err != nil {
+			utilities.ExitWithError(err)
+		}
+	}
 
 	runErrors := make(map[string]utilities.GogenError)
-	var runSummary exporter.Summary
-	outputJsonFilePath := utilities.GenerateFileName(r.OutputFolder, "gogen%s.json", r.FileNameSuffix)
+	countySummaries := make(map[string]exporter.Summary)
+	inputRows := make(map[string]int)
+	var summaries exporter.DataExporter
+	lineCount := 0
+	outputJsonFilePath := utilities.GenerateFileName(r.outputFolder(), "gogen%s.json", r.FileNameSuffix)
 
-	err = os.MkdirAll(r.OutputFolder, os.ModePerm)
+	err = os.MkdirAll(r.outputFolder(), os.ModePerm)
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
 
 	for fileIndex, inputFile := range inputFiles {
-		processingStartTime = time.Now()
 		fileIndex = fileIndex + 1
-		dojInformation, gogenErr := data.NewDOJInformation(inputFile, computeAtDate, configurableEligibilityFlow)
+		dojInformation, gogenErr := data.NewDOJInformation(inputFile, computeAtDate, calendar, data.ConfigurableEligibilityFlow{})
 		if gogenErr.ErrorType != "" {
 			runErrors[inputFile] = gogenErr
 			continue
 		}
-		countyEligibilities := dojInformation.DetermineEligibility(r.County, configurableEligibilityFlow)
+		lineCount += dojInformation.TotalRows()
+		inputRows[inputFile] = dojInformation.TotalRows()
+
+		err = r.resolveIdentities(dojInformation, identityResolutionOptions, exportSettings, fileIndex, len(inputFiles))
+		if err != nil {
+			runErrors[inputFile] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+			continue
+		}
+
+		fileCounties := counties
+		if fileCounties == nil {
+			fileCounties = dojInformation.ConvictionCounties()
+		}
+
+		for _, county := range fileCounties {
+			countyStartTime := clock.Now()
+			flow, err := countyFlow(county)
+			if err != nil {
+				utilities.ExitWithError(err)
+			}
 
-		dismissAllProp64Eligibilities := dojInformation.DetermineEligibility(r.County, data.EligibilityFlows["DISMISS ALL PROP 64"])
-		dismissAllProp64AndRelatedEligibilities := dojInformation.DetermineEligibility(r.County, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"])
+			countyOutputFolder := filepath.Join(r.outputFolder(), countyFolderName(county))
+			err = os.MkdirAll(countyOutputFolder, os.ModePerm)
+			if err != nil {
+				runErrors[inputFile+": "+county] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+				continue
+			}
 
-		dojFilePath := utilities.GenerateIndexedFileName(r.OutputFolder, "All_Results%s.csv", fileIndex, len(inputFiles), r.FileNameSuffix)
-		condensedFilePath := utilities.GenerateIndexedFileName(r.OutputFolder, "All_Results_Condensed%s.csv", fileIndex, len(inputFiles), r.FileNameSuffix)
-		prop64ConvictionsFilePath := utilities.GenerateIndexedFileName(r.OutputFolder, "Prop64_Results%s.csv", fileIndex, len(inputFiles), r.FileNameSuffix)
+			exportSettings.run.InputFile = inputFile
+			fileSummary, err := r.exportCounty(dojInformation, county, flow, exportSettings, countyFolderName(county), fileIndex, len(inputFiles))
+			if err != nil {
+				addRunError(runErrors, inputFile+": "+county, err)
+				continue
+			}
+			fileSummary.Warnings = checkCountyHasRows(dojInformation, county, inputFile)
+			countySummary := summaries.AccumulateSummaryData(countySummaries[county], fileSummary)
+			countySummary.ProcessingTimeInSeconds = countySummaries[county].ProcessingTimeInSeconds + clock.Now().Sub(countyStartTime).Seconds()
+			countySummaries[county] = countySummary
+		}
+	}
 
-		dojWriter, err := exporter.NewDOJWriter(dojFilePath)
+	if encounteredErrors(runErrors) {
+		utilities.ExitWithErrors(runErrors)
+	}
+
+	for county, summary := range countySummaries {
+		countyOutputFolder := filepath.Join(r.outputFolder(), countyFolderName(county))
+		err = r.exportConvictionYears(files, summary, countyOutputFolder)
 		if err != nil {
-			runErrors[inputFile] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+			utilities.ExitWithError(err)
+		}
+		writeJson(files, summary, utilities.GenerateFileName(countyOutputFolder, "gogen%s.json", r.FileNameSuffix))
+	}
+
+	statewideSummary := exporter.NewStatewideSummary(countySummaries, lineCount)
+	err = r.exportConvictionYears(files, statewideSummary.Statewide, r.outputFolder())
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	statewideSummary.Manifest, err = r.manifest(r.County, defaultOptions, *exportSettings.run, inputRows, files)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	statewideSummary.ProcessingTimeInSeconds = clock.Now().Sub(processingStartTime).Seconds()
+	writeJson(files, statewideSummary, outputJsonFilePath)
+	return nil
+}
+
+func (r runOpts) resolveIdentities(dojInformation *data.DOJInformation, options data.IdentityResolutionOptions, settings countyExportSettings, fileIndex int, fileCount int) error {
+	if options.Strictness == data.IdentityResolutionOff {
+		return nil
+	}
+	links := dojInformation.ResolveIdentities(options)
+
+	identityLinksFilePath := utilities.GenerateIndexedFileName(r.outputFolder(), "Identity_Links%s.csv", fileIndex, fileCount, r.FileNameSuffix)
+	identityLinkWriter, err := exporter.NewIdentityLinkWriter(settings.files, identityLinksFilePath)
+	if err != nil {
+		return err
+	}
+	return exporter.ExportIdentityLinks(links, exporter.NewRedactingWriter(identityLinkWriter, settings.redactor, exporter.IdentityLinkHeaders))
+}
+
+func (r runOpts) exportCounty(
+	dojInformation *data.DOJInformation,
+	county string,
+	configurableEligibilityFlow data.ConfigurableEligibilityFlow,
+	settings countyExportSettings,
+	countyFolder string,
+	fileIndex int,
+	fileCount int,
+) (exporter.Summary, error) {
+	countyEligibilities := dojInformation.DetermineEligibility(county, configurableEligibilityFlow)
+	if settings.excludeList != nil {
+		dojInformation.ApplyExcludeList(countyEligibilities, *settings.excludeList)
+	}
+
+	dismissAllProp64Eligibilities := dojInformation.DetermineEligibility(county, data.EligibilityFlows["DISMISS ALL PROP 64"])
+	dismissAllProp64AndRelatedEligibilities := dojInformation.DetermineEligibility(county, data.EligibilityFlows["DISMISS ALL PROP 64 AND RELATED"])
+
+	outputFolder := filepath.Join(r.outputFolder(), countyFolder)
+	courtMatched := settings.courtMatcher != nil
+	if courtMatched {
+		unmatched := dojInformation.MatchCourtRecords(countyEligibilities, *settings.courtMatcher)
+		unmatchedFilePath := utilities.GenerateIndexedFileName(outputFolder, "Court_Unmatched%s.csv", fileIndex, fileCount, r.FileNameSuffix)
+		var unmatchedDojWriter exporter.DOJWriter
+		var err error
+		if columns, ok := settings.outputProfile.Columns("Court_Unmatched"); ok {
+			unmatchedDojWriter, err = exporter.NewProfiledDOJWriter(settings.files, unmatchedFilePath, columns.WithRedactor(settings.redactor))
+		} else {
+			unmatchedDojWriter, err = exporter.NewCondensedDOJWriter(settings.files, unmatchedFilePath)
+		}
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+		err = exporter.ExportUnmatchedCourtConvictions(dojInformation, countyEligibilities, unmatched, exporter.NewRedactingWriter(unmatchedDojWriter, settings.redactor, nil))
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+	}
+
+	if settings.orderExporter != nil {
+		ordersFolder := utilities.GenerateIndexedFileName(outputFolder, "Orders%s", fileIndex, fileCount, r.FileNameSuffix)
+		err := settings.orderExporter.Export(dojInformation, countyEligibilities, county, ordersFolder)
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+	}
+
+	run := *settings.run
+	run.County = county
+	var sinks []exporter.Sink
+	for _, destination := range settings.destinations {
+		sink, err := exporter.NewSink(destination.Scheme, exporter.SinkOptions{
+			OutputFolder:      filepath.Join(destination.Folder, countyFolder),
+			FileIndex:         fileIndex,
+			FileCount:         fileCount,
+			FileNameSuffix:    r.FileNameSuffix,
+			CourtMatchColumns: courtMatched,
+			Profile:           settings.outputProfile,
+			Redactor:          settings.redactor,
+			Run:               &run,
+			Files:             settings.files,
+		})
+		if err != nil {
+			return exporter.Summary{}, err
+		}
+		sinks = append(sinks, sink)
+	}
+	writers := exporter.RedactWriters(exporter.CombineSinkWriters(sinks), settings.redactor)
+
+	// A statewide file holds every county's rows, so each county folder only
+	// gets the rows reported by that county instead of another full copy.
+	var rowIndexes []int
+	if settings.countyRowsOnly {
+		rowIndexes = dojInformation.RowIndexesInCounty(county)
+	}
+
+	dataExporter := exporter.NewDataExporter(
+		dojInformation,
+		countyEligibilities,
+		dismissAllProp64Eligibilities,
+		dismissAllProp64AndRelatedEligibilities,
+		writers.DOJ,
+		writers.Condensed,
+		writers.Prop64Convictions,
+		writers.HandReview,
+		writers.Subjects).WithRowIndexes(rowIndexes)
+
+	summary, err := dataExporter.Export(county, configurableEligibilityFlow)
+	if r.EquityBreakdowns {
+		summary.EquityBreakdowns = exporter.NewEquityBreakdowns(dojInformation, county, countyEligibilities, r.EquityMinCellSize)
+	}
+	if err == nil {
+		err = exporter.WriteSinkEligibilities(sinks, dojInformation, rowIndexes, countyEligibilities)
+	}
+	run.FinishedAt = clock.Now()
+	settings.run.FinishedAt = run.FinishedAt
+	err = exporter.CollectWriteErrors(err, exporter.CloseSinks(sinks, summary))
+	if err != nil {
+		return exporter.Summary{}, err
+	}
+	return summary, nil
+}
+
+type countyExportSettings struct {
+	run            *exporter.RunMetadata
+	files          utilities.OutputFiles
+	destinations   []exporter.OutputDestination
+	outputProfile  *exporter.OutputProfile
+	redactor       *exporter.Redactor
+	courtMatcher   *data.CourtMatcher
+	excludeList    *data.ExcludeList
+	orderExporter  *exporter.OrderExporter
+	countyRowsOnly bool
+}
+
+func (r runOpts) countyExportSettings(startedAt time.Time, calendar data.Calendar, files utilities.OutputFiles) (countyExportSettings, error) {
+	settings := countyExportSettings{files: files}
+	var err error
+	settings.run = &exporter.RunMetadata{
+		GogenVersion:       VERSION,
+		ComputeAt:          parseComputeAt(r.ComputeAt),
+		EligibilityOptions: r.EligibilityOptions,
+		StartedAt:          startedAt,
+	}
+	if r.EquityBreakdowns && r.EquityMinCellSize < 1 {
+		return settings, errors.New("--equity-min-cell-size must be at least 1")
+	}
+	settings.destinations, err = r.outputDestinations()
+	if err != nil {
+		return settings, err
+	}
+	if r.OutputProfile != "" {
+		settings.outputProfile, err = exporter.ReadOutputProfile(r.OutputProfile)
+		if err != nil {
+			return settings, err
+		}
+	}
+	settings.redactor, err = r.redactor(calendar)
+	if err != nil {
+		return settings, err
+	}
+	settings.courtMatcher, err = r.courtMatcher()
+	if err != nil {
+		return settings, err
+	}
+	settings.excludeList, err = r.excludeList()
+	if err != nil {
+		return settings, err
+	}
+	settings.orderExporter, err = r.orderExporter(files)
+	return settings, err
+}
+
+func (r runOpts) exportConvictionYears(files utilities.OutputFiles, summary exporter.Summary, outputFolder string) error {
+	convictionYearsFilePath := utilities.GenerateFileName(outputFolder, "Conviction_Years%s.csv", r.FileNameSuffix)
+	convictionYearsWriter, err := exporter.NewConvictionYearsWriter(files, convictionYearsFilePath, exporter.ConvictionYearDeterminations(summary))
+	if err != nil {
+		return err
+	}
+	return exporter.ExportConvictionYears(summary, convictionYearsWriter)
+}
+
+// The manifest shares its start and finish with the run table of SQLite outputs.
+func (r runOpts) manifest(county string, eligibilityOptions data.EligibilityOptions, run exporter.RunMetadata, inputRows map[string]int, files utilities.OutputFiles) (*exporter.Manifest, error) {
+	if run.FinishedAt.IsZero() {
+		run.FinishedAt = clock.Now()
+	}
+	manifest := exporter.Manifest{
+		GogenVersion:       VERSION,
+		Options:            flagValues(r),
+		EligibilityOptions: &eligibilityOptions,
+		County:             county,
+		ComputeAt:          parseComputeAt(r.ComputeAt).Format("2006-01-02"),
+		StartedAt:          run.StartedAt,
+		FinishedAt:         run.FinishedAt,
+	}
+
+	for _, inputFile := range strings.Split(r.DOJFiles, ",") {
+		rows := inputRows[inputFile]
+		file, err := exporter.NewManifestFile(inputFile, "", &rows)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Inputs = append(manifest.Inputs, file)
+	}
+
+	inputFiles, err := r.manifestInputFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, inputFile := range inputFiles {
+		file, err := exporter.NewCountedManifestFile(inputFile, "")
+		if err != nil {
+			return nil, err
+		}
+		manifest.Inputs = append(manifest.Inputs, file)
+	}
+
+	for _, outputFile := range files.Created() {
+		file, err := exporter.NewCountedManifestFile(outputFile, r.outputFolder())
+		if err != nil {
+			return nil, err
+		}
+		manifest.Outputs = append(manifest.Outputs, file)
+	}
+	return &manifest, nil
+}
+
+func (r runOpts) manifestInputFiles() ([]string, error) {
+	var inputFiles []string
+	for _, inputFile := range []string{r.EligibilityOptions, r.CourtCMS, r.CourtCMSMapping, r.ExcludeList, r.OutputProfile, r.OrderTemplate, r.EncryptPublicKey} {
+		if inputFile != "" {
+			inputFiles = append(inputFiles, inputFile)
+		}
+	}
+	if r.CountyEligibilityOptions != "" {
+		countyOptionsFiles, err := filepath.Glob(filepath.Join(r.CountyEligibilityOptions, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		inputFiles = append(inputFiles, countyOptionsFiles...)
+	}
+	return inputFiles, nil
+}
+
+func flagValues(options interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	optionsValue := reflect.ValueOf(options)
+	for index := 0; index < optionsValue.NumField(); index++ {
+		name := optionsValue.Type().Field(index).Tag.Get("long")
+		if name != "" {
+			values[name] = optionsValue.Field(index).Interface()
+		}
+	}
+	return values
+}
+
+func (r runOpts) outputFiles() (utilities.OutputFiles, error) {
+	if r.EncryptPassphraseFile == "" && r.EncryptPublicKey == "" {
+		return utilities.NewOutputFiles(nil), nil
+	}
+	encryption, err := utilities.ReadOutputEncryption(r.EncryptPassphraseFile, r.EncryptPublicKey)
+	if err != nil {
+		return utilities.OutputFiles{}, err
+	}
+	return utilities.NewOutputFiles(encryption), nil
+}
+
+func (r runOpts) outputFolder() string {
+	if len(r.Outputs) == 0 {
+		return ""
+	}
+	destination, err := exporter.ParseOutputDestination(r.Outputs[0])
+	if err != nil {
+		return r.Outputs[0]
+	}
+	return destination.Folder
+}
+
+func (r runOpts) outputDestinations() ([]exporter.OutputDestination, error) {
+	outputFormats := r.OutputFormat
+	if len(outputFormats) == 0 {
+		outputFormats = []string{"csv"}
+	}
+
+	var destinations []exporter.OutputDestination
+	for _, output := range r.Outputs {
+		destination, err := exporter.ParseOutputDestination(output)
+		if err != nil {
+			return nil, err
+		}
+		if destination.Scheme != "" {
+			if len(r.OutputFormat) > 0 {
+				return nil, fmt.Errorf("--output-format can't be used with the prefixed output %q: prefix each --outputs folder with the formats it should get instead", output)
+			}
+			destinations = append(destinations, destination)
 			continue
 		}
-		condensedDojWriter, err := exporter.NewCondensedDOJWriter(condensedFilePath)
+		for _, format := range outputFormats {
+			destinations = append(destinations, exporter.OutputDestination{Scheme: format, Folder: destination.Folder})
+		}
+	}
+	return destinations, nil
+}
+
+func (r runOpts) redactor(calendar data.Calendar) (*exporter.Redactor, error) {
+	if !r.Redact {
+		return nil, nil
+	}
+	if r.RedactKeyFile == "" {
+		return nil, errors.New("--redact needs a --redact-key-file")
+	}
+	if r.OrderFormat != "" || r.OrderTemplate != "" {
+		return nil, errors.New("--redact can't be used to write court orders")
+	}
+	return exporter.ReadRedactor(r.RedactKeyFile, r.RedactDOB, parseComputeAt(r.ComputeAt), calendar)
+}
+
+func (r runOpts) orderExporter(files utilities.OutputFiles) (*exporter.OrderExporter, error) {
+	if r.OrderFormat == "" && r.OrderTemplate == "" {
+		return nil, nil
+	}
+	format := r.OrderFormat
+	if format == "" {
+		format = "text"
+	}
+	orderExporter, err := exporter.NewOrderExporter(files, r.OrderTemplate, format, r.OrderBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	return &orderExporter, nil
+}
+
+func (r runOpts) courtMatcher() (*data.CourtMatcher, error) {
+	if r.CourtCMS == "" {
+		return nil, nil
+	}
+	mapping := data.DefaultCourtColumnMapping
+	if r.CourtCMSMapping != "" {
+		mappingBytes, err := ioutil.ReadFile(r.CourtCMSMapping)
 		if err != nil {
-			runErrors[inputFile] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+			return nil, err
+		}
+		err = json.Unmarshal(mappingBytes, &mapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+	records, err := data.ReadCourtRecords(r.CourtCMS, mapping)
+	if err != nil {
+		return nil, err
+	}
+	courtMatcher := data.NewCourtMatcher(records)
+	return &courtMatcher, nil
+}
+
+func (r runOpts) excludeList() (*data.ExcludeList, error) {
+	if r.ExcludeList == "" {
+		return nil, nil
+	}
+	excludeList, err := data.ReadExcludeList(r.ExcludeList)
+	if err != nil {
+		return nil, err
+	}
+	return &excludeList, nil
+}
+
+func (r runOpts) countyEligibilityOptions(county string, defaultOptions data.EligibilityOptions) (data.EligibilityOptions, error) {
+	if r.CountyEligibilityOptions == "" {
+		return defaultOptions, nil
+	}
+	optionsPath := filepath.Join(r.CountyEligibilityOptions, countyFolderName(county)+".json")
+	if _, err := os.Stat(optionsPath); os.IsNotExist(err) {
+		return defaultOptions, nil
+	}
+	return readEligibilityOptions(optionsPath)
+}
+
+func parseCounties(county string) ([]string, bool, error) {
+	if strings.EqualFold(strings.TrimSpace(county), "ALL") {
+		return nil, true, nil
+	}
+	var counties []string
+	for _, name := range strings.Split(county, ",") {
+		if strings.TrimSpace(name) == "" {
 			continue
 		}
-		prop64ConvictionsDojWriter, err := exporter.NewDOJWriter(prop64ConvictionsFilePath)
+		normalizedName, err := data.NormalizeCounty(name)
 		if err != nil {
-			runErrors[inputFile] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+			return nil, false, err
+		}
+		counties = append(counties, normalizedName)
+	}
+	if len(counties) == 0 {
+		return nil, false, errors.New("missing required field: Run gogen --help for more info")
+	}
+	return counties, strings.Contains(county, ","), nil
+}
+
+func checkCountyHasRows(dojInformation *data.DOJInformation, county string, inputFile string) []string {
+	if dojInformation.HasRowsInCounty(county) {
+		return nil
+	}
+	warning := fmt.Sprintf("%s: no rows found for county %s", inputFile, county)
+	utilities.PrintWarning(warning)
+	return []string{warning}
+}
+
+func countyFolderName(county string) string {
+	return strings.Replace(strings.TrimSpace(county), " ", "_", -1)
+}
+
+func (c compareOpts) Execute(args []string) error {
+
+	var processingStartTime time.Time
+
+	utilities.SetErrorFileName(utilities.GenerateFileName(c.OutputFolder, "gogen_comparison%s.err", c.FileNameSuffix), utilities.OutputFiles{})
+
+	if c.OutputFolder == "" || c.DOJFiles == "" || c.County == "" || len(c.EligibilityOptions) < 2 {
+		utilities.ExitWithError(errors.New("missing required field: compare needs --outputs, --input-doj, --county and at least two --eligibility-options"))
+	}
+
+	inputFiles := strings.Split(c.DOJFiles, ",")
+
+	computeAtDate := parseComputeAt(c.ComputeAt)
+
+	county, err := data.NormalizeCounty(c.County)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	calendar, err := data.NewCalendar(data.LeapDayRule(c.LeapDayRule))
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	var flows []data.ConfigurableEligibilityFlow
+	var labels []string
+	for _, optionsPath := range c.EligibilityOptions {
+		flow, err := readEligibilityFlow(optionsPath, county)
+		if err != nil {
+			utilities.ExitWithError(fmt.Errorf("%s: %s", optionsPath, err.Error()))
+		}
+		flows = append(flows, flow)
+		labels = append(labels, configurationLabel(optionsPath, labels))
+	}
+
+	runErrors := make(map[string]utilities.GogenError)
+	var runSummary exporter.ComparisonSummary
+	outputJsonFilePath := utilities.GenerateFileName(c.OutputFolder, "gogen_comparison%s.json", c.FileNameSuffix)
+
+	err = os.MkdirAll(c.OutputFolder, os.ModePerm)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	for fileIndex, inputFile := range inputFiles {
+		processingStartTime = clock.Now()
+		fileIndex = fileIndex + 1
+		dojInformation, gogenErr := data.NewDOJInformation(inputFile, computeAtDate, calendar, flows[0])
+		if gogenErr.ErrorType != "" {
+			runErrors[inputFile] = gogenErr
 			continue
 		}
+		checkCountyHasRows(dojInformation, county, inputFile)
 
-		dataExporter := exporter.NewDataExporter(
-			dojInformation,
-			countyEligibilities,
-			dismissAllProp64Eligibilities,
-			dismissAllProp64AndRelatedEligibilities,
-			dojWriter,
-			condensedDojWriter,
-			prop64ConvictionsDojWriter)
+		var configEligibilities []map[int]*data.EligibilityInfo
+		for _, flow := range flows {
+			configEligibilities = append(configEligibilities, dojInformation.DetermineEligibility(county, flow))
+		}
 
-		fileSummary := dataExporter.Export(r.County, configurableEligibilityFlow)
-		runSummary = dataExporter.AccumulateSummaryData(runSummary, fileSummary)
+		comparisonFilePath := utilities.GenerateIndexedFileName(c.OutputFolder, "Comparison_Results%s.csv", fileIndex, len(inputFiles), c.FileNameSuffix)
+		comparisonWriter, err := exporter.NewComparisonWriter(utilities.OutputFiles{}, comparisonFilePath, labels)
+		if err != nil {
+			runErrors[inputFile] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+			continue
+		}
+
+		comparisonExporter := exporter.NewComparisonExporter(dojInformation, labels, configEligibilities, comparisonWriter)
+		fileSummary, err := comparisonExporter.Export(county)
+		if err != nil {
+			addRunError(runErrors, inputFile, err)
+			continue
+		}
+		runSummary = comparisonExporter.AccumulateComparisonData(runSummary, fileSummary)
 	}
 
 	if encounteredErrors(runErrors) {
 		utilities.ExitWithErrors(runErrors)
 	}
 
-	ExportSummary(runSummary, processingStartTime, outputJsonFilePath)
+	runSummary.ProcessingTimeInSeconds = clock.Now().Sub(processingStartTime).Seconds()
+	writeJson(utilities.OutputFiles{}, runSummary, outputJsonFilePath)
 	return nil
 }
 
+func parseComputeAt(computeAt string) time.Time {
+	computeAtDate := utilities.StartOfDay(clock.Now())
+
+	if computeAt != "" {
+		computeAtOption, err := time.Parse("2006-01-02", computeAt)
+		if err != nil {
+			utilities.ExitWithError(errors.New("invalid --compute-at date: Must be a valid date in the format YYYY-MM-DD"))
+		} else {
+			computeAtDate = computeAtOption
+		}
+	}
+	return computeAtDate
+}
+
+func readEligibilityFlow(optionsPath string, county string) (data.ConfigurableEligibilityFlow, error) {
+	options, err := readEligibilityOptions(optionsPath)
+	if err != nil {
+		return data.ConfigurableEligibilityFlow{}, err
+	}
+	return data.NewConfigurableEligibilityFlow(options, county)
+}
+
+func readEligibilityOptions(optionsPath string) (data.EligibilityOptions, error) {
+	var options data.EligibilityOptions
+	optionsFile, err := os.Open(optionsPath)
+	if err != nil {
+		return options, err
+	}
+	defer optionsFile.Close()
+
+	optionsBytes, err := ioutil.ReadAll(optionsFile)
+	if err != nil {
+		return options, err
+	}
+
+	err = json.Unmarshal(optionsBytes, &options)
+	return options, err
+}
+
+func configurationLabel(optionsPath string, existingLabels []string) string {
+	label := strings.TrimSuffix(filepath.Base(optionsPath), filepath.Ext(optionsPath))
+	for _, existing := range existingLabels {
+		if existing == label {
+			return fmt.Sprintf("%s_%d", label, len(existingLabels)+1)
+		}
+	}
+	return label
+}
+
+func addRunError(runErrors map[string]utilities.GogenError, key string, err error) {
+	if writeErrors, ok := err.(exporter.WriteErrors); ok {
+		for _, writeError := range writeErrors {
+			destination := writeError.Destination
+			if destination == "" {
+				destination = key
+			}
+			runErrors[destination] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: writeError.Err.Error()}
+		}
+		return
+	}
+	runErrors[key] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: err.Error()}
+}
+
 func encounteredErrors(runErrors map[string]utilities.GogenError) bool {
 	for _, value := range runErrors {
 		if value.ErrorType != "" {
@@ -162,17 +911,179 @@ func encounteredErrors(runErrors map[string]utilities.GogenError) bool {
 
 }
 
-func ExportSummary(summary exporter.Summary, startTime time.Time, filePath string) {
-	summary.ProcessingTimeInSeconds = time.Since(startTime).Seconds()
+func ExportSummary(files utilities.OutputFiles, summary exporter.Summary, startTime time.Time, filePath string) {
+	summary.ProcessingTimeInSeconds = clock.Now().Sub(startTime).Seconds()
+	writeJson(files, summary, filePath)
+}
 
-	s, err := json.Marshal(summary)
+func writeJson(files utilities.OutputFiles, value interface{}, filePath string) {
+	s, err := json.Marshal(value)
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
-	err = ioutil.WriteFile(filePath, s, 0644)
+	err = files.WriteFile(filePath, s)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+}
+
+func (d dispositionUpdateOpts) Execute(args []string) error {
+	utilities.SetErrorFileName(utilities.GenerateFileName(d.OutputFolder, "gogen_disposition_update%s.err", d.FileNameSuffix), utilities.OutputFiles{})
+
+	if d.OutputFolder == "" || d.Results == "" || d.CourtDecisions == "" {
+		utilities.ExitWithError(errors.New("missing required field: disposition-update needs --outputs, --results and --court-decisions"))
+	}
+
+	decisions, err := exporter.ReadCourtDecisions(d.CourtDecisions)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	var decryption *utilities.OutputDecryption
+	if strings.HasSuffix(d.Results, utilities.EncryptedFileExtension) {
+		decryption, err = utilities.ReadOutputDecryption(d.PassphraseFile, d.PrivateKey)
+		if err != nil {
+			utilities.ExitWithError(err)
+		}
+	}
+	updates, rejected, err := exporter.BuildDispositionUpdates(d.Results, decryption, decisions)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	err = os.MkdirAll(d.OutputFolder, os.ModePerm)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	err = exporter.WriteDispositionUpdateFile(utilities.OutputFiles{}, utilities.GenerateFileName(d.OutputFolder, "DOJ_Disposition_Update%s.txt", d.FileNameSuffix), updates, clock.Now())
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	rejectedWriter, err := exporter.NewRejectedDecisionWriter(utilities.OutputFiles{}, utilities.GenerateFileName(d.OutputFolder, "DOJ_Disposition_Update_Rejected%s.csv", d.FileNameSuffix))
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	err = exporter.ExportRejectedDecisions(rejected, rejectedWriter)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	fmt.Printf("Wrote %d disposition updates\n", len(updates))
+	if len(rejected) > 0 {
+		utilities.PrintWarning(fmt.Sprintf("%d court decisions were rejected, see DOJ_Disposition_Update_Rejected.csv for the reasons", len(rejected)))
+	}
+	return nil
+}
+
+func (d decryptOpts) Execute(args []string) error {
+	utilities.SetErrorFileName(utilities.GenerateFileName(d.OutputFolder, "gogen_decrypt%s.err", ""), utilities.OutputFiles{})
+
+	if d.OutputFolder == "" || d.Input == "" {
+		utilities.ExitWithError(errors.New("missing required field: decrypt needs --outputs, --input and --passphrase-file or --private-key"))
+	}
+
+	decryption, err := utilities.ReadOutputDecryption(d.PassphraseFile, d.PrivateKey)
 	if err != nil {
 		utilities.ExitWithError(err)
 	}
+	count, err := utilities.DecryptFiles(d.Input, d.OutputFolder, decryption)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	fmt.Printf("Decrypted %d files\n", count)
+	return nil
+}
+
+func (v verifyManifestOpts) Execute(args []string) error {
+	utilities.SetErrorFileName(filepath.Join(filepath.Dir(v.Manifest), "gogen_verify_manifest.err"), utilities.OutputFiles{})
+
+	if v.Manifest == "" {
+		utilities.ExitWithError(errors.New("missing required field: verify-manifest needs --manifest"))
+	}
+
+	summaryBytes, err := readSummaryFile(v.Manifest, v.PassphraseFile, v.PrivateKey)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	manifest, err := exporter.ReadManifest(summaryBytes)
+	if err != nil {
+		utilities.ExitWithError(fmt.Errorf("%s: %s", v.Manifest, err))
+	}
+
+	problems := exporter.VerifyManifest(manifest, filepath.Dir(v.Manifest))
+	if len(problems) > 0 {
+		verifyErrors := make(map[string]utilities.GogenError)
+		for _, problem := range problems {
+			verifyErrors[problem.Path] = utilities.GogenError{ErrorType: "OTHER", ErrorMessage: problem.Problem}
+		}
+		utilities.ExitWithErrors(verifyErrors)
+	}
+
+	fmt.Printf("Verified %d output files\n", len(manifest.Outputs))
+	return nil
+}
+
+func (r reportOpts) Execute(args []string) error {
+	summaryPath := r.Input
+	if info, err := os.Stat(r.Input); err == nil && info.IsDir() {
+		summaryPath = utilities.GenerateFileName(r.Input, "gogen%s.json", r.FileNameSuffix)
+		if _, err := os.Stat(summaryPath); os.IsNotExist(err) {
+			summaryPath += utilities.EncryptedFileExtension
+		}
+	}
+	outputFolder := r.OutputFolder
+	if outputFolder == "" {
+		outputFolder = filepath.Dir(summaryPath)
+	}
+
+	utilities.SetErrorFileName(utilities.GenerateFileName(outputFolder, "gogen_report%s.err", r.FileNameSuffix), utilities.OutputFiles{})
+
+	if r.Input == "" {
+		utilities.ExitWithError(errors.New("missing required field: report needs --input"))
+	}
+
+	summaryBytes, err := readSummaryFile(summaryPath, r.PassphraseFile, r.PrivateKey)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	report, err := exporter.ReadReport(summaryBytes)
+	if err != nil {
+		utilities.ExitWithError(fmt.Errorf("%s: %s", summaryPath, err))
+	}
+
+	err = os.MkdirAll(outputFolder, os.ModePerm)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+	reportFilePath := utilities.GenerateFileName(outputFolder, "Report%s.html", r.FileNameSuffix)
+	err = exporter.WriteReport(utilities.OutputFiles{}, report, reportFilePath)
+	if err != nil {
+		utilities.ExitWithError(err)
+	}
+
+	fmt.Printf("Wrote report to %s\n", reportFilePath)
+	return nil
+}
+
+func readSummaryFile(summaryPath string, passphraseFile string, privateKeyFile string) ([]byte, error) {
+	summaryBytes, err := ioutil.ReadFile(summaryPath)
+	if err != nil || !strings.HasSuffix(summaryPath, utilities.EncryptedFileExtension) {
+		return summaryBytes, err
+	}
+
+	decryption, err := utilities.ReadOutputDecryption(passphraseFile, privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	var decrypted bytes.Buffer
+	err = decryption.Decrypt(bytes.NewReader(summaryBytes), &decrypted)
+	if err != nil {
+		return nil, err
+	}
+	return decrypted.Bytes(), nil
 }
 
 func (e exportTestCSVOpts) Execute(args []string) error {
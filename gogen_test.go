@@ -1,14 +1,19 @@
 package main
 
 import (
+	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"github.com/onsi/gomega/gstruct"
+	"gogen/data"
 	"gogen/exporter"
 	"gogen/utilities"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	path "path/filepath"
+	"strings"
 	"time"
 
 	. "gogen/test_fixtures"
@@ -17,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
+	"github.com/tealeg/xlsx"
 )
 
 func GetOutputSummary(filePath string) exporter.Summary {
@@ -288,6 +294,845 @@ var _ = Describe("gogen", func() {
 		}))
 	})
 
+	It("matches the county regardless of case and spacing", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "San Joaquin ")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		summary := GetOutputSummary(path.Join(outputDir, "gogen.json"))
+		Expect(summary.County).To(Equal("SAN JOAQUIN"))
+		Expect(summary.SubjectsWithProp64ConvictionCountInCounty).ToNot(BeZero())
+		Expect(summary.Warnings).To(BeEmpty())
+	})
+
+	It("rejects counties that are not in California", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUN")
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Eventually(session.Err).Should(gbytes.Say(`unknown county "SAN JOAQUN"`))
+	})
+
+	It("warns when the county has no rows in the input", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SACRAMENTO")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Err).To(gbytes.Say("Warning: .*no rows found for county SACRAMENTO"))
+
+		summary := GetOutputSummary(path.Join(outputDir, "gogen.json"))
+		Expect(summary.Warnings).To(ConsistOf(fmt.Sprintf("%s: no rows found for county SACRAMENTO", pathToDOJ)))
+	})
+
+	It("writes a linkage report when identity resolution is on", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		identityResolutionFlag := "--identity-resolution=STANDARD"
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, identityResolutionFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		linksFile, err := ioutil.ReadFile(path.Join(outputDir, "Identity_Links.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(linksFile)).To(HavePrefix("MERGED_SUBJECT_ID,SUBJECT_ID,"))
+	})
+
+	It("adds court docket numbers and lists unmatched eligible convictions when given a court CMS export", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		courtCMSFlag := fmt.Sprintf("--court-cms=%s", path.Join("test_fixtures", "court_cms.csv"))
+		courtCMSMappingFlag := fmt.Sprintf("--court-cms-mapping=%s", path.Join("test_fixtures", "court_cms_mapping.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, courtCMSFlag, courtCMSMappingFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		resultsFile, err := os.Open(path.Join(outputDir, "All_Results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		headers := results[0]
+		Expect(headers[len(headers)-2:]).To(Equal([]string{"Court Docket Number", "Court Match Confidence"}))
+
+		var dockets []string
+		for _, row := range results[1:] {
+			if docket := row[len(row)-2]; docket != "" {
+				dockets = append(dockets, docket+" "+row[len(row)-1])
+			}
+		}
+		Expect(dockets).To(ConsistOf("CR-2014-0001 1.00", "CR-2015-0042 1.00"))
+
+		unmatchedFile, err := os.Open(path.Join(outputDir, "Court_Unmatched.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		unmatched, err := csv.NewReader(unmatchedFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unmatched).To(HaveLen(14))
+	})
+
+	It("marks convictions on the exclude list as previously processed", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		excludeListFlag := fmt.Sprintf("--exclude-list=%s", path.Join("test_fixtures", "exclude_list.csv"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, excludeListFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		summary := GetOutputSummary(path.Join(outputDir, "gogen.json"))
+		Expect(summary.PreviouslyProcessedCount).To(Equal(3))
+
+		resultsFile, err := os.Open(path.Join(outputDir, "All_Results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		determinationColumn := len(results[0]) - 2
+		var reasons []string
+		for _, row := range results[1:] {
+			if row[determinationColumn] == "Previously Processed" {
+				reasons = append(reasons, row[determinationColumn+1])
+			}
+		}
+		Expect(reasons).To(ConsistOf("Petition filed 03/01/2019", "Petition filed 03/01/2019", "Listed in exclude list"))
+	})
+
+	It("writes one row per subject with a Prop 64 conviction to Subjects_Results", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		subjectsFile, err := os.Open(path.Join(outputDir, "Subjects_Results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		subjects, err := csv.NewReader(subjectsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(subjects[0]).To(Equal(exporter.SubjectHeaders))
+		Expect(subjects).To(HaveLen(10))
+		Expect(subjects).To(ContainElement([]string{"17954908", "BIRD,BIG", "08/22/1985", "8690594867", "3", "2", "0", "0", "0", "0", "0", "N", "N", "998877; 34345"}))
+		Expect(subjects).To(ContainElement([]string{"43322421", "REN,KYLO", "11/19/1983", "A234698573", "1", "1", "0", "0", "0", "0", "0", "Y", "Y", ""}))
+	})
+
+	It("writes a single Excel workbook when the output format is xlsx", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--output-format=xlsx")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(path.Join(outputDir, "All_Results.csv")).ToNot(BeAnExistingFile())
+
+		workbook, err := xlsx.OpenFile(path.Join(outputDir, "Results.xlsx"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var sheetNames []string
+		for _, sheet := range workbook.Sheets {
+			sheetNames = append(sheetNames, sheet.Name)
+		}
+		Expect(sheetNames).To(Equal([]string{"All_Results", "All_Results_Condensed", "Prop64_Results", "Hand_Review", "Subjects_Results", "Summary"}))
+		Expect(workbook.Sheet["Subjects_Results"].Rows).To(HaveLen(10))
+	})
+
+	It("writes JSON Lines results alongside the CSV results", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--output-format=csv", "--output-format=jsonl")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		resultsFile, err := os.Open(path.Join(outputDir, "All_Results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+
+		jsonResults, err := ioutil.ReadFile(path.Join(outputDir, "Prop64_Results.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(jsonResults)), "\n")
+
+		var row exporter.JSONLinesRow
+		Expect(json.Unmarshal([]byte(lines[0]), &row)).To(Succeed())
+		Expect(row.SchemaVersion).To(Equal(exporter.JSONLinesSchemaVersion))
+		Expect(row.Eligibility).ToNot(BeNil())
+		Expect(row.Eligibility.Determination).ToNot(BeEmpty())
+
+		eligibleRows := 0
+		for _, result := range results[1:] {
+			if result[len(result)-2] != "" {
+				eligibleRows++
+			}
+		}
+		Expect(lines).To(HaveLen(eligibleRows))
+	})
+
+	It("writes results to every destination given with --outputs", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		jsonOutputDir := path.Join(outputDir, "json")
+		databaseOutputDir := path.Join(outputDir, "database")
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		jsonOutputsFlag := fmt.Sprintf("--outputs=jsonl:%s", jsonOutputDir)
+		databaseOutputsFlag := fmt.Sprintf("--outputs=sqlite:%s", databaseOutputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, jsonOutputsFlag, databaseOutputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(path.Join(outputDir, "All_Results.csv")).To(BeAnExistingFile())
+		Expect(path.Join(outputDir, "gogen.json")).To(BeAnExistingFile())
+		Expect(path.Join(jsonOutputDir, "All_Results.jsonl")).To(BeAnExistingFile())
+		Expect(path.Join(jsonOutputDir, "All_Results.csv")).ToNot(BeAnExistingFile())
+
+		database, err := sql.Open("sqlite3", path.Join(databaseOutputDir, "Results.db"))
+		Expect(err).ToNot(HaveOccurred())
+		defer database.Close()
+
+		var subjectCount int
+		Expect(database.QueryRow("SELECT COUNT(*) FROM subjects_results").Scan(&subjectCount)).To(Succeed())
+		Expect(subjectCount).To(Equal(9))
+
+		var county, inputFile string
+		Expect(database.QueryRow("SELECT county, input_file FROM run").Scan(&county, &inputFile)).To(Succeed())
+		Expect(county).To(Equal("SAN JOAQUIN"))
+		Expect(inputFile).To(Equal(pathToDOJ))
+	})
+
+	It("exits with an error for an unknown output scheme", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		parquetOutputsFlag := fmt.Sprintf("--outputs=parquet:%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, parquetOutputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Eventually(session.Err).Should(gbytes.Say("unknown output scheme"))
+	})
+
+	It("exits with an error when --output-format is combined with a prefixed output", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		jsonOutputsFlag := fmt.Sprintf("--outputs=jsonl:%s", path.Join(outputDir, "json"))
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, jsonOutputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--output-format=xlsx")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Eventually(session.Err).Should(gbytes.Say("--output-format can't be used with the prefixed output"))
+		Expect(path.Join(outputDir, "Results.xlsx")).ToNot(BeAnExistingFile())
+	})
+
+	It("writes the columns of the output profile to each result file", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		outputProfileFlag := fmt.Sprintf("--output-profile=%s", path.Join("test_fixtures", "output_profile.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, outputProfileFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		for _, fileName := range []string{"All_Results.csv", "All_Results_Condensed.csv", "Prop64_Results.csv", "Hand_Review.csv"} {
+			resultsFile, err := os.Open(path.Join(outputDir, fileName))
+			Expect(err).ToNot(HaveOccurred())
+			results, err := csv.NewReader(resultsFile).ReadAll()
+			Expect(err).ToNot(HaveOccurred())
+			resultsFile.Close()
+
+			Expect(results[0]).To(Equal([]string{"SUBJECT_ID", "CII_NUMBER", "Name", "Date of Birth", "Court Case Number", "Code Section", "Disposition Date", "Eligibility Determination", "Eligibility Reason"}))
+		}
+
+		subjectsFile, err := os.Open(path.Join(outputDir, "Subjects_Results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		defer subjectsFile.Close()
+		subjects, err := csv.NewReader(subjectsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(subjects[0]).To(Equal(exporter.SubjectHeaders))
+	})
+
+	It("exits with an error for an invalid output profile", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		profilePath := path.Join(outputDir, "profile.json")
+		Expect(ioutil.WriteFile(profilePath, []byte(`{"columnSets": {"privacy": [{"doj": "SSN"}]}}`), 0644)).To(Succeed())
+		outputProfileFlag := fmt.Sprintf("--output-profile=%s", profilePath)
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, outputProfileFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Eventually(session.Err).Should(gbytes.Say("unknown DOJ column"))
+	})
+
+	It("redacts identifiers without changing the summary", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		plainOutputDir := path.Join(outputDir, "plain")
+		redactedOutputDir := path.Join(outputDir, "redacted")
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		redactKeyFlag := fmt.Sprintf("--redact-key-file=%s", path.Join("test_fixtures", "redact_key.txt"))
+
+		for _, args := range [][]string{
+			{"run", fmt.Sprintf("--outputs=%s", plainOutputDir), dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag},
+			{"run", fmt.Sprintf("--outputs=%s", redactedOutputDir), dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--redact", redactKeyFlag},
+		} {
+			session, err := gexec.Start(exec.Command(pathToGogen, args...), GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+		}
+
+		plainSummary := GetOutputSummary(path.Join(plainOutputDir, "gogen.json"))
+		redactedSummary := GetOutputSummary(path.Join(redactedOutputDir, "gogen.json"))
+		plainSummary.ProcessingTimeInSeconds, redactedSummary.ProcessingTimeInSeconds = 0, 0
+		plainSummary.Manifest, redactedSummary.Manifest = nil, nil
+		Expect(redactedSummary).To(Equal(plainSummary))
+
+		readResults := func(outputDir string) [][]string {
+			resultsFile, err := os.Open(path.Join(outputDir, "All_Results.csv"))
+			Expect(err).ToNot(HaveOccurred())
+			defer resultsFile.Close()
+			results, err := csv.NewReader(resultsFile).ReadAll()
+			Expect(err).ToNot(HaveOccurred())
+			return results
+		}
+		plainResults := readResults(plainOutputDir)
+		redactedResults := readResults(redactedOutputDir)
+		Expect(redactedResults).To(HaveLen(len(plainResults)))
+		for index, result := range redactedResults[1:] {
+			plainResult := plainResults[index+1]
+			Expect(result[data.PRI_NAME]).To(BeEmpty())
+			Expect(result[data.SUBJECT_ID]).ToNot(Equal(plainResult[data.SUBJECT_ID]))
+			Expect(result[data.PRI_DOB]).To(Equal(plainResult[data.PRI_DOB][:4]))
+			Expect(result[data.OFFENSE_DESCR]).To(Equal(plainResult[data.OFFENSE_DESCR]))
+			Expect(result[len(result)-2]).To(Equal(plainResult[len(plainResult)-2]))
+		}
+	})
+
+	It("exits with an error when redacting without a key", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, eligibilityOptionsFlag, "--redact")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Eventually(session.Err).Should(gbytes.Say("--redact needs a --redact-key-file"))
+	})
+
+	It("writes a run manifest that gogen verify-manifest checks the outputs against", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		manifestPath := path.Join(outputDir, "gogen.json")
+		summary := GetOutputSummary(manifestPath)
+		Expect(summary.Manifest.GogenVersion).To(Equal(VERSION))
+		Expect(summary.Manifest.County).To(Equal("SAN JOAQUIN"))
+		Expect(summary.Manifest.ComputeAt).To(Equal("2019-11-11"))
+		Expect(summary.Manifest.Options).To(HaveKeyWithValue("leap-day-rule", "MAR1"))
+		Expect(summary.Manifest.FinishedAt).ToNot(BeTemporally("<", summary.Manifest.StartedAt))
+		Expect(summary.Manifest.Inputs[0].Path).To(Equal(pathToDOJ))
+		Expect(*summary.Manifest.Inputs[0].Rows).To(Equal(38))
+
+		var resultsManifestFile exporter.ManifestFile
+		for _, output := range summary.Manifest.Outputs {
+			if output.Path == "All_Results.csv" {
+				resultsManifestFile = output
+			}
+		}
+		Expect(resultsManifestFile.SHA256).To(HaveLen(64))
+		Expect(*resultsManifestFile.Rows).To(Equal(38))
+
+		command = exec.Command(pathToGogen, "verify-manifest", "--manifest="+manifestPath)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Eventually(session).Should(gbytes.Say(fmt.Sprintf("Verified %d output files", len(summary.Manifest.Outputs))))
+
+		resultsFile, err := os.OpenFile(path.Join(outputDir, "All_Results.csv"), os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = resultsFile.WriteString("tampered\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultsFile.Close()).To(Succeed())
+
+		command = exec.Command(pathToGogen, "verify-manifest", "--manifest="+manifestPath)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Eventually(session.Err).Should(gbytes.Say("All_Results.csv: SHA-256 is"))
+	})
+
+	It("adds equity breakdowns with small counts left out to the summary", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		unsuppressedOutputDir := path.Join(outputDir, "unsuppressed")
+		suppressedOutputDir := path.Join(outputDir, "suppressed")
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		for _, args := range [][]string{
+			{"run", fmt.Sprintf("--outputs=%s", unsuppressedOutputDir), dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--equity-breakdowns", "--equity-min-cell-size=1"},
+			{"run", fmt.Sprintf("--outputs=%s", suppressedOutputDir), dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--equity-breakdowns"},
+		} {
+			session, err := gexec.Start(exec.Command(pathToGogen, args...), GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+		}
+
+		summary := GetOutputSummary(path.Join(unsuppressedOutputDir, "gogen.json"))
+		Expect(summary.EquityBreakdowns.ByRace).To(Equal(map[string]map[string]int{
+			"UNKNOWN": {
+				"Prop64Convictions":         summary.Prop64FelonyConvictionsCountInCounty + summary.Prop64NonFelonyConvictionsCountInCounty,
+				"EligibleConvictions":       15,
+				"CountSubjectsNoFelony":     summary.ReliefWithCurrentEligibilityChoices["CountSubjectsNoFelony"],
+				"CountSubjectsNoConviction": summary.ReliefWithCurrentEligibilityChoices["CountSubjectsNoConviction"],
+			},
+		}))
+		Expect(summary.EquityBreakdowns.ByGender).To(Equal(summary.EquityBreakdowns.ByRace))
+		Expect(summary.EquityBreakdowns.ByAgeBand).To(HaveKey("50-59"))
+
+		suppressedSummary := GetOutputSummary(path.Join(suppressedOutputDir, "gogen.json"))
+		Expect(suppressedSummary.EquityBreakdowns.MinimumCellSize).To(Equal(11))
+		for _, groupCounts := range []map[string]map[string]int{suppressedSummary.EquityBreakdowns.ByRace, suppressedSummary.EquityBreakdowns.ByGender, suppressedSummary.EquityBreakdowns.ByAgeBand} {
+			for _, counts := range groupCounts {
+				for _, count := range counts {
+					Expect(count == 0 || count >= 11).To(BeTrue())
+				}
+			}
+		}
+		Expect(suppressedSummary.EquityBreakdowns.ByAgeBand["50-59"]).ToNot(HaveKey("Prop64Convictions"))
+	})
+
+	It("writes a standalone HTML report from the summary of a run", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		command = exec.Command(pathToGogen, "report", "--input="+outputDir)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Eventually(session).Should(gbytes.Say("Wrote report to"))
+
+		reportBytes, err := ioutil.ReadFile(path.Join(outputDir, "Report.html"))
+		Expect(err).ToNot(HaveOccurred())
+		report := string(reportBytes)
+		Expect(report).To(ContainSubstring("Prop 64 relief report for SAN JOAQUIN"))
+		Expect(report).To(ContainSubstring("Eligibility computed at 2019-11-11"))
+		Expect(report).To(ContainSubstring("Dismiss all Prop 64 and related"))
+		Expect(report).To(ContainSubstring("Prop 64 convictions by disposition year"))
+		Expect(report).ToNot(ContainSubstring("<script"))
+		Expect(report).ToNot(ContainSubstring("http"))
+	})
+
+	It("exits with an error when the report input has no summary", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		command := exec.Command(pathToGogen, "report", "--input="+outputDir)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Expect(path.Join(outputDir, "gogen_report.err")).To(BeAnExistingFile())
+	})
+
+	It("encrypts every output file and decrypts them with gogen decrypt", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		encryptedOutputDir := path.Join(outputDir, "encrypted")
+		decryptedOutputDir := path.Join(outputDir, "decrypted")
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", encryptedOutputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		passphraseFile := path.Join("test_fixtures", "encryption_passphrase.txt")
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--output-format=csv", "--output-format=jsonl", "--encrypt-passphrase-file="+passphraseFile)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session, 10*time.Second).Should(gexec.Exit(0))
+
+		files, err := ioutil.ReadDir(encryptedOutputDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(BeEmpty())
+		for _, file := range files {
+			Expect(file.Name()).To(HaveSuffix(utilities.EncryptedFileExtension))
+		}
+
+		command = exec.Command(pathToGogen, "decrypt", "--input="+encryptedOutputDir, "--outputs="+decryptedOutputDir, "--passphrase-file="+passphraseFile)
+		session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session, 10*time.Second).Should(gexec.Exit(0))
+		Eventually(session).Should(gbytes.Say(fmt.Sprintf("Decrypted %d files", len(files))))
+
+		summary := GetOutputSummary(path.Join(decryptedOutputDir, "gogen.json"))
+		Expect(summary.County).To(Equal("SAN JOAQUIN"))
+
+		resultsFile, err := os.Open(path.Join(decryptedOutputDir, "All_Results.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		defer resultsFile.Close()
+		results, err := csv.NewReader(resultsFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0][0]).To(Equal("RECORD_ID"))
+		Expect(path.Join(decryptedOutputDir, "All_Results.jsonl")).To(BeAnExistingFile())
+	})
+
+	It("exits with an error when encrypting a SQLite database", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=sqlite:%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+		passphraseFlag := fmt.Sprintf("--encrypt-passphrase-file=%s", path.Join("test_fixtures", "encryption_passphrase.txt"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, passphraseFlag)
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+		Expect(path.Join(outputDir, "Results.db")).ToNot(BeAnExistingFile())
+		Expect(path.Join(outputDir, "gogen.err.enc")).To(BeAnExistingFile())
+	})
+
+	It("writes court orders grouped by case when given an order format", func() {
+
+		outputDir, err = ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+		Expect(err).ToNot(HaveOccurred())
+
+		pathToGogen, err := gexec.Build("gogen")
+		Expect(err).ToNot(HaveOccurred())
+
+		outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+		dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+		countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+		computeAtFlag := "--compute-at=2019-11-11"
+		eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+		command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag, "--order-format=csv", "--order-batch-size=50")
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session).Should(gexec.Exit(0))
+
+		ordersFile, err := os.Open(path.Join(outputDir, "Orders", "Orders_Batch_001.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		orders, err := csv.NewReader(ordersFile).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(orders[0][0]).To(Equal("CASE_NUMBER"))
+		Expect(orders).To(HaveLen(16))
+	})
+
 	It("can accept path to eligibility options file", func() {
 
 		outputDir, err = ioutil.TempDir("/tmp", "gogen")
@@ -314,6 +1159,8 @@ var _ = Describe("gogen", func() {
 
 		Eventually(session).Should(gexec.Exit(0))
 
+		Expect(path.Join(outputDir, "Conviction_Years.csv")).To(BeAnExistingFile())
+
 		summary := GetOutputSummary(path.Join(outputDir, "gogen.json"))
 		Expect(summary).To(gstruct.MatchAllFields(gstruct.Fields{
 			"County":                  Equal("SACRAMENTO"),
@@ -330,6 +1177,13 @@ var _ = Describe("gogen", func() {
 				"CountSubjectsNoConviction":           Equal(4),
 				"CountSubjectsNoConvictionLast7Years": Equal(2),
 			}),
+			"ReliefWithDismissAllProp64AndRelated": gstruct.MatchAllKeys(gstruct.Keys{
+				"CountSubjectsNoFelony":               Equal(5),
+				"CountSubjectsNoConviction":           Equal(4),
+				"CountSubjectsNoConvictionLast7Years": Equal(2),
+			}),
+			"Prop64ConvictionsCountInCountyByYear":        And(HaveLen(14), HaveKeyWithValue("2015", map[string]int{"Felony": 2, "NonFelony": 1})),
+			"Prop64ConvictionsCountByYearByDetermination": And(HaveLen(14), HaveKeyWithValue("2015", map[string]int{"Eligible for Dismissal": 2, "Eligible for Reduction": 1})),
 			"Prop64ConvictionsCountInCountyByCodeSection": gstruct.MatchAllKeys(gstruct.Keys{
 				"11357": Equal(3),
 				"11358": Equal(7),
@@ -354,9 +1208,244 @@ var _ = Describe("gogen", func() {
 				"Individual is deceased":                   Equal(1),
 				"Only has 11357-60 charges":                Equal(1),
 			}),
+			"HandReviewCountByTrigger": BeEmpty(),
+			"PreviouslyProcessedCount": Equal(0),
+			"EquityBreakdowns":         BeNil(),
+			"Warnings":                 BeEmpty(),
+			"Manifest":                 Not(BeNil()),
 		}))
 	})
 
+	Describe("Comparing eligibility options files", func() {
+		It("evaluates each options file against the same input and reports the differences", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToInputExcel := path.Join("test_fixtures", "configurable_flow.xlsx")
+			inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+			pathToGogen, err := gexec.Build("gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			compareCommand := "compare"
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+			countyFlag := fmt.Sprintf("--county=%s", "SACRAMENTO")
+			computeAtFlag := "--compute-at=2019-11-11"
+			firstOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+			secondOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options_dismiss_all.json"))
+
+			command := exec.Command(pathToGogen, compareCommand, outputsFlag, dojFlag, countyFlag, computeAtFlag, firstOptionsFlag, secondOptionsFlag)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(0))
+			Ω(path.Join(outputDir, "Comparison_Results.csv")).Should(BeAnExistingFile())
+
+			bytes, _ := ioutil.ReadFile(path.Join(outputDir, "gogen_comparison.json"))
+			var summary exporter.ComparisonSummary
+			json.Unmarshal(bytes, &summary)
+
+			Expect(summary.LineCount).To(Equal(38))
+			Expect(summary.DifferingRowsCount).To(Equal(1))
+			Expect(summary.Configurations).To(HaveLen(2))
+			Expect(summary.Configurations[0].EligibilityOptions).To(Equal("eligibility_options"))
+			Expect(summary.Configurations[0].ConvictionCountByDetermination).To(Equal(map[string]int{
+				"Eligible for Dismissal": 17,
+				"Eligible for Reduction": 1,
+			}))
+			Expect(summary.Configurations[1].EligibilityOptions).To(Equal("eligibility_options_dismiss_all"))
+			Expect(summary.Configurations[1].ConvictionCountByDetermination).To(Equal(map[string]int{
+				"Eligible for Dismissal": 18,
+			}))
+			Expect(summary.Configurations[1].CountSubjectsNoFelony).To(Equal(5))
+			Expect(summary.Configurations[1].CountSubjectsNoConviction).To(Equal(4))
+		})
+
+		It("requires at least two eligibility options files", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToGogen, err := gexec.Build("gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+			countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+			optionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+			command := exec.Command(pathToGogen, "compare", outputsFlag, dojFlag, countyFlag, optionsFlag)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(utilities.ERROR_EXIT))
+			Eventually(session.Err).Should(gbytes.Say("at least two --eligibility-options"))
+		})
+	})
+
+	Describe("Processing several counties", func() {
+		It("writes a folder for each county and a statewide summary", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+			countyOptionsDir, err := ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			dismissAllOptions, err := ioutil.ReadFile(path.Join("test_fixtures", "eligibility_options_dismiss_all.json"))
+			Expect(err).ToNot(HaveOccurred())
+			err = ioutil.WriteFile(path.Join(countyOptionsDir, "YOLO.json"), dismissAllOptions, 0644)
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToInputExcel := path.Join("test_fixtures", "configurable_flow.xlsx")
+			inputCSV, _, _ := ExtractFullCSVFixtures(pathToInputExcel)
+
+			pathToGogen, err := gexec.Build("gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", inputCSV)
+			countyFlag := "--county=ALL"
+			computeAtFlag := "--compute-at=2019-11-11"
+			optionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+			countyOptionsFlag := fmt.Sprintf("--county-eligibility-options=%s", countyOptionsDir)
+
+			command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, optionsFlag, countyOptionsFlag)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(0))
+
+			Ω(path.Join(outputDir, "SACRAMENTO", "All_Results.csv")).Should(BeAnExistingFile())
+			Ω(path.Join(outputDir, "YOLO", "Prop64_Results.csv")).Should(BeAnExistingFile())
+			Ω(path.Join(outputDir, "All_Results.csv")).ShouldNot(BeAnExistingFile())
+
+			rowCount := 0
+			for _, county := range []string{"SACRAMENTO", "YOLO"} {
+				resultsFile, err := os.Open(path.Join(outputDir, county, "All_Results.csv"))
+				Expect(err).ToNot(HaveOccurred())
+				results, err := csv.NewReader(resultsFile).ReadAll()
+				resultsFile.Close()
+				Expect(err).ToNot(HaveOccurred())
+
+				countyColumn := -1
+				for index, header := range results[0] {
+					if header == "STP_ORI_CNTY_NAME" {
+						countyColumn = index
+					}
+				}
+				Expect(countyColumn).ToNot(Equal(-1))
+				Expect(len(results)).To(BeNumerically(">", 1))
+				for _, row := range results[1:] {
+					Expect(row[countyColumn]).To(Equal(county))
+				}
+				rowCount += len(results) - 1
+			}
+			Expect(rowCount).To(BeNumerically("<=", 38))
+
+			sacramentoSummary := GetOutputSummary(path.Join(outputDir, "SACRAMENTO", "gogen.json"))
+			Expect(sacramentoSummary.County).To(Equal("SACRAMENTO"))
+			Expect(sacramentoSummary.SubjectsWithSomeReliefCount).To(Equal(12))
+
+			bytes, _ := ioutil.ReadFile(path.Join(outputDir, "gogen.json"))
+			var statewideSummary exporter.StatewideSummary
+			json.Unmarshal(bytes, &statewideSummary)
+
+			Expect(statewideSummary.Counties).To(Equal([]string{"SACRAMENTO", "YOLO"}))
+			Expect(statewideSummary.LineCount).To(Equal(38))
+			Expect(statewideSummary.CountySummaries["SACRAMENTO"].ConvictionDismissalCountByCodeSection).To(Equal(sacramentoSummary.ConvictionDismissalCountByCodeSection))
+			Expect(statewideSummary.Statewide.County).To(Equal("ALL"))
+			Expect(statewideSummary.Statewide.SubjectsWithSomeReliefCount).To(Equal(
+				sacramentoSummary.SubjectsWithSomeReliefCount + statewideSummary.CountySummaries["YOLO"].SubjectsWithSomeReliefCount))
+		})
+
+		It("only evaluates the listed counties", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToGogen, err := gexec.Build("gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+			countyFlag := "--county=SAN JOAQUIN,SACRAMENTO"
+			computeAtFlag := "--compute-at=2019-11-11"
+			optionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+			command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, optionsFlag)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session).Should(gexec.Exit(0))
+
+			Ω(path.Join(outputDir, "SAN_JOAQUIN", "All_Results.csv")).Should(BeAnExistingFile())
+			Ω(path.Join(outputDir, "SACRAMENTO", "All_Results.csv")).Should(BeAnExistingFile())
+			Ω(path.Join(outputDir, "YOLO")).ShouldNot(BeADirectory())
+
+			bytes, _ := ioutil.ReadFile(path.Join(outputDir, "gogen.json"))
+			var statewideSummary exporter.StatewideSummary
+			json.Unmarshal(bytes, &statewideSummary)
+			Expect(statewideSummary.Counties).To(Equal([]string{"SACRAMENTO", "SAN JOAQUIN"}))
+		})
+	})
+
+	Describe("Writing a DOJ disposition update file", func() {
+		It("writes granted convictions and rejects decisions that cannot be traced", func() {
+			outputDir, err = ioutil.TempDir("/tmp", "gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToDOJ, err = path.Abs(path.Join("test_fixtures", "extra_comma.csv"))
+			Expect(err).ToNot(HaveOccurred())
+
+			pathToGogen, err := gexec.Build("gogen")
+			Expect(err).ToNot(HaveOccurred())
+
+			outputsFlag := fmt.Sprintf("--outputs=%s", outputDir)
+			dojFlag := fmt.Sprintf("--input-doj=%s", pathToDOJ)
+			countyFlag := fmt.Sprintf("--county=%s", "SAN JOAQUIN")
+			computeAtFlag := "--compute-at=2019-11-11"
+			eligibilityOptionsFlag := fmt.Sprintf("--eligibility-options=%s", path.Join("test_fixtures", "eligibility_options.json"))
+
+			command := exec.Command(pathToGogen, "run", outputsFlag, dojFlag, countyFlag, computeAtFlag, eligibilityOptionsFlag)
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			decisionsPath := path.Join(outputDir, "decisions.csv")
+			decisions := "SUBJECT_ID,CNT_ORDER,DECISION,DECISION_DATE\n" +
+				"17954908,101001008000,GRANTED,03/04/2020\n" +
+				"84734892,101001024000,GRANTED,03/04/2020\n" +
+				"43322421,101001031000,DENIED,03/04/2020\n" +
+				"43322421,999999999999,GRANTED,03/04/2020\n"
+			Expect(ioutil.WriteFile(decisionsPath, []byte(decisions), os.ModePerm)).To(Succeed())
+
+			resultsFlag := fmt.Sprintf("--results=%s", path.Join(outputDir, "All_Results.csv"))
+			decisionsFlag := fmt.Sprintf("--court-decisions=%s", decisionsPath)
+			command = exec.Command(pathToGogen, "disposition-update", outputsFlag, resultsFlag, decisionsFlag)
+			session, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Eventually(session.Err).Should(gbytes.Say("1 court decisions were rejected"))
+
+			update, err := ioutil.ReadFile(path.Join(outputDir, "DOJ_Disposition_Update.txt"))
+			Expect(err).ToNot(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(update)), "\r\n")
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(HaveSuffix("00000002"))
+			Expect(lines[1]).To(Equal("D17954908  8690594867101001008000998877              D20200304"))
+			Expect(lines[2]).To(Equal("D84734892  A971951352101001024000                    R20200304"))
+			Expect(lines[3]).To(Equal("T00000002"))
+
+			rejected, err := ioutil.ReadFile(path.Join(outputDir, "DOJ_Disposition_Update_Rejected.csv"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(rejected)).To(ContainSubstring("5,43322421,999999999999,GRANTED,no conviction in the results"))
+		})
+	})
+
 	Describe("Processing multiple input files", func() {
 		It("nests and indexes the names of the results files for each input file", func() {
 			outputDir, err = ioutil.TempDir("/tmp", "gogen")
@@ -440,6 +1529,13 @@ var _ = Describe("gogen", func() {
 					"CountSubjectsNoConviction":           Equal(8),
 					"CountSubjectsNoConvictionLast7Years": Equal(4),
 				}),
+				"ReliefWithDismissAllProp64AndRelated": gstruct.MatchAllKeys(gstruct.Keys{
+					"CountSubjectsNoFelony":               Equal(10),
+					"CountSubjectsNoConviction":           Equal(8),
+					"CountSubjectsNoConvictionLast7Years": Equal(4),
+				}),
+				"Prop64ConvictionsCountInCountyByYear":        And(HaveLen(14), HaveKeyWithValue("2015", map[string]int{"Felony": 4, "NonFelony": 2})),
+				"Prop64ConvictionsCountByYearByDetermination": And(HaveLen(14), HaveKeyWithValue("2015", map[string]int{"Eligible for Dismissal": 4, "Eligible for Reduction": 2})),
 				"Prop64ConvictionsCountInCountyByCodeSection": gstruct.MatchAllKeys(gstruct.Keys{
 					"11357": Equal(6),
 					"11358": Equal(14),
@@ -464,6 +1560,11 @@ var _ = Describe("gogen", func() {
 					"Individual is deceased":                   Equal(2),
 					"Only has 11357-60 charges":                Equal(2),
 				}),
+				"HandReviewCountByTrigger": BeEmpty(),
+				"PreviouslyProcessedCount": Equal(0),
+				"EquityBreakdowns":         BeNil(),
+				"Warnings":                 BeEmpty(),
+				"Manifest":                 Not(BeNil()),
 			}))
 		})
 
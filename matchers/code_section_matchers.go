@@ -1,7 +1,9 @@
 package matchers
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 )
 
 var prop64matcher = regexp.MustCompile(`(11357|11358|11359|11360)`)
@@ -13,6 +15,8 @@ var Prop64MatchersByCodeSection = map[string]*regexp.Regexp{
 	"11360":                 regexp.MustCompile(`11360.*`),
 }
 var Section11357SubSectionMatcher = regexp.MustCompile(`11357\(([A-D])\)`)
+var prop64SubSectionMatcher = regexp.MustCompile(`(?i)(11357|11358|11359|11360)\s*\(([A-D])\)`)
+var prop64CodeSectionKeyMatcher = regexp.MustCompile(`^(11357|11358|11359|11360)(\([A-D]\))?$`)
 
 func ExtractProp64Section(codeSection string) (bool, string) {
 	if IsProp64Charge(codeSection) {
@@ -45,4 +49,32 @@ func Extract11357SubSection(codeSection string) (bool, string) {
 	} else {
 		return false, ""
 	}
-}
\ No newline at end of file
+}
+
+func ExtractProp64SubSection(codeSection string) (bool, string) {
+	result := prop64SubSectionMatcher.FindStringSubmatch(codeSection)
+	if result != nil {
+		return true, fmt.Sprintf("%s(%s)", result[1], strings.ToUpper(result[2]))
+	} else {
+		return false, ""
+	}
+}
+
+func IsProp64CodeSectionKey(key string) bool {
+	return prop64CodeSectionKeyMatcher.MatchString(key)
+}
+
+func IsProp64SubSectionKey(key string) bool {
+	return IsProp64CodeSectionKey(key) && strings.Contains(key, "(")
+}
+
+func MatchesProp64CodeSectionKey(key string, codeSection string) bool {
+	if IsProp64SubSectionKey(key) {
+		ok, subSection := ExtractProp64SubSection(codeSection)
+		return ok && subSection == key
+	}
+	if IsProp64CodeSectionKey(key) {
+		return Prop64MatchersByCodeSection[key].MatchString(codeSection)
+	}
+	return false
+}
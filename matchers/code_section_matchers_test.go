@@ -90,4 +90,38 @@ var _ = Describe("Matched11357SubSection", func() {
 		Expect(getMatched11357SubSection("11357")).To(Equal(""))
 		Expect(getMatched11357SubSection("647(f) HS")).To(Equal(""))
 	})
-})
\ No newline at end of file
+})
+var _ = Describe("ExtractProp64SubSection", func() {
+	It("returns the section and upper-cased subsection for any Prop 64 code section", func() {
+		_, subSection := matchers.ExtractProp64SubSection("11357(a) HS")
+		Expect(subSection).To(Equal("11357(A)"))
+		_, subSection = matchers.ExtractProp64SubSection("664/11359 (B) HS")
+		Expect(subSection).To(Equal("11359(B)"))
+	})
+
+	It("returns false if there is no subsection", func() {
+		ok, subSection := matchers.ExtractProp64SubSection("11357 HS")
+		Expect(ok).To(BeFalse())
+		Expect(subSection).To(Equal(""))
+	})
+})
+
+var _ = Describe("MatchesProp64CodeSectionKey", func() {
+	It("matches whole section keys against any subsection", func() {
+		Expect(matchers.MatchesProp64CodeSectionKey("11357", "11357(A) HS")).To(BeTrue())
+		Expect(matchers.MatchesProp64CodeSectionKey("11357", "11357 HS")).To(BeTrue())
+		Expect(matchers.MatchesProp64CodeSectionKey("11357", "11358 HS")).To(BeFalse())
+	})
+
+	It("matches subsection keys only against that subsection", func() {
+		Expect(matchers.MatchesProp64CodeSectionKey("11357(A)", "11357(A) HS")).To(BeTrue())
+		Expect(matchers.MatchesProp64CodeSectionKey("11357(A)", "11357(B) HS")).To(BeFalse())
+		Expect(matchers.MatchesProp64CodeSectionKey("11357(A)", "11357 HS")).To(BeFalse())
+	})
+
+	It("does not match unknown keys", func() {
+		Expect(matchers.IsProp64CodeSectionKey("11361")).To(BeFalse())
+		Expect(matchers.IsProp64CodeSectionKey("11357(E)")).To(BeFalse())
+		Expect(matchers.MatchesProp64CodeSectionKey("11361", "11361 HS")).To(BeFalse())
+	})
+})
@@ -0,0 +1,549 @@
+package utilities
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const EncryptedFileExtension = ".enc"
+
+const (
+	encryptionMagic     = "GOGENENC1\n"
+	recipientPassphrase = byte(1)
+	recipientPublicKey  = byte(2)
+	encryptionChunkSize = 64 * 1024
+	scryptLogN          = 15
+	maxScryptLogN       = 20
+	fileKeySize         = 32
+	saltSize            = 16
+)
+
+var ErrDecryption = errors.New("could not decrypt: wrong passphrase or private key, or the file was modified")
+
+type OutputEncryption struct {
+	salt             []byte
+	keyEncryptionKey []byte
+	publicKey        *rsa.PublicKey
+}
+
+type OutputDecryption struct {
+	passphrase        []byte
+	privateKey        *rsa.PrivateKey
+	keyEncryptionKeys map[string][]byte
+}
+
+// OutputFiles creates the files of one run, encrypting them when the run
+// was given an OutputEncryption, and keeps the names of the files it created
+// for the manifest. The zero OutputFiles writes plain files and keeps no names.
+type OutputFiles struct {
+	encryption *OutputEncryption
+	created    *[]string
+}
+
+func NewOutputFiles(encryption *OutputEncryption) OutputFiles {
+	return OutputFiles{encryption: encryption, created: &[]string{}}
+}
+
+func (f OutputFiles) Encrypted() bool {
+	return f.encryption != nil
+}
+
+func (f OutputFiles) Name(filePath string) string {
+	if f.Encrypted() {
+		return filePath + EncryptedFileExtension
+	}
+	return filePath
+}
+
+func (f OutputFiles) Record(filePath string) {
+	if f.created == nil {
+		return
+	}
+	for _, outputFile := range *f.created {
+		if outputFile == filePath {
+			return
+		}
+	}
+	*f.created = append(*f.created, filePath)
+}
+
+func (f OutputFiles) Created() []string {
+	if f.created == nil {
+		return nil
+	}
+	return append([]string{}, *f.created...)
+}
+
+func (f OutputFiles) Create(filePath string) (io.WriteCloser, error) {
+	file, err := os.Create(f.Name(filePath))
+	if err != nil {
+		return nil, err
+	}
+	f.Record(file.Name())
+	if !f.Encrypted() {
+		return &outputFile{file: file}, nil
+	}
+
+	writer, err := f.encryption.NewWriter(file)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (f OutputFiles) WriteFile(filePath string, contents []byte) error {
+	file, err := f.Create(filePath)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(contents)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// NewPassphraseEncryption runs scrypt once, so every file of a run shares
+// the salt and key-encryption key while still getting its own file key.
+func NewPassphraseEncryption(passphrase []byte) (*OutputEncryption, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("encryption passphrase is empty")
+	}
+	salt := make([]byte, saltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	keyEncryptionKey, err := scrypt.Key(passphrase, salt, 1<<scryptLogN, 8, 1, fileKeySize)
+	if err != nil {
+		return nil, err
+	}
+	return &OutputEncryption{salt: salt, keyEncryptionKey: keyEncryptionKey}, nil
+}
+
+func NewPublicKeyEncryption(publicKeyPEM []byte) (*OutputEncryption, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+	var publicKey interface{}
+	var err error
+	if block.Type == "RSA PUBLIC KEY" {
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	} else {
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+	return &OutputEncryption{publicKey: rsaPublicKey}, nil
+}
+
+func ReadOutputEncryption(passphraseFile string, publicKeyFile string) (*OutputEncryption, error) {
+	if passphraseFile != "" && publicKeyFile != "" {
+		return nil, errors.New("encrypt with either a passphrase or a public key, not both")
+	}
+	if passphraseFile != "" {
+		passphrase, err := readPassphrase(passphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewPassphraseEncryption(passphrase)
+	}
+	publicKeyPEM, err := ioutil.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	encryption, err := NewPublicKeyEncryption(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", publicKeyFile, err)
+	}
+	return encryption, nil
+}
+
+func NewPassphraseDecryption(passphrase []byte) *OutputDecryption {
+	return &OutputDecryption{passphrase: passphrase}
+}
+
+func NewPrivateKeyDecryption(privateKeyPEM []byte) (*OutputDecryption, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	var privateKey interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return &OutputDecryption{privateKey: rsaPrivateKey}, nil
+}
+
+func ReadOutputDecryption(passphraseFile string, privateKeyFile string) (*OutputDecryption, error) {
+	if (passphraseFile == "") == (privateKeyFile == "") {
+		return nil, errors.New("decrypt with either a passphrase or a private key")
+	}
+	if passphraseFile != "" {
+		passphrase, err := readPassphrase(passphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewPassphraseDecryption(passphrase), nil
+	}
+	privateKeyPEM, err := ioutil.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	decryption, err := NewPrivateKeyDecryption(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", privateKeyFile, err)
+	}
+	return decryption, nil
+}
+
+func (e *OutputEncryption) NewWriter(destination io.WriteCloser) (io.WriteCloser, error) {
+	fileKey := make([]byte, fileKeySize)
+	_, err := rand.Read(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := bytes.NewBufferString(encryptionMagic)
+	if e.publicKey != nil {
+		wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, e.publicKey, fileKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		header.WriteByte(recipientPublicKey)
+		binary.Write(header, binary.BigEndian, uint16(len(wrappedKey)))
+		header.Write(wrappedKey)
+	} else {
+		keyCipher, err := newGCM(e.keyEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, keyCipher.NonceSize())
+		_, err = rand.Read(nonce)
+		if err != nil {
+			return nil, err
+		}
+		header.WriteByte(recipientPassphrase)
+		header.Write(e.salt)
+		header.WriteByte(scryptLogN)
+		header.Write(nonce)
+		header.Write(keyCipher.Seal(nil, nonce, fileKey, []byte(encryptionMagic)))
+	}
+
+	streamCipher, err := newGCM(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = destination.Write(header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{destination: destination, cipher: streamCipher, header: header.Bytes()}, nil
+}
+
+func (d *OutputDecryption) Decrypt(source io.Reader, destination io.Writer) error {
+	reader := bufio.NewReader(source)
+	header := bytes.NewBuffer(nil)
+	readHeader := func(length int) ([]byte, error) {
+		field := make([]byte, length)
+		_, err := io.ReadFull(reader, field)
+		if err != nil {
+			return nil, errors.New("not a gogen encrypted file")
+		}
+		header.Write(field)
+		return field, nil
+	}
+
+	magic, err := readHeader(len(encryptionMagic))
+	if err != nil || string(magic) != encryptionMagic {
+		return errors.New("not a gogen encrypted file")
+	}
+	recipient, err := readHeader(1)
+	if err != nil {
+		return err
+	}
+
+	var fileKey []byte
+	switch recipient[0] {
+	case recipientPublicKey:
+		if d.privateKey == nil {
+			return errors.New("file was encrypted with a public key: decrypt it with the private key")
+		}
+		length, err := readHeader(2)
+		if err != nil {
+			return err
+		}
+		wrappedKey, err := readHeader(int(binary.BigEndian.Uint16(length)))
+		if err != nil {
+			return err
+		}
+		fileKey, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, d.privateKey, wrappedKey, nil)
+		if err != nil {
+			return ErrDecryption
+		}
+	case recipientPassphrase:
+		if d.passphrase == nil {
+			return errors.New("file was encrypted with a passphrase: decrypt it with the passphrase")
+		}
+		salt, err := readHeader(saltSize)
+		if err != nil {
+			return err
+		}
+		logN, err := readHeader(1)
+		if err != nil {
+			return err
+		}
+		if logN[0] > maxScryptLogN {
+			return errors.New("not a gogen encrypted file")
+		}
+		keyEncryptionKey, err := d.keyEncryptionKey(salt, logN[0])
+		if err != nil {
+			return err
+		}
+		keyCipher, err := newGCM(keyEncryptionKey)
+		if err != nil {
+			return err
+		}
+		nonce, err := readHeader(keyCipher.NonceSize())
+		if err != nil {
+			return err
+		}
+		wrappedKey, err := readHeader(fileKeySize + keyCipher.Overhead())
+		if err != nil {
+			return err
+		}
+		fileKey, err = keyCipher.Open(nil, nonce, wrappedKey, []byte(encryptionMagic))
+		if err != nil {
+			return ErrDecryption
+		}
+	default:
+		return errors.New("not a gogen encrypted file")
+	}
+
+	streamCipher, err := newGCM(fileKey)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, encryptionChunkSize+streamCipher.Overhead())
+	for counter := uint64(0); ; counter++ {
+		length, err := io.ReadFull(reader, chunk)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		final := length < len(chunk)
+		if !final {
+			_, peekErr := reader.Peek(1)
+			final = peekErr == io.EOF
+		}
+
+		plaintext, err := streamCipher.Open(nil, chunkNonce(counter, final), chunk[:length], header.Bytes())
+		if err != nil {
+			return ErrDecryption
+		}
+		_, err = destination.Write(plaintext)
+		if err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// keyEncryptionKey keeps the keys it derives, since all the files of a run
+// share one salt and should not each pay for scrypt again.
+func (d *OutputDecryption) keyEncryptionKey(salt []byte, logN byte) ([]byte, error) {
+	cacheKey := string(append(append([]byte{}, salt...), logN))
+	if keyEncryptionKey, ok := d.keyEncryptionKeys[cacheKey]; ok {
+		return keyEncryptionKey, nil
+	}
+	keyEncryptionKey, err := scrypt.Key(d.passphrase, salt, 1<<uint(logN), 8, 1, fileKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if d.keyEncryptionKeys == nil {
+		d.keyEncryptionKeys = make(map[string][]byte)
+	}
+	d.keyEncryptionKeys[cacheKey] = keyEncryptionKey
+	return keyEncryptionKey, nil
+}
+
+type outputFile struct {
+	file   *os.File
+	closed bool
+}
+
+func (f *outputFile) Write(p []byte) (int, error) {
+	return f.file.Write(p)
+}
+
+func (f *outputFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.file.Close()
+}
+
+type encryptingWriter struct {
+	destination io.WriteCloser
+	cipher      cipher.AEAD
+	header      []byte
+	buffer      []byte
+	counter     uint64
+	closed      bool
+}
+
+func (w *encryptingWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encrypted file")
+	}
+	written := len(p)
+	for len(w.buffer)+len(p) > encryptionChunkSize {
+		fill := encryptionChunkSize - len(w.buffer)
+		w.buffer = append(w.buffer, p[:fill]...)
+		p = p[fill:]
+		err := w.sealChunk(false)
+		if err != nil {
+			return 0, err
+		}
+	}
+	w.buffer = append(w.buffer, p...)
+	return written, nil
+}
+
+func (w *encryptingWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.sealChunk(true)
+	closeErr := w.destination.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *encryptingWriter) sealChunk(final bool) error {
+	_, err := w.destination.Write(w.cipher.Seal(nil, chunkNonce(w.counter, final), w.buffer, w.header))
+	w.counter++
+	w.buffer = w.buffer[:0]
+	return err
+}
+
+func chunkNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func readPassphrase(passphraseFile string) ([]byte, error) {
+	passphrase, err := ioutil.ReadFile(passphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(passphrase), "\r\n")), nil
+}
+
+func DecryptFiles(input string, outputFolder string, decryption *OutputDecryption) (int, error) {
+	info, err := os.Stat(input)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return 1, decryptFile(input, filepath.Join(outputFolder, strings.TrimSuffix(filepath.Base(input), EncryptedFileExtension)), decryption)
+	}
+
+	count := 0
+	err = filepath.Walk(input, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(filePath) != EncryptedFileExtension {
+			return err
+		}
+		relativePath, err := filepath.Rel(input, filePath)
+		if err != nil {
+			return err
+		}
+		err = decryptFile(filePath, filepath.Join(outputFolder, strings.TrimSuffix(relativePath, EncryptedFileExtension)), decryption)
+		if err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func decryptFile(inputPath string, outputPath string, decryption *OutputDecryption) error {
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer inputFile.Close()
+
+	err = os.MkdirAll(filepath.Dir(outputPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	err = decryption.Decrypt(inputFile, outputFile)
+	closeErr := outputFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("%s: %s", inputPath, err)
+	}
+	return nil
+}
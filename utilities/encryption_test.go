@@ -0,0 +1,141 @@
+package utilities_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	path "path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gogen/utilities"
+)
+
+var _ = Describe("Encryption", func() {
+	var plaintext []byte
+
+	encrypt := func(encryption *utilities.OutputEncryption, contents []byte) []byte {
+		var encrypted bytes.Buffer
+		writer, err := encryption.NewWriter(nopCloser{&encrypted})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = writer.Write(contents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		return encrypted.Bytes()
+	}
+
+	decrypt := func(decryption *utilities.OutputDecryption, encrypted []byte) ([]byte, error) {
+		var decrypted bytes.Buffer
+		err := decryption.Decrypt(bytes.NewReader(encrypted), &decrypted)
+		return decrypted.Bytes(), err
+	}
+
+	BeforeEach(func() {
+		plaintext = bytes.Repeat([]byte("SUBJECT_ID,PRI_NAME\n100,SKYWALKER LUKE\n"), 5000)
+	})
+
+	It("decrypts what was encrypted with the same passphrase", func() {
+		encryption, err := utilities.NewPassphraseEncryption([]byte("correct horse"))
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, contents := range [][]byte{plaintext, plaintext[:64*1024], {}} {
+			encrypted := encrypt(encryption, contents)
+			Expect(bytes.Contains(encrypted, []byte("SKYWALKER"))).To(BeFalse())
+
+			decrypted, err := decrypt(utilities.NewPassphraseDecryption([]byte("correct horse")), encrypted)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decrypted).To(Equal(contents))
+		}
+	})
+
+	It("wraps a new file key for each file under the key derived once from the passphrase", func() {
+		encryption, err := utilities.NewPassphraseEncryption([]byte("correct horse"))
+		Expect(err).ToNot(HaveOccurred())
+		first := encrypt(encryption, plaintext)
+		second := encrypt(encryption, plaintext)
+
+		headerStart := len("GOGENENC1\n") + 1
+		Expect(first[headerStart : headerStart+16]).To(Equal(second[headerStart : headerStart+16]))
+		Expect(first[headerStart+16:]).ToNot(Equal(second[headerStart+16:]))
+
+		decryption := utilities.NewPassphraseDecryption([]byte("correct horse"))
+		for _, encrypted := range [][]byte{first, second} {
+			decrypted, err := decrypt(decryption, encrypted)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+		}
+	})
+
+	It("refuses a wrong passphrase or a modified or truncated file", func() {
+		encryption, err := utilities.NewPassphraseEncryption([]byte("correct horse"))
+		Expect(err).ToNot(HaveOccurred())
+		encrypted := encrypt(encryption, plaintext)
+
+		_, err = decrypt(utilities.NewPassphraseDecryption([]byte("battery staple")), encrypted)
+		Expect(err).To(Equal(utilities.ErrDecryption))
+
+		modified := append([]byte{}, encrypted...)
+		modified[len(modified)-100] ^= 1
+		_, err = decrypt(utilities.NewPassphraseDecryption([]byte("correct horse")), modified)
+		Expect(err).To(Equal(utilities.ErrDecryption))
+
+		_, err = decrypt(utilities.NewPassphraseDecryption([]byte("correct horse")), encrypted[:len(encrypted)-len(plaintext)%(64*1024)-16])
+		Expect(err).To(Equal(utilities.ErrDecryption))
+	})
+
+	It("decrypts what was encrypted with a public key using the private key", func() {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		encryption, err := utilities.NewPublicKeyEncryption(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}))
+		Expect(err).ToNot(HaveOccurred())
+		encrypted := encrypt(encryption, plaintext)
+
+		decryption, err := utilities.NewPrivateKeyDecryption(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+		Expect(err).ToNot(HaveOccurred())
+		decrypted, err := decrypt(decryption, encrypted)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decrypted).To(Equal(plaintext))
+
+		_, err = decrypt(utilities.NewPassphraseDecryption([]byte("correct horse")), encrypted)
+		Expect(err).To(MatchError(ContainSubstring("encrypted with a public key")))
+	})
+
+	It("writes output files encrypted and decrypts a folder of them", func() {
+		outputDir, err := ioutil.TempDir("/tmp", "gogen")
+		Expect(err).ToNot(HaveOccurred())
+		encryption, err := utilities.NewPassphraseEncryption([]byte("correct horse"))
+		Expect(err).ToNot(HaveOccurred())
+
+		files := utilities.NewOutputFiles(encryption)
+		Expect(os.MkdirAll(path.Join(outputDir, "encrypted", "Orders"), os.ModePerm)).To(Succeed())
+		Expect(files.WriteFile(path.Join(outputDir, "encrypted", "All_Results.csv"), plaintext)).To(Succeed())
+		Expect(files.WriteFile(path.Join(outputDir, "encrypted", "Orders", "Order.txt"), []byte("ORDER"))).To(Succeed())
+
+		Expect(path.Join(outputDir, "encrypted", "All_Results.csv")).ToNot(BeAnExistingFile())
+		Expect(files.Created()).To(Equal([]string{
+			path.Join(outputDir, "encrypted", "All_Results.csv.enc"),
+			path.Join(outputDir, "encrypted", "Orders", "Order.txt.enc"),
+		}))
+
+		count, err := utilities.DecryptFiles(path.Join(outputDir, "encrypted"), path.Join(outputDir, "decrypted"), utilities.NewPassphraseDecryption([]byte("correct horse")))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(2))
+		Expect(ioutil.ReadFile(path.Join(outputDir, "decrypted", "All_Results.csv"))).To(Equal(plaintext))
+		Expect(ioutil.ReadFile(path.Join(outputDir, "decrypted", "Orders", "Order.txt"))).To(Equal([]byte("ORDER")))
+	})
+})
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -3,7 +3,6 @@ package utilities
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
@@ -26,7 +25,10 @@ func (g *GogenError) Error() string {
 	return g.ErrorMessage
 }
 
-var errorFileName string
+var errorFile struct {
+	name  string
+	files OutputFiles
+}
 
 func PrintProgressBar(index, totalRows int, totalTime time.Duration, tail string) {
 	progress := float64(index) / float64(totalRows)
@@ -52,8 +54,9 @@ func AddMaps(map1 map[string]int, map2 map[string]int) map[string]int {
 	return map1
 }
 
-func SetErrorFileName(filename string) {
-	errorFileName = filename
+func SetErrorFileName(filename string, files OutputFiles) {
+	errorFile.name = filename
+	errorFile.files = files
 }
 
 func ExitWithError(originalError error) {
@@ -70,7 +73,7 @@ func ExitWithErrors(originalErrors map[string]GogenError) {
 		os.Exit(ERROR_EXIT)
 	}
 
-	err = ioutil.WriteFile(errorFileName, s, 0644)
+	err = errorFile.files.WriteFile(errorFile.name, s)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(ERROR_EXIT)
@@ -89,6 +92,10 @@ func ExitWithErrors(originalErrors map[string]GogenError) {
 	os.Exit(ERROR_EXIT)
 }
 
+func PrintWarning(message string) {
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+}
+
 func GenerateFileName(outputFolder string, template string, suffix string) string {
 	if suffix != "" {
 		suffix = "_" + suffix
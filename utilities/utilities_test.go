@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gstruct"
 	"gogen/utilities"
+	"time"
 )
 
 var _ = Describe("Utilities", func() {
@@ -34,4 +35,18 @@ var _ = Describe("Utilities", func() {
 			}))
 		})
 	})
+
+	Describe("Clock", func() {
+		It("always returns the same time from a fixed clock", func() {
+			fixedTime := time.Date(2019, time.November, 11, 15, 4, 5, 0, time.UTC)
+			clock := utilities.FixedClock{Time: fixedTime}
+
+			Expect(clock.Now()).To(Equal(fixedTime))
+			Expect(clock.Now()).To(Equal(clock.Now()))
+		})
+
+		It("truncates a time to the start of its day", func() {
+			Expect(utilities.StartOfDay(time.Date(2019, time.November, 11, 15, 4, 5, 0, time.UTC))).To(Equal(time.Date(2019, time.November, 11, 0, 0, 0, 0, time.UTC)))
+		})
+	})
 })